@@ -26,13 +26,70 @@ import (
 
 const OpenFgaAuthModelIdEnv = "OPENFGA_AUTH_MODEL_ID"
 const OpenFgaStoreIdEnv = "OPENFGA_STORE_ID"
+const OpenFgaAuthModelVersionEnv = "OPENFGA_AUTH_MODEL_VERSION"
+const OpenFgaApiUrlEnv = "OPENFGA_API_URL"
 
 const OpenFgaStoreLabel = "openfga-store"
 const OpenFgaAuthModelVersionLabel = "openfga-auth-model-version"
+const OpenFgaAuthModelChannelLabel = "openfga-auth-model-channel"
+
+const OpenFgaAuthModelVersionRangeAnnotation = "openfga-auth-model-version-range"
 
 const OpenFgaAuthIdUpdatedAtAnnotation = "openfga-auth-id-updated-at"
 const OpenFgaStoreIdUpdatedAtAnnotation = "openfga-store-id-updated-at"
 
+// OpenFgaDeliveryAnnotation selects how a workload gets the store ID and authorization model ID, see DeliveryMode.
+const OpenFgaDeliveryAnnotation = "openfga-delivery"
+
+// DeliveryMode is how the store ID and authorization model ID are delivered to a workload.
+type DeliveryMode string
+
+const (
+	// DeliveryModeEnv sets the IDs as environment variables of the pod template, which restarts the pods of the
+	// workload whenever an ID changes. This is the default.
+	DeliveryModeEnv DeliveryMode = "env"
+
+	// DeliveryModeConfigMap writes the IDs and the version to a ConfigMap owned by the workload, which is mounted
+	// into its containers. IDs are changed without changing the pod template, such that applications watching
+	// the mounted files can switch authorization models without restarting.
+	DeliveryModeConfigMap DeliveryMode = "configmap"
+
+	// DeliveryModeConfigMapKeyRef writes the values to the ConfigMap of DeliveryModeConfigMap, and injects environment
+	// variables referencing its keys with `valueFrom.configMapKeyRef`. The checksum of the ConfigMap is set on the pod
+	// template, such that the pods are restarted to read the new values.
+	DeliveryModeConfigMapKeyRef DeliveryMode = "configMapKeyRef"
+)
+
+// The env annotations set the names of the environment variables a workload gets. The store ID and authorization
+// model ID are injected as OpenFgaStoreIdEnv and OpenFgaAuthModelIdEnv by default, the version and the API URL only
+// when their annotation is set.
+const OpenFgaStoreIdEnvAnnotation = "openfga-store-id-env"
+const OpenFgaAuthModelIdEnvAnnotation = "openfga-auth-model-id-env"
+const OpenFgaAuthModelVersionEnvAnnotation = "openfga-auth-model-version-env"
+const OpenFgaApiUrlEnvAnnotation = "openfga-api-url-env"
+
+// OpenFgaContainersAnnotation restricts the injection to the containers and init containers with the comma
+// separated names. Every container, but no init container, gets the environment variables by default.
+const OpenFgaContainersAnnotation = "openfga-containers"
+
+// OpenFgaInjectedEnvAnnotation holds the names of the environment variables injected into the pod template of a
+// workload by container, in json, such that variables of containers which are no longer targeted, or with a previous
+// name, are removed.
+const OpenFgaInjectedEnvAnnotation = "openfga-injected-env"
+
+// OpenFgaConfigMapChecksumAnnotation holds the checksum of the delivery ConfigMap on the pod template of workloads
+// with DeliveryModeConfigMapKeyRef.
+const OpenFgaConfigMapChecksumAnnotation = "openfga-configmap-checksum"
+
+// OpenFgaOutdatedTemplateChecksumAnnotation holds the checksum of the pod template a Job should have, set once the
+// JobTemplateImmutable event has been emitted for it, such that the event isn't repeated on every reconciliation.
+const OpenFgaOutdatedTemplateChecksumAnnotation = "openfga-outdated-template-checksum"
+
+// OpenFgaRollbackAuthModelIdAnnotation and OpenFgaRollbackAuthModelVersionAnnotation hold the authorization model
+// ID and version a Deployment is reverted to, while the operator waits for it to become available.
+const OpenFgaRollbackAuthModelIdAnnotation = "openfga-rollback-auth-model-id"
+const OpenFgaRollbackAuthModelVersionAnnotation = "openfga-rollback-auth-model-version"
+
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // AuthorizationModelSpec defines the desired state of AuthorizationModel
@@ -40,17 +97,195 @@ type AuthorizationModelSpec struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	Instances []AuthorizationModelInstance `json:"instances,omitempty"`
+
+	// Channels of the authorization model request, selected by workloads with the `openfga-auth-model-channel` label.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Channels []ModelChannel `json:"channels,omitempty"`
+
+	// RolloutStrategy of the authorization model request, which moves workloads in batches.
+	// +optional
+	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// AutomaticRollback of the authorization model request, which reverts Deployments failing with a new version.
+	// +optional
+	AutomaticRollback *AutomaticRollback `json:"automaticRollback,omitempty"`
+}
+
+// AutomaticRollback reverts a Deployment to its previous authorization model ID when it doesn't become
+// available after getting a new one. The version is marked as failed in the status of the authorization
+// model, such that workloads aren't moved to it again.
+type AutomaticRollback struct {
+	// ProgressDeadline is the time a Deployment has to become available after getting a new authorization
+	// model ID. Defaults to 10 minutes.
+	// +optional
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
+}
+
+// RolloutStrategy moves workloads to new authorization model IDs in batches, instead of updating every
+// workload at once. A batch is started once the previous one is done, and the rollout halts when the
+// Deployments of a batch don't become available within the progress deadline.
+type RolloutStrategy struct {
+	// BatchSize is the number of workloads updated per batch. Defaults to 1 when batchPercentage isn't set.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	BatchSize int32 `json:"batchSize,omitempty"`
+
+	// BatchPercentage is the percentage of the workloads updated per batch, rounded up.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	BatchPercentage int32 `json:"batchPercentage,omitempty"`
+
+	// PauseBetweenBatches is the time to wait after a batch is done before the next batch is started.
+	// +optional
+	PauseBetweenBatches *metav1.Duration `json:"pauseBetweenBatches,omitempty"`
+
+	// WaitForAvailable waits until the updated Deployments of a batch are available before the next
+	// batch is started. Other workload kinds are done once they are updated.
+	// +optional
+	WaitForAvailable bool `json:"waitForAvailable,omitempty"`
+
+	// ProgressDeadline is the time the Deployments of a batch have to become available, after which the
+	// rollout halts. Defaults to 10 minutes.
+	// +optional
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
 }
 
+// ModelChannel names a version constraint, such that workloads follow the versions of the channel instead of
+// pinning a version themselves.
+type ModelChannel struct {
+	// Name of the channel, e.g. "stable" or "canary".
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Version constraint of the channel, e.g. "1.x", "~1.2" or ">=1.1.0 <2.0.0". Workloads of the channel get
+	// the highest version matching the constraint.
+	// +kubebuilder:validation:MinLength=1
+	Version string `json:"version"`
+}
+
+// ConditionWorkloadsSynced is true when every workload bound to the authorization model has the
+// store ID and authorization model ID it should have.
+const ConditionWorkloadsSynced = "WorkloadsSynced"
+
 // AuthorizationModelStatus defines the observed state of AuthorizationModel
 type AuthorizationModelStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
+	// Workloads bound to the authorization model using the `openfga-store` label, sorted by kind and name.
+	Workloads []BoundWorkload `json:"workloads,omitempty"`
+
+	// LastUpdateTime is the last time a workload was updated with new IDs.
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// Conditions describe the latest observations of the authorization model.
+	// Known condition types are "WorkloadsSynced".
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// Rollout is the progress of the rollout of the authorization model to its workloads, when the
+	// authorization model request has a rollout strategy.
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+
+	// FailedVersions are the instances which were rolled back, because a Deployment didn't become available
+	// with them. Workloads aren't moved to a failed instance again, until it is removed from the authorization
+	// model.
+	// +optional
+	FailedVersions []FailedVersion `json:"failedVersions,omitempty"`
+}
+
+// FailedVersion is an instance which was rolled back by the automatic rollback.
+type FailedVersion struct {
+	// Version of the failed instance.
+	Version string `json:"version"`
+
+	// AuthorizationModelId of the failed instance.
+	AuthorizationModelId string `json:"authorizationModelId"`
+
+	// Workload which didn't become available with the instance, as kind/name.
+	Workload string `json:"workload"`
+
+	// Reason the workload was rolled back.
+	Reason string `json:"reason"`
+
+	// FailedAt is the time the workload was rolled back.
+	FailedAt metav1.Time `json:"failedAt"`
+}
+
+// RolloutPhase is the phase of a rollout.
+type RolloutPhase string
+
+const (
+	// RolloutPhaseProgressing means workloads are being updated in batches.
+	RolloutPhaseProgressing RolloutPhase = "Progressing"
+
+	// RolloutPhaseHalted means the Deployments of a batch didn't become available in time, and no more
+	// workloads are updated until the authorization model changes.
+	RolloutPhaseHalted RolloutPhase = "Halted"
+
+	// RolloutPhaseCompleted means every workload has been updated.
+	RolloutPhaseCompleted RolloutPhase = "Completed"
+)
+
+// RolloutStatus is the progress of a rollout.
+type RolloutStatus struct {
+	// ObservedGeneration is the generation of the authorization model rolled out. A new rollout is started
+	// when the authorization model changes.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Phase of the rollout: "Progressing", "Halted" or "Completed".
+	Phase RolloutPhase `json:"phase,omitempty"`
+
+	// Batches is the number of batches started.
+	Batches int32 `json:"batches"`
+
+	// UpdatedWorkloads is the number of workloads updated by the rollout.
+	UpdatedWorkloads int32 `json:"updatedWorkloads"`
+
+	// PendingWorkloads is the number of workloads still to be updated.
+	PendingWorkloads int32 `json:"pendingWorkloads"`
+
+	// CurrentBatch lists the workloads of the last batch as kind/name.
+	// +optional
+	CurrentBatch []string `json:"currentBatch,omitempty"`
+
+	// LastBatchTime is the time the last batch was started.
+	// +optional
+	LastBatchTime *metav1.Time `json:"lastBatchTime,omitempty"`
+
+	// Message describes why the rollout halted.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// BoundWorkload is a workload which gets its store ID and authorization model ID from the authorization model.
+type BoundWorkload struct {
+	// Kind of the workload, e.g. "Deployment".
+	Kind string `json:"kind"`
+
+	// Name of the workload.
+	Name string `json:"name"`
+
+	// Version of the authorization model the workload currently has.
+	Version string `json:"version,omitempty"`
+
+	// AuthorizationModelId the workload currently has.
+	AuthorizationModelId string `json:"authorizationModelId,omitempty"`
+
+	// Error from the last failed attempt to update the workload.
+	Error string `json:"error,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Workloads Synced",type=string,JSONPath=`.status.conditions[?(@.type=="WorkloadsSynced")].status`
+//+kubebuilder:printcolumn:name="Rollout",type=string,JSONPath=`.status.rollout.phase`
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // AuthorizationModel is the Schema for the authorizationmodels API
 type AuthorizationModel struct {
@@ -74,10 +309,28 @@ func init() {
 	SchemeBuilder.Register(&AuthorizationModel{}, &AuthorizationModelList{})
 }
 
+// ModelOrigin describes where the ID of an authorization model instance comes from.
+// +kubebuilder:validation:Enum=Created;Adopted;Existing
+type ModelOrigin string
+
+const (
+	// ModelOriginCreated means the authorization model was written to OpenFGA by the operator.
+	ModelOriginCreated ModelOrigin = "Created"
+
+	// ModelOriginAdopted means an identical authorization model already existed in the store, and its ID
+	// has been reused instead of writing a new one.
+	ModelOriginAdopted ModelOrigin = "Adopted"
+
+	// ModelOriginExisting means the ID was set with existingAuthorizationModelId.
+	ModelOriginExisting ModelOrigin = "Existing"
+)
+
 type AuthorizationModelDefinition struct {
-	Id                 string
-	AuthorizationModel string
-	Version            ModelVersion
+	Id                        string
+	AuthorizationModel        string
+	ModularAuthorizationModel *ModularAuthorizationModel
+	Version                   ModelVersion
+	Origin                    ModelOrigin
 }
 
 func NewAuthorizationModelDefinition(id string, authorizationModel string, version ModelVersion) AuthorizationModelDefinition {
@@ -90,18 +343,35 @@ func NewAuthorizationModelDefinition(id string, authorizationModel string, versi
 
 func (d AuthorizationModelDefinition) IntoInstance(now time.Time) AuthorizationModelInstance {
 	return AuthorizationModelInstance{
-		Id:                 d.Id,
-		AuthorizationModel: d.AuthorizationModel,
-		Version:            d.Version,
-		CreatedAt:          &metav1.Time{Time: now},
+		Id:                        d.Id,
+		AuthorizationModel:        d.AuthorizationModel,
+		ModularAuthorizationModel: d.ModularAuthorizationModel,
+		Version:                   d.Version,
+		Origin:                    d.Origin,
+		CreatedAt:                 &metav1.Time{Time: now},
 	}
 }
 
 type AuthorizationModelInstance struct {
-	Id                 string       `json:"id,omitempty"`
-	AuthorizationModel string       `json:"authorizationModel,omitempty"`
-	Version            ModelVersion `json:"version,omitempty"`
-	CreatedAt          *metav1.Time `json:"createdAt,omitempty"`
+	Id string `json:"id,omitempty"`
+
+	// AuthorizationModel in DSL. Authorization models requested in json are rendered to DSL.
+	AuthorizationModel string `json:"authorizationModel,omitempty"`
+
+	// ModularAuthorizationModel is the modular authorization model the instance was created from, with
+	// the manifest and the contents of the module files inlined.
+	// +optional
+	ModularAuthorizationModel *ModularAuthorizationModel `json:"modularAuthorizationModel,omitempty"`
+
+	Version ModelVersion `json:"version,omitempty"`
+
+	// Origin of the ID: "Created" when the operator wrote the authorization model to OpenFGA, "Adopted"
+	// when an identical authorization model already existed in the store, or "Existing" when the ID was
+	// set with existingAuthorizationModelId.
+	// +optional
+	Origin ModelOrigin `json:"origin,omitempty"`
+
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
 }
 
 type ByVersionAndCreatedAtDesc []AuthorizationModelInstance
@@ -135,6 +405,16 @@ func FilterBySchemaVersion(instances []AuthorizationModelInstance, version Model
 	return filtered
 }
 
+func FilterByVersionConstraint(instances []AuthorizationModelInstance, constraint VersionConstraint) []AuthorizationModelInstance {
+	var filtered []AuthorizationModelInstance
+	for _, instance := range instances {
+		if constraint.Matches(instance.Version) {
+			filtered = append(filtered, instance)
+		}
+	}
+	return filtered
+}
+
 func NewAuthorizationModel(name, namespace string, definitions []AuthorizationModelDefinition, now time.Time) AuthorizationModel {
 	instances := make([]AuthorizationModelInstance, len(definitions))
 	for i, definition := range definitions {
@@ -154,19 +434,48 @@ func NewAuthorizationModel(name, namespace string, definitions []AuthorizationMo
 	}
 }
 
+// WithoutFailedVersions returns a copy of the authorization model without the instances which failed, such
+// that workloads don't select them.
+func (a *AuthorizationModel) WithoutFailedVersions() *AuthorizationModel {
+	result := a.DeepCopy()
+	if len(a.Status.FailedVersions) == 0 {
+		return result
+	}
+	failed := make(map[string]struct{}, len(a.Status.FailedVersions))
+	for _, failedVersion := range a.Status.FailedVersions {
+		failed[failedVersion.AuthorizationModelId] = struct{}{}
+	}
+	result.Spec.Instances = nil
+	for _, instance := range a.Spec.Instances {
+		if _, ok := failed[instance.Id]; !ok {
+			result.Spec.Instances = append(result.Spec.Instances, instance)
+		}
+	}
+	return result
+}
+
 func (a *AuthorizationModel) GetVersionFromDeployment(deployment v1.Deployment) (AuthorizationModelInstance, error) {
+	return a.GetVersionFromObject(&deployment)
+}
+
+// GetVersionFromObject returns the highest instance selected by the given workload, or the latest instance
+// when the workload doesn't select a version. A workload selects versions with one of:
+//   - the `openfga-auth-model-version` label, holding a version or a version constraint valid as a label
+//     value, e.g. "1.2.3" or "1.x";
+//   - the `openfga-auth-model-version-range` annotation, holding any version constraint, e.g. "~1.2";
+//   - the `openfga-auth-model-channel` label, holding the name of a channel of the authorization model.
+func (a *AuthorizationModel) GetVersionFromObject(object metav1.Object) (AuthorizationModelInstance, error) {
 	if len(a.Spec.Instances) == 0 {
 		return AuthorizationModelInstance{}, fmt.Errorf("no authorization model exists")
 	}
-	version, ok := deployment.Labels[OpenFgaAuthModelVersionLabel]
+	constraint, ok, err := a.versionConstraint(object)
+	if err != nil {
+		return AuthorizationModelInstance{}, err
+	}
 	if ok {
-		modelVersion, err := ModelVersionFromString(version)
-		if err != nil {
-			return AuthorizationModelInstance{}, err
-		}
-		filtered := FilterBySchemaVersion(a.Spec.Instances, modelVersion)
+		filtered := FilterByVersionConstraint(a.Spec.Instances, constraint)
 		if len(filtered) == 0 {
-			return AuthorizationModelInstance{}, fmt.Errorf("neither current or any latest models match version %s", version)
+			return AuthorizationModelInstance{}, fmt.Errorf("neither current or any latest models match version %s", constraint)
 		}
 		SortAuthorizationModelInstancesByVersionAndCreatedAtDesc(filtered)
 		return filtered[0], nil
@@ -175,3 +484,41 @@ func (a *AuthorizationModel) GetVersionFromDeployment(deployment v1.Deployment)
 	SortAuthorizationModelInstancesByVersionAndCreatedAtDesc(a.Spec.Instances)
 	return a.Spec.Instances[0], nil
 }
+
+// versionConstraint returns the version constraint selected by the workload, and false when it doesn't
+// select one.
+func (a *AuthorizationModel) versionConstraint(object metav1.Object) (VersionConstraint, bool, error) {
+	version, hasVersion := object.GetLabels()[OpenFgaAuthModelVersionLabel]
+	versionRange, hasVersionRange := object.GetAnnotations()[OpenFgaAuthModelVersionRangeAnnotation]
+	channel, hasChannel := object.GetLabels()[OpenFgaAuthModelChannelLabel]
+
+	selectors := 0
+	for _, selected := range []bool{hasVersion, hasVersionRange, hasChannel} {
+		if selected {
+			selectors++
+		}
+	}
+	if selectors > 1 {
+		return VersionConstraint{}, false, fmt.Errorf("only one of the label %s, the annotation %s and the label %s may be set",
+			OpenFgaAuthModelVersionLabel, OpenFgaAuthModelVersionRangeAnnotation, OpenFgaAuthModelChannelLabel)
+	}
+
+	switch {
+	case hasVersion:
+		constraint, err := ParseVersionConstraint(version)
+		return constraint, true, err
+	case hasVersionRange:
+		constraint, err := ParseVersionConstraint(versionRange)
+		return constraint, true, err
+	case hasChannel:
+		for _, modelChannel := range a.Spec.Channels {
+			if modelChannel.Name == channel {
+				constraint, err := ParseVersionConstraint(modelChannel.Version)
+				return constraint, true, err
+			}
+		}
+		return VersionConstraint{}, false, fmt.Errorf("channel %s doesn't exist", channel)
+	default:
+		return VersionConstraint{}, false, nil
+	}
+}
@@ -323,6 +323,185 @@ func TestWhenNoVersionIsPresentThenAddReturnLatest(t *testing.T) {
 	}
 }
 
+func TestGivenStatefulSetWithVersionLabelThenReturnMatchingInstance(t *testing.T) {
+	// Arrange
+	currentTime := metaTime(time.Now())
+	version := ModelVersion{Major: 1, Minor: 1, Patch: 1}
+	instance := AuthorizationModelInstance{
+		Id:                 uuid.NewString(),
+		AuthorizationModel: "AuthorizationModel",
+		Version:            version,
+		CreatedAt:          currentTime,
+	}
+	authModel := AuthorizationModel{
+		Spec: AuthorizationModelSpec{
+			Instances: []AuthorizationModelInstance{
+				instance,
+				{
+					Id:                 uuid.NewString(),
+					AuthorizationModel: "AuthorizationModel",
+					Version:            ModelVersion{Major: 2, Minor: 0, Patch: 0},
+					CreatedAt:          currentTime,
+				},
+			},
+		},
+	}
+	statefulSet := appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "name",
+			Labels: map[string]string{
+				OpenFgaAuthModelVersionLabel: version.String(),
+			},
+		},
+	}
+
+	// Act
+	actualInstance, err := authModel.GetVersionFromObject(&statefulSet)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error getting version: %v", err)
+	}
+	if instance.Id != actualInstance.Id {
+		t.Errorf("Unexpected version. Expected %v, got %v", instance.Id, actualInstance.Id)
+	}
+}
+
+func TestGetVersionFromObjectWithVersionSelectors(t *testing.T) {
+	currentTime := time.Now()
+	authModel := AuthorizationModel{
+		Spec: AuthorizationModelSpec{
+			Instances: []AuthorizationModelInstance{
+				{Id: "1.0.0", Version: ModelVersion{Major: 1}, CreatedAt: metaTime(currentTime)},
+				{Id: "1.1.0", Version: ModelVersion{Major: 1, Minor: 1}, CreatedAt: metaTime(currentTime)},
+				{Id: "1.1.2", Version: ModelVersion{Major: 1, Minor: 1, Patch: 2}, CreatedAt: metaTime(currentTime)},
+				{Id: "2.0.0", Version: ModelVersion{Major: 2}, CreatedAt: metaTime(currentTime)},
+			},
+			Channels: []ModelChannel{
+				{Name: "stable", Version: "1.x"},
+				{Name: "canary", Version: ">=2.0.0"},
+				{Name: "next", Version: "3.x"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		expectedId  string
+		expectError bool
+	}{
+		{
+			name:       "wildcard version label",
+			labels:     map[string]string{OpenFgaAuthModelVersionLabel: "1.x"},
+			expectedId: "1.1.2",
+		},
+		{
+			name:       "partial version label",
+			labels:     map[string]string{OpenFgaAuthModelVersionLabel: "1.0"},
+			expectedId: "1.0.0",
+		},
+		{
+			name:        "tilde range annotation",
+			annotations: map[string]string{OpenFgaAuthModelVersionRangeAnnotation: "~1.1"},
+			expectedId:  "1.1.2",
+		},
+		{
+			name:        "comparison range annotation",
+			annotations: map[string]string{OpenFgaAuthModelVersionRangeAnnotation: ">=1.0.0 <1.1.2"},
+			expectedId:  "1.1.0",
+		},
+		{
+			name:       "channel label",
+			labels:     map[string]string{OpenFgaAuthModelChannelLabel: "stable"},
+			expectedId: "1.1.2",
+		},
+		{
+			name:       "canary channel label",
+			labels:     map[string]string{OpenFgaAuthModelChannelLabel: "canary"},
+			expectedId: "2.0.0",
+		},
+		{
+			name:        "channel without matching version",
+			labels:      map[string]string{OpenFgaAuthModelChannelLabel: "next"},
+			expectError: true,
+		},
+		{
+			name:        "unknown channel",
+			labels:      map[string]string{OpenFgaAuthModelChannelLabel: "beta"},
+			expectError: true,
+		},
+		{
+			name:        "invalid range",
+			annotations: map[string]string{OpenFgaAuthModelVersionRangeAnnotation: "~a"},
+			expectError: true,
+		},
+		{
+			name:        "version label and channel label",
+			labels:      map[string]string{OpenFgaAuthModelVersionLabel: "1.x", OpenFgaAuthModelChannelLabel: "stable"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			deployment := appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "name", Labels: tt.labels, Annotations: tt.annotations},
+			}
+
+			// Act
+			actualInstance, err := authModel.GetVersionFromObject(&deployment)
+
+			// Assert
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got instance %v", actualInstance.Id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error getting version: %v", err)
+			}
+			if tt.expectedId != actualInstance.Id {
+				t.Errorf("Unexpected version. Expected %v, got %v", tt.expectedId, actualInstance.Id)
+			}
+		})
+	}
+}
+
+func TestGetVersionFromObjectWithoutFailedVersions(t *testing.T) {
+	// Arrange
+	currentTime := time.Now()
+	authModel := AuthorizationModel{
+		Spec: AuthorizationModelSpec{
+			Instances: []AuthorizationModelInstance{
+				{Id: "1.0.0", Version: ModelVersion{Major: 1}, CreatedAt: metaTime(currentTime)},
+				{Id: "1.1.0", Version: ModelVersion{Major: 1, Minor: 1}, CreatedAt: metaTime(currentTime)},
+			},
+		},
+		Status: AuthorizationModelStatus{
+			FailedVersions: []FailedVersion{{Version: "1.1.0", AuthorizationModelId: "1.1.0"}},
+		},
+	}
+	deployment := createDeployment()
+
+	// Act
+	actualInstance, err := authModel.WithoutFailedVersions().GetVersionFromDeployment(deployment)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error getting version: %v", err)
+	}
+	if actualInstance.Id != "1.0.0" {
+		t.Errorf("Unexpected version. Expected %v, got %v", "1.0.0", actualInstance.Id)
+	}
+	if len(authModel.Spec.Instances) != 2 {
+		t.Errorf("expected the instances of the authorization model to be unchanged")
+	}
+}
+
 func metaTime(t time.Time) *metav1.Time {
 	return &metav1.Time{Time: t}
 }
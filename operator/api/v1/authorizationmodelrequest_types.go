@@ -18,6 +18,7 @@ package v1
 
 import (
 	"fmt"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"strconv"
 	"strings"
@@ -45,6 +46,19 @@ const (
 	SynchronizationFailed AuthorizationModelRequestStatusState = "SynchronizationFailed"
 )
 
+// Condition types set on the status of an AuthorizationModelRequest.
+const (
+	// ConditionStoreReady is true when the store exists in OpenFGA and in Kubernetes.
+	ConditionStoreReady = "StoreReady"
+
+	// ConditionModelsSynced is true when every instance of the request exists as an authorization model
+	// in OpenFGA and is part of the AuthorizationModel resource.
+	ConditionModelsSynced = "ModelsSynced"
+
+	// ConditionReady is true when the request is fully synchronized.
+	ConditionReady = "Ready"
+)
+
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // AuthorizationModelRequestSpec defines the desired state of AuthorizationModelRequest
@@ -55,8 +69,68 @@ type AuthorizationModelRequestSpec struct {
 	// Only applicable when migrating from existing infrastructure where the operator was not previously used.
 	ExistingStoreId string                              `json:"existingStoreId,omitempty"`
 	Instances       []AuthorizationModelRequestInstance `json:"instances,omitempty"`
+
+	// DeletionPolicy defines what happens with the store in OpenFGA when the request is deleted.
+	// It is applied to the Store resource of the request.
+	// Valid values are:
+	// - "Retain" (default): the store is kept in OpenFGA;
+	// - "Delete": the store is deleted from OpenFGA when the operator created it.
+	// +kubebuilder:default=Retain
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// ConnectionRef references the FGAConnection or ClusterFGAConnection of the OpenFGA server the request
+	// is synchronized to. The OpenFGA server configured for the operator is used when it's not set.
+	// The connection of a request can't be changed once its store exists.
+	// +optional
+	ConnectionRef *ConnectionReference `json:"connectionRef,omitempty"`
+
+	// BreakingChangePolicy defines what happens when a new minor or patch version contains breaking changes
+	// to the previous version of its major version, like a removed type or relation.
+	// Valid values are:
+	// - "Warn" (default): the version is added, and a warning event lists the breaking changes;
+	// - "Reject": the version isn't added, and fails with the breaking changes;
+	// - "Ignore": versions aren't checked for breaking changes.
+	// +kubebuilder:default=Warn
+	// +optional
+	BreakingChangePolicy BreakingChangePolicy `json:"breakingChangePolicy,omitempty"`
+
+	// Channels name version constraints which workloads select with the `openfga-auth-model-channel` label,
+	// e.g. a "stable" channel with the version "1.x" and a "canary" channel with the version "2.x". Changing
+	// the version of a channel moves its workloads without changing the workloads.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Channels []ModelChannel `json:"channels,omitempty"`
+
+	// RolloutStrategy moves workloads to new versions in batches, e.g. 25% of the workloads at a time,
+	// waiting for the updated Deployments to become available. Every workload is updated at once when
+	// it isn't set.
+	// +optional
+	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// AutomaticRollback reverts Deployments to their previous authorization model ID when they don't become
+	// available after getting a new version, and marks the version as failed in the status of the
+	// AuthorizationModel. Deployments aren't reverted when it isn't set.
+	// +optional
+	AutomaticRollback *AutomaticRollback `json:"automaticRollback,omitempty"`
 }
 
+// BreakingChangePolicy defines what happens with new minor and patch versions containing breaking changes.
+// +kubebuilder:validation:Enum=Warn;Reject;Ignore
+type BreakingChangePolicy string
+
+const (
+	// BreakingChangePolicyWarn adds the version with a warning.
+	BreakingChangePolicyWarn BreakingChangePolicy = "Warn"
+
+	// BreakingChangePolicyReject doesn't add the version.
+	BreakingChangePolicyReject BreakingChangePolicy = "Reject"
+
+	// BreakingChangePolicyIgnore doesn't check versions for breaking changes.
+	BreakingChangePolicyIgnore BreakingChangePolicy = "Ignore"
+)
+
 // AuthorizationModelRequestStatus defines the observed state of AuthorizationModelRequest.
 // It captures the current status of the request, tracking its progress through
 // different stages of its lifecycle.
@@ -70,11 +144,52 @@ type AuthorizationModelRequestStatus struct {
 	// Defaults to "Pending" when the request is created.
 	// +kubebuilder:default="Pending"
 	State AuthorizationModelRequestStatusState `json:"state,omitempty"`
+
+	// ObservedGeneration is the generation of the request which was last processed by the controller.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// StoreId is the ID of the store in OpenFGA the request is synchronized to.
+	StoreId string `json:"storeId,omitempty"`
+
+	// Versions holds the status of each requested version, sorted by version with the latest first.
+	Versions []AuthorizationModelVersionStatus `json:"versions,omitempty"`
+
+	// Conditions describe the latest observations of the request.
+	// Known condition types are "StoreReady", "ModelsSynced" and "Ready".
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// AuthorizationModelVersionStatus is the status of a single version of an AuthorizationModelRequest.
+type AuthorizationModelVersionStatus struct {
+	// Version of the authorization model, e.g. "1.2.0".
+	Version string `json:"version"`
+
+	// Id given by OpenFGA when the authorization model was created.
+	Id string `json:"id,omitempty"`
+
+	// Origin of the ID, i.e. "Created", "Adopted" or "Existing".
+	Origin ModelOrigin `json:"origin,omitempty"`
+
+	// CreatedAt is the time the version was added to the AuthorizationModel resource.
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+
+	// LastError is the error from the last failed attempt to synchronize the version.
+	LastError string `json:"lastError,omitempty"`
+
+	// FailedAssertions are the assertions which failed in the last attempt to synchronize the version,
+	// limited to the first 20.
+	// +optional
+	FailedAssertions []string `json:"failedAssertions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.state`
+//+kubebuilder:printcolumn:name="Latest Version",type=string,JSONPath=`.status.versions[0].version`
+//+kubebuilder:printcolumn:name="Model ID",type=string,JSONPath=`.status.versions[0].id`
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // AuthorizationModelRequest is the Schema for the authorizationmodelrequests API
@@ -143,7 +258,157 @@ func (v ModelVersion) String() string {
 type AuthorizationModelRequestInstance struct {
 	// ExistingAuthorizationModelId specifies the ID of an existing authorization model in the system.
 	// Only applicable when migrating from existing infrastructure where the operator was not previously used.
-	ExistingAuthorizationModelId string       `json:"existingAuthorizationModelId,omitempty"`
-	AuthorizationModel           string       `json:"authorizationModel,omitempty"`
-	Version                      ModelVersion `json:"version,omitempty"`
+	ExistingAuthorizationModelId string `json:"existingAuthorizationModelId,omitempty"`
+	AuthorizationModel           string `json:"authorizationModel,omitempty"`
+
+	// Format of AuthorizationModel, or of the content loaded with AuthorizationModelFrom.
+	// Valid values are:
+	// - "dsl" (default): the OpenFGA DSL;
+	// - "json": the json syntax of the OpenFGA API, i.e. the body of a WriteAuthorizationModel request.
+	// +kubebuilder:default=dsl
+	// +optional
+	Format AuthorizationModelFormat `json:"format,omitempty"`
+
+	// AuthorizationModelFrom loads the authorization model from a ConfigMap or Secret in the namespace
+	// of the request, instead of inlining it in AuthorizationModel.
+	// +optional
+	AuthorizationModelFrom *AuthorizationModelSource `json:"authorizationModelFrom,omitempty"`
+
+	// ModularAuthorizationModel is an authorization model split into module files, which are combined
+	// into a single authorization model.
+	// +optional
+	ModularAuthorizationModel *ModularAuthorizationModel `json:"modularAuthorizationModel,omitempty"`
+
+	// Assertions are tests of the authorization model, which are run against OpenFGA once the model is
+	// written. The version is only added to the AuthorizationModel resource when all of them pass.
+	// +optional
+	Assertions *AuthorizationModelAssertions `json:"assertions,omitempty"`
+
+	// AssertionsFrom loads the assertions from an OpenFGA test file (.fga.yaml) in a ConfigMap in the
+	// namespace of the request, instead of inlining them in Assertions. The tuples and tests of the file
+	// are used, its model is ignored.
+	// +optional
+	AssertionsFrom *corev1.ConfigMapKeySelector `json:"assertionsFrom,omitempty"`
+
+	// WriteAssertions stores the check assertions with the authorization model in OpenFGA once they
+	// passed, such that they show up in tools like the OpenFGA playground. OpenFGA evaluates stored
+	// assertions against the tuples of the store, without the tuples of the tests.
+	// +optional
+	WriteAssertions bool `json:"writeAssertions,omitempty"`
+
+	Version ModelVersion `json:"version,omitempty"`
+}
+
+// AuthorizationModelAssertions are the tests of an authorization model, like the tests of an OpenFGA
+// test file.
+type AuthorizationModelAssertions struct {
+	// Tuples exist during all tests.
+	// +optional
+	Tuples []RelationshipTuple `json:"tuples,omitempty"`
+
+	// Tests of the authorization model.
+	Tests []AssertionTest `json:"tests"`
+}
+
+// AssertionTest is a test of an authorization model. The tuples of the test are sent as contextual
+// tuples, such that they are never written to the store.
+type AssertionTest struct {
+	// Name of the test, which is shown with failing assertions.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Tuples exist during the test, in addition to the tuples of all tests.
+	// +optional
+	Tuples []RelationshipTuple `json:"tuples,omitempty"`
+
+	// Check asserts whether users have relations to objects.
+	// +optional
+	Check []CheckAssertion `json:"check,omitempty"`
+
+	// ListObjects asserts the objects of a type users have relations to.
+	// +optional
+	ListObjects []ListObjectsAssertion `json:"listObjects,omitempty"`
+}
+
+// CheckAssertion asserts the relations of a user to an object.
+type CheckAssertion struct {
+	// User of the check, e.g. "user:anne".
+	User string `json:"user"`
+
+	// Object of the check, e.g. "document:roadmap".
+	Object string `json:"object"`
+
+	// Assertions map relations to whether the user is expected to have them, e.g. {"viewer": true}.
+	Assertions map[string]bool `json:"assertions"`
+}
+
+// ListObjectsAssertion asserts the objects of a type a user has relations to.
+type ListObjectsAssertion struct {
+	// User of the list objects call, e.g. "user:anne".
+	User string `json:"user"`
+
+	// Type of the objects, e.g. "document".
+	Type string `json:"type"`
+
+	// Assertions map relations to the objects the user is expected to have them to, in any order.
+	Assertions map[string][]string `json:"assertions"`
+}
+
+// ModularAuthorizationModel is an authorization model consisting of an fga.mod manifest and the module
+// files listed in its contents.
+type ModularAuthorizationModel struct {
+	// Manifest is the content of the fga.mod file, e.g.
+	//   schema: '1.2'
+	//   contents:
+	//     - core.fga
+	//     - issues.fga
+	// +optional
+	Manifest string `json:"manifest,omitempty"`
+
+	// ManifestFrom loads the manifest from a ConfigMap in the namespace of the request, instead of
+	// inlining it in Manifest.
+	// +optional
+	ManifestFrom *corev1.ConfigMapKeySelector `json:"manifestFrom,omitempty"`
+
+	// Modules are the module files listed in the contents of the manifest.
+	Modules []ModuleFile `json:"modules"`
+}
+
+// ModuleFile is a module file of a modular authorization model.
+type ModuleFile struct {
+	// Name of the module file as listed in the contents of the manifest, e.g. "core.fga".
+	Name string `json:"name"`
+
+	// Contents of the module file in DSL.
+	// +optional
+	Contents string `json:"contents,omitempty"`
+
+	// ContentsFrom loads the contents from a ConfigMap in the namespace of the request, instead of
+	// inlining them in Contents.
+	// +optional
+	ContentsFrom *corev1.ConfigMapKeySelector `json:"contentsFrom,omitempty"`
+}
+
+// AuthorizationModelFormat is the format an authorization model is written in.
+// +kubebuilder:validation:Enum=dsl;json
+type AuthorizationModelFormat string
+
+const (
+	// FormatDSL is the OpenFGA DSL.
+	FormatDSL AuthorizationModelFormat = "dsl"
+
+	// FormatJSON is the json syntax of the OpenFGA API.
+	FormatJSON AuthorizationModelFormat = "json"
+)
+
+// AuthorizationModelSource selects the key of a ConfigMap or a Secret which contains an authorization model.
+// Exactly one of ConfigMapKeyRef and SecretKeyRef must be set.
+type AuthorizationModelSource struct {
+	// ConfigMapKeyRef selects a key of a ConfigMap.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// SecretKeyRef selects a key of a Secret.
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
@@ -0,0 +1,221 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConnectionKind is the kind of resource a connection reference points to.
+// +kubebuilder:validation:Enum=FGAConnection;ClusterFGAConnection
+type ConnectionKind string
+
+const (
+	// ConnectionKindNamespaced is an FGAConnection in the namespace of the referencing resource.
+	ConnectionKindNamespaced ConnectionKind = "FGAConnection"
+
+	// ConnectionKindCluster is a ClusterFGAConnection.
+	ConnectionKindCluster ConnectionKind = "ClusterFGAConnection"
+)
+
+// ConnectionReference references an FGAConnection or a ClusterFGAConnection.
+type ConnectionReference struct {
+	// Kind of the connection, "FGAConnection" (default) or "ClusterFGAConnection".
+	// +kubebuilder:default=FGAConnection
+	// +optional
+	Kind ConnectionKind `json:"kind,omitempty"`
+
+	// Name of the connection.
+	Name string `json:"name"`
+}
+
+func (r ConnectionReference) String() string {
+	return fmt.Sprintf("%s/%s", r.kind(), r.Name)
+}
+
+func (r ConnectionReference) kind() ConnectionKind {
+	if r.Kind == "" {
+		return ConnectionKindNamespaced
+	}
+	return r.Kind
+}
+
+// IsCluster returns true when the reference points to a ClusterFGAConnection.
+func (r ConnectionReference) IsCluster() bool {
+	return r.kind() == ConnectionKindCluster
+}
+
+// SameConnection returns true when both references point to the same connection, where nil is the
+// OpenFGA server configured for the operator.
+func SameConnection(a, b *ConnectionReference) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.kind() == b.kind() && a.Name == b.Name
+}
+
+// ConnectionAuthMode is the way the operator authenticates to OpenFGA.
+// +kubebuilder:validation:Enum=none;apiToken;clientCredentials
+type ConnectionAuthMode string
+
+const (
+	ConnectionAuthModeNone              ConnectionAuthMode = "none"
+	ConnectionAuthModeApiToken          ConnectionAuthMode = "apiToken"
+	ConnectionAuthModeClientCredentials ConnectionAuthMode = "clientCredentials"
+)
+
+// SecretKeyReference selects a key of a Secret.
+type SecretKeyReference struct {
+	// Name of the secret.
+	Name string `json:"name"`
+
+	// Namespace of the secret. Only applicable to a ClusterFGAConnection, for which it's required. The
+	// secrets of an FGAConnection are in its namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key of the secret.
+	Key string `json:"key"`
+}
+
+// SecretReference references a Secret.
+type SecretReference struct {
+	// Name of the secret.
+	Name string `json:"name"`
+
+	// Namespace of the secret. Only applicable to a ClusterFGAConnection, for which it's required. The
+	// secrets of an FGAConnection are in its namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// FGAConnectionSpec defines an OpenFGA server and how the operator connects to it.
+type FGAConnectionSpec struct {
+	// ApiUrl is the URL of the OpenFGA API, e.g. "https://openfga.eu-west-1.example.com".
+	// +kubebuilder:validation:MinLength=1
+	ApiUrl string `json:"apiUrl"`
+
+	// AuthMode selects how the operator authenticates to OpenFGA.
+	// Valid values are:
+	// - "none": no authentication;
+	// - "apiToken": the preshared key of apiTokenSecretRef;
+	// - "clientCredentials": an access token of the OAuth2 client credentials flow.
+	// Inferred from the credentials when not set.
+	// +optional
+	AuthMode ConnectionAuthMode `json:"authMode,omitempty"`
+
+	// ApiTokenSecretRef selects the preshared key used for authentication to OpenFGA.
+	// +optional
+	ApiTokenSecretRef *SecretKeyReference `json:"apiTokenSecretRef,omitempty"`
+
+	// ClientCredentials configure the OAuth2 client credentials flow.
+	// +optional
+	ClientCredentials *ClientCredentials `json:"clientCredentials,omitempty"`
+
+	// TLS configures the TLS connections to OpenFGA and the token issuer.
+	// +optional
+	TLS *ConnectionTLS `json:"tls,omitempty"`
+}
+
+// ClientCredentials configure the OAuth2 client credentials flow, in which the operator gets an access
+// token from the token issuer.
+type ClientCredentials struct {
+	// ClientId of the operator.
+	ClientId string `json:"clientId"`
+
+	// ClientSecretRef selects the client secret of the operator.
+	ClientSecretRef SecretKeyReference `json:"clientSecretRef"`
+
+	// ApiTokenIssuer is the token issuer, e.g. "auth.example.com".
+	ApiTokenIssuer string `json:"apiTokenIssuer"`
+
+	// ApiAudience is the audience of the access token.
+	// +optional
+	ApiAudience string `json:"apiAudience,omitempty"`
+
+	// ApiScopes of the access token, separated by spaces.
+	// +optional
+	ApiScopes string `json:"apiScopes,omitempty"`
+}
+
+// ConnectionTLS configures TLS connections.
+type ConnectionTLS struct {
+	// CaSecretRef selects a PEM bundle of certificate authorities trusted in addition to the system ones,
+	// e.g. the key "ca.crt" of a secret.
+	// +optional
+	CaSecretRef *SecretKeyReference `json:"caSecretRef,omitempty"`
+
+	// ClientCertificateSecretRef references a secret of type "kubernetes.io/tls" with the client
+	// certificate and key for mutual TLS in the keys "tls.crt" and "tls.key".
+	// +optional
+	ClientCertificateSecretRef *SecretReference `json:"clientCertificateSecretRef,omitempty"`
+
+	// InsecureSkipVerify disables the verification of the certificate of OpenFGA. Only use this for development.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:printcolumn:name="API URL",type=string,JSONPath=`.spec.apiUrl`
+//+kubebuilder:printcolumn:name="Auth Mode",type=string,JSONPath=`.spec.authMode`
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// FGAConnection is an OpenFGA server which authorization model requests in its namespace can be synchronized to.
+type FGAConnection struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FGAConnectionSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FGAConnectionList contains a list of FGAConnection
+type FGAConnectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FGAConnection `json:"items"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="API URL",type=string,JSONPath=`.spec.apiUrl`
+//+kubebuilder:printcolumn:name="Auth Mode",type=string,JSONPath=`.spec.authMode`
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterFGAConnection is an OpenFGA server which authorization model requests in any namespace can be
+// synchronized to.
+type ClusterFGAConnection struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FGAConnectionSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterFGAConnectionList contains a list of ClusterFGAConnection
+type ClusterFGAConnectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterFGAConnection `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FGAConnection{}, &FGAConnectionList{}, &ClusterFGAConnection{}, &ClusterFGAConnectionList{})
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RelationshipTuplesFinalizer is set on relationship tuples with the deletion policy "Delete", and removed
+// once the tuples managed by the operator are deleted from OpenFGA.
+const RelationshipTuplesFinalizer = "extensions.fga-operator/delete-tuples"
+
+// Condition types set on the status of RelationshipTuples.
+const (
+	// RelationshipTuplesConditionReady is true when all tuples exist in the store.
+	RelationshipTuplesConditionReady = "Ready"
+)
+
+// StoreReference references a Store in the namespace of the referencing resource.
+type StoreReference struct {
+	// Name of the store, which is the name of its AuthorizationModelRequest.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// RelationshipTuple is a relationship between a user and an object in OpenFGA.
+type RelationshipTuple struct {
+	// User of the relationship, e.g. "user:anne", "team:admins#member" or "user:*".
+	// +kubebuilder:validation:MinLength=1
+	User string `json:"user"`
+
+	// Relation of the user to the object, e.g. "admin".
+	// +kubebuilder:validation:MinLength=1
+	Relation string `json:"relation"`
+
+	// Object of the relationship, e.g. "organization:acme".
+	// +kubebuilder:validation:MinLength=1
+	Object string `json:"object"`
+}
+
+func (t RelationshipTuple) String() string {
+	return fmt.Sprintf("%s#%s@%s", t.Object, t.Relation, t.User)
+}
+
+// RelationshipTuplesSpec defines the desired state of RelationshipTuples
+type RelationshipTuplesSpec struct {
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// StoreRef references the store the tuples are written to.
+	StoreRef StoreReference `json:"storeRef"`
+
+	// Tuples which are written to the store. Tuples removed from the list are deleted from the store if
+	// they were written by the operator.
+	// +listType=atomic
+	// +optional
+	Tuples []RelationshipTuple `json:"tuples,omitempty"`
+
+	// DeletionPolicy defines what happens with the tuples written by the operator when the resource is deleted.
+	// Valid values are:
+	// - "Delete" (default): the tuples are deleted from the store;
+	// - "Retain": the tuples are kept in the store.
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// RelationshipTupleError is a tuple which couldn't be written or deleted.
+type RelationshipTupleError struct {
+	RelationshipTuple `json:",inline"`
+
+	// Message of the failure.
+	Message string `json:"message"`
+}
+
+// RelationshipTuplesStatus defines the observed state of RelationshipTuples
+type RelationshipTuplesStatus struct {
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// StoreId is the ID of the store the managed tuples were written to.
+	StoreId string `json:"storeId,omitempty"`
+
+	// ObservedGeneration is the generation of the spec which was last applied.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Applied is the number of tuples of the spec which exist in the store.
+	Applied int32 `json:"applied"`
+
+	// Written is the number of tuples written in the last reconciliation.
+	Written int32 `json:"written"`
+
+	// Pruned is the number of tuples deleted in the last reconciliation.
+	Pruned int32 `json:"pruned"`
+
+	// Failed is the number of tuples which couldn't be written or deleted.
+	Failed int32 `json:"failed"`
+
+	// LastApplied is the last time the tuples were applied.
+	LastApplied *metav1.Time `json:"lastApplied,omitempty"`
+
+	// ManagedTuples are the tuples written by the operator, which are deleted when they are removed from the
+	// spec. Tuples which already existed when they were added to the spec aren't managed, and are never deleted.
+	// +listType=atomic
+	// +optional
+	ManagedTuples []RelationshipTuple `json:"managedTuples,omitempty"`
+
+	// Errors of the tuples which couldn't be written or deleted.
+	// +listType=atomic
+	// +optional
+	Errors []RelationshipTupleError `json:"errors,omitempty"`
+
+	// Conditions describe the latest observations of the tuples.
+	// Known condition types are "Ready".
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:path=relationshiptuples,singular=relationshiptuples
+//+kubebuilder:printcolumn:name="Store",type=string,JSONPath=`.spec.storeRef.name`
+//+kubebuilder:printcolumn:name="Applied",type=integer,JSONPath=`.status.applied`
+//+kubebuilder:printcolumn:name="Failed",type=integer,JSONPath=`.status.failed`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+
+// RelationshipTuples is the Schema for the relationshiptuples API
+type RelationshipTuples struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RelationshipTuplesSpec   `json:"spec,omitempty"`
+	Status RelationshipTuplesStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// RelationshipTuplesList contains a list of RelationshipTuples
+type RelationshipTuplesList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RelationshipTuples `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RelationshipTuples{}, &RelationshipTuplesList{})
+}
@@ -24,25 +24,120 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// StoreRecreatePolicy defines what the operator does when the store no longer exists in OpenFGA.
+// +kubebuilder:validation:Enum=Never;IfNotFound
+type StoreRecreatePolicy string
+
+const (
+	// RecreateNever only reports that the store is missing.
+	RecreateNever StoreRecreatePolicy = "Never"
+
+	// RecreateIfNotFound creates a new store in OpenFGA, and recreates the authorization models in it.
+	RecreateIfNotFound StoreRecreatePolicy = "IfNotFound"
+)
+
+// DeletionPolicy defines what happens with the store or the tuples in OpenFGA when their resource is deleted.
+// +kubebuilder:validation:Enum=Retain;Delete
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyRetain keeps the store or the tuples in OpenFGA.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+
+	// DeletionPolicyDelete deletes the store or the tuples from OpenFGA.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+)
+
+// StoreFinalizer is set on stores with the deletion policy "Delete", and removed once the store is
+// deleted from OpenFGA.
+const StoreFinalizer = "extensions.fga-operator/delete-store"
+
+// Condition types set on the status of a Store.
+const (
+	// StoreConditionReady is true when the store has been verified to exist in OpenFGA.
+	StoreConditionReady = "Ready"
+
+	// StoreConditionNotFound is true when the store doesn't exist in OpenFGA.
+	StoreConditionNotFound = "NotFound"
+)
+
+// StoreOrigin describes where the store of a Store resource comes from.
+// +kubebuilder:validation:Enum=Created;Adopted;Existing
+type StoreOrigin string
+
+const (
+	// StoreOriginCreated means the store was created in OpenFGA by the operator.
+	StoreOriginCreated StoreOrigin = "Created"
+
+	// StoreOriginAdopted means a store with the name of the request already existed in OpenFGA, and has been
+	// reused instead of creating a new one.
+	StoreOriginAdopted StoreOrigin = "Adopted"
+
+	// StoreOriginExisting means the store was set with existingStoreId.
+	StoreOriginExisting StoreOrigin = "Existing"
+)
+
 // StoreSpec defines the desired state of Store
 type StoreSpec struct {
-	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// Identification which is given by OpenFGA when the Store is created
 	Id string `json:"id,omitempty"`
+
+	// RecreatePolicy defines what happens when the store no longer exists in OpenFGA.
+	// Valid values are:
+	// - "Never" (default): the store is reported as not found;
+	// - "IfNotFound": a new store is created and the authorization models are created in it.
+	// +kubebuilder:default=Never
+	// +optional
+	RecreatePolicy StoreRecreatePolicy `json:"recreatePolicy,omitempty"`
+
+	// DeletionPolicy defines what happens with the store in OpenFGA when the resource is deleted.
+	// Valid values are:
+	// - "Retain" (default): the store is kept in OpenFGA;
+	// - "Delete": the store is deleted from OpenFGA when the operator created it.
+	// +kubebuilder:default=Retain
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// ConnectionRef references the connection of the OpenFGA server the store lives on. The OpenFGA server
+	// configured for the operator is used when it's not set.
+	// +optional
+	ConnectionRef *ConnectionReference `json:"connectionRef,omitempty"`
 }
 
 // StoreStatus defines the observed state of Store
 type StoreStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
 	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+
+	// LastVerified is the last time the store was verified to exist in OpenFGA.
+	LastVerified *metav1.Time `json:"lastVerified,omitempty"`
+
+	// Origin is where the store comes from. Only stores created by the operator are deleted from OpenFGA with
+	// the deletion policy "Delete".
+	// +optional
+	Origin StoreOrigin `json:"origin,omitempty"`
+
+	// ApiUrl is the URL of the OpenFGA API the store lives on, which is injected into the workloads requesting it.
+	// +optional
+	ApiUrl string `json:"apiUrl,omitempty"`
+
+	// Conditions describe the latest observations of the store.
+	// Known condition types are "Ready" and "NotFound".
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Store ID",type=string,JSONPath=`.spec.id`
+//+kubebuilder:printcolumn:name="Connection",type=string,JSONPath=`.spec.connectionRef.name`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Last Verified",type="date",JSONPath=".status.lastVerified"
 
 // Store is the Schema for the stores API
 type Store struct {
@@ -0,0 +1,203 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VersionConstraint selects model versions with the syntax of npm version ranges:
+//   - an exact version, e.g. "1.2.3" or "=1.2.3";
+//   - a version with wildcards, e.g. "1.x", "1.2.*" or "1", matching every version it leaves out;
+//   - a comparison, e.g. ">=1.1.0", ">1", "<2.0.0" or "<=1.2";
+//   - a tilde range allowing patch updates, e.g. "~1.2" or "~1.2.3", or minor updates for "~1";
+//   - a caret range allowing updates which don't change the left-most non-zero part, e.g. "^1.2.3".
+//
+// Comparisons separated by spaces must all match, e.g. ">=1.1.0 <2.0.0", and alternatives are separated
+// by "||", e.g. "1.x || >=3.0.0".
+// +kubebuilder:object:generate=false
+type VersionConstraint struct {
+	raw          string
+	alternatives [][]versionRange
+}
+
+// versionRange matches versions from the lower bound up to, but not including, the upper bound. A nil bound
+// is unbounded.
+// +kubebuilder:object:generate=false
+type versionRange struct {
+	lower *ModelVersion
+	upper *ModelVersion
+}
+
+// ParseVersionConstraint parses a version constraint.
+func ParseVersionConstraint(constraint string) (VersionConstraint, error) {
+	result := VersionConstraint{raw: constraint}
+	for _, alternative := range strings.Split(constraint, "||") {
+		ranges, err := parseComparisons(alternative)
+		if err != nil {
+			return VersionConstraint{}, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+		}
+		result.alternatives = append(result.alternatives, ranges)
+	}
+	return result, nil
+}
+
+// Matches returns true when the version satisfies the constraint.
+func (c VersionConstraint) Matches(version ModelVersion) bool {
+	for _, ranges := range c.alternatives {
+		matches := true
+		for _, versionRange := range ranges {
+			matches = matches && versionRange.matches(version)
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+func (c VersionConstraint) String() string {
+	return c.raw
+}
+
+func (r versionRange) matches(version ModelVersion) bool {
+	if r.lower != nil && version.lessThan(*r.lower) {
+		return false
+	}
+	return r.upper == nil || version.lessThan(*r.upper)
+}
+
+func parseComparisons(alternative string) ([]versionRange, error) {
+	fields := strings.Fields(alternative)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty constraint")
+	}
+	var ranges []versionRange
+	for i := 0; i < len(fields); i++ {
+		comparison := fields[i]
+		// The version may be separated from its operator by a space, e.g. ">= 1.1.0".
+		if strings.TrimLeft(comparison, "<>=~^") == "" && i+1 < len(fields) {
+			i++
+			comparison += fields[i]
+		}
+		versionRange, err := parseComparison(comparison)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, versionRange)
+	}
+	return ranges, nil
+}
+
+func parseComparison(comparison string) (versionRange, error) {
+	operator := comparison[:len(comparison)-len(strings.TrimLeft(comparison, "<>=~^"))]
+	partial, err := parsePartialVersion(comparison[len(operator):])
+	if err != nil {
+		return versionRange{}, err
+	}
+	lower, upper := partial.lower(), partial.upper()
+	switch operator {
+	case "", "=":
+		return versionRange{lower: &lower, upper: upper}, nil
+	case ">=":
+		return versionRange{lower: &lower}, nil
+	case ">":
+		if upper == nil {
+			return versionRange{}, fmt.Errorf("%s matches no version", comparison)
+		}
+		return versionRange{lower: upper}, nil
+	case "<":
+		return versionRange{upper: &lower}, nil
+	case "<=":
+		return versionRange{upper: upper}, nil
+	case "~":
+		if partial.parts == 3 {
+			upper = &ModelVersion{Major: lower.Major, Minor: lower.Minor + 1}
+		}
+		return versionRange{lower: &lower, upper: upper}, nil
+	case "^":
+		switch {
+		case lower.Major > 0 || partial.parts == 1:
+			upper = &ModelVersion{Major: lower.Major + 1}
+		case lower.Minor > 0 || partial.parts == 2:
+			upper = &ModelVersion{Minor: lower.Minor + 1}
+		}
+		return versionRange{lower: &lower, upper: upper}, nil
+	default:
+		return versionRange{}, fmt.Errorf("unknown operator %s", operator)
+	}
+}
+
+// partialVersion is a version of which only the first parts are set, the others being wildcards.
+// +kubebuilder:object:generate=false
+type partialVersion struct {
+	numbers [3]int
+	parts   int
+}
+
+func parsePartialVersion(version string) (partialVersion, error) {
+	result := partialVersion{}
+	parts := strings.Split(version, ".")
+	if len(parts) > 3 {
+		return partialVersion{}, fmt.Errorf("invalid version %s", version)
+	}
+	wildcard := false
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			wildcard = true
+			continue
+		}
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 || wildcard {
+			return partialVersion{}, fmt.Errorf("invalid version %s", version)
+		}
+		result.numbers[i] = number
+		result.parts = i + 1
+	}
+	return result, nil
+}
+
+func (p partialVersion) lower() ModelVersion {
+	return ModelVersion{Major: p.numbers[0], Minor: p.numbers[1], Patch: p.numbers[2]}
+}
+
+// upper returns the lowest version after the versions matched by the partial version, or nil when it
+// matches every version.
+func (p partialVersion) upper() *ModelVersion {
+	switch p.parts {
+	case 0:
+		return nil
+	case 1:
+		return &ModelVersion{Major: p.numbers[0] + 1}
+	case 2:
+		return &ModelVersion{Major: p.numbers[0], Minor: p.numbers[1] + 1}
+	default:
+		return &ModelVersion{Major: p.numbers[0], Minor: p.numbers[1], Patch: p.numbers[2] + 1}
+	}
+}
+
+func (v ModelVersion) lessThan(other ModelVersion) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	return v.Patch < other.Patch
+}
@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"testing"
+)
+
+func TestVersionConstraintMatches(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matching   []string
+		others     []string
+	}{
+		{constraint: "1.2.3", matching: []string{"1.2.3"}, others: []string{"1.2.2", "1.2.4", "1.3.0"}},
+		{constraint: "=1.2.3", matching: []string{"1.2.3"}, others: []string{"1.2.4"}},
+		{constraint: "1.x", matching: []string{"1.0.0", "1.9.9"}, others: []string{"0.9.0", "2.0.0"}},
+		{constraint: "1", matching: []string{"1.0.0", "1.9.9"}, others: []string{"2.0.0"}},
+		{constraint: "1.2.*", matching: []string{"1.2.0", "1.2.9"}, others: []string{"1.1.9", "1.3.0"}},
+		{constraint: "*", matching: []string{"0.0.0", "9.9.9"}},
+		{constraint: "~1.2", matching: []string{"1.2.0", "1.2.9"}, others: []string{"1.1.9", "1.3.0"}},
+		{constraint: "~1.2.3", matching: []string{"1.2.3", "1.2.9"}, others: []string{"1.2.2", "1.3.0"}},
+		{constraint: "~1", matching: []string{"1.0.0", "1.9.0"}, others: []string{"2.0.0"}},
+		{constraint: "^1.2.3", matching: []string{"1.2.3", "1.9.0"}, others: []string{"1.2.2", "2.0.0"}},
+		{constraint: "^0.2.3", matching: []string{"0.2.3", "0.2.9"}, others: []string{"0.3.0"}},
+		{constraint: "^0.0.3", matching: []string{"0.0.3"}, others: []string{"0.0.4"}},
+		{constraint: ">=1.1.0 <2.0.0", matching: []string{"1.1.0", "1.9.9"}, others: []string{"1.0.9", "2.0.0"}},
+		{constraint: ">= 1.1.0 < 2", matching: []string{"1.1.0", "1.9.9"}, others: []string{"1.0.9", "2.0.0"}},
+		{constraint: ">1.2", matching: []string{"1.3.0"}, others: []string{"1.2.9"}},
+		{constraint: "<=1.2", matching: []string{"1.2.9"}, others: []string{"1.3.0"}},
+		{constraint: "1.x || >=3.0.0", matching: []string{"1.5.0", "3.1.0"}, others: []string{"2.0.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			// Arrange
+			constraint, err := ParseVersionConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, versions := range []struct {
+				versions []string
+				expected bool
+			}{{tt.matching, true}, {tt.others, false}} {
+				for _, version := range versions.versions {
+					modelVersion, err := ModelVersionFromString(version)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					// Act
+					matches := constraint.Matches(modelVersion)
+
+					// Assert
+					if matches != versions.expected {
+						t.Errorf("expected %s matching %s to be %t", tt.constraint, version, versions.expected)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParseVersionConstraintError(t *testing.T) {
+	for _, constraint := range []string{"", "1.2.3.4", "a", "1.x.2", ">*", "!1.2", "1.2 ||", "-1"} {
+		t.Run(constraint, func(t *testing.T) {
+			// Act
+			_, err := ParseVersionConstraint(constraint)
+
+			// Assert
+			if err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
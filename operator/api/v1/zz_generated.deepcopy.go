@@ -21,16 +21,52 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssertionTest) DeepCopyInto(out *AssertionTest) {
+	*out = *in
+	if in.Tuples != nil {
+		in, out := &in.Tuples, &out.Tuples
+		*out = make([]RelationshipTuple, len(*in))
+		copy(*out, *in)
+	}
+	if in.Check != nil {
+		in, out := &in.Check, &out.Check
+		*out = make([]CheckAssertion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ListObjects != nil {
+		in, out := &in.ListObjects, &out.ListObjects
+		*out = make([]ListObjectsAssertion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssertionTest.
+func (in *AssertionTest) DeepCopy() *AssertionTest {
+	if in == nil {
+		return nil
+	}
+	out := new(AssertionTest)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthorizationModel) DeepCopyInto(out *AuthorizationModel) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationModel.
@@ -51,9 +87,41 @@ func (in *AuthorizationModel) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthorizationModelAssertions) DeepCopyInto(out *AuthorizationModelAssertions) {
+	*out = *in
+	if in.Tuples != nil {
+		in, out := &in.Tuples, &out.Tuples
+		*out = make([]RelationshipTuple, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tests != nil {
+		in, out := &in.Tests, &out.Tests
+		*out = make([]AssertionTest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationModelAssertions.
+func (in *AuthorizationModelAssertions) DeepCopy() *AuthorizationModelAssertions {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthorizationModelAssertions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthorizationModelDefinition) DeepCopyInto(out *AuthorizationModelDefinition) {
 	*out = *in
+	if in.ModularAuthorizationModel != nil {
+		in, out := &in.ModularAuthorizationModel, &out.ModularAuthorizationModel
+		*out = new(ModularAuthorizationModel)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Version = in.Version
 }
 
@@ -70,6 +138,11 @@ func (in *AuthorizationModelDefinition) DeepCopy() *AuthorizationModelDefinition
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthorizationModelInstance) DeepCopyInto(out *AuthorizationModelInstance) {
 	*out = *in
+	if in.ModularAuthorizationModel != nil {
+		in, out := &in.ModularAuthorizationModel, &out.ModularAuthorizationModel
+		*out = new(ModularAuthorizationModel)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Version = in.Version
 	if in.CreatedAt != nil {
 		in, out := &in.CreatedAt, &out.CreatedAt
@@ -125,7 +198,7 @@ func (in *AuthorizationModelRequest) DeepCopyInto(out *AuthorizationModelRequest
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationModelRequest.
@@ -149,6 +222,26 @@ func (in *AuthorizationModelRequest) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthorizationModelRequestInstance) DeepCopyInto(out *AuthorizationModelRequestInstance) {
 	*out = *in
+	if in.AuthorizationModelFrom != nil {
+		in, out := &in.AuthorizationModelFrom, &out.AuthorizationModelFrom
+		*out = new(AuthorizationModelSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ModularAuthorizationModel != nil {
+		in, out := &in.ModularAuthorizationModel, &out.ModularAuthorizationModel
+		*out = new(ModularAuthorizationModel)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Assertions != nil {
+		in, out := &in.Assertions, &out.Assertions
+		*out = new(AuthorizationModelAssertions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AssertionsFrom != nil {
+		in, out := &in.AssertionsFrom, &out.AssertionsFrom
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Version = in.Version
 }
 
@@ -200,8 +293,30 @@ func (in *AuthorizationModelRequestSpec) DeepCopyInto(out *AuthorizationModelReq
 	if in.Instances != nil {
 		in, out := &in.Instances, &out.Instances
 		*out = make([]AuthorizationModelRequestInstance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConnectionRef != nil {
+		in, out := &in.ConnectionRef, &out.ConnectionRef
+		*out = new(ConnectionReference)
+		**out = **in
+	}
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]ModelChannel, len(*in))
 		copy(*out, *in)
 	}
+	if in.RolloutStrategy != nil {
+		in, out := &in.RolloutStrategy, &out.RolloutStrategy
+		*out = new(RolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutomaticRollback != nil {
+		in, out := &in.AutomaticRollback, &out.AutomaticRollback
+		*out = new(AutomaticRollback)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationModelRequestSpec.
@@ -217,6 +332,20 @@ func (in *AuthorizationModelRequestSpec) DeepCopy() *AuthorizationModelRequestSp
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthorizationModelRequestStatus) DeepCopyInto(out *AuthorizationModelRequestStatus) {
 	*out = *in
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]AuthorizationModelVersionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationModelRequestStatus.
@@ -229,6 +358,31 @@ func (in *AuthorizationModelRequestStatus) DeepCopy() *AuthorizationModelRequest
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthorizationModelSource) DeepCopyInto(out *AuthorizationModelSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationModelSource.
+func (in *AuthorizationModelSource) DeepCopy() *AuthorizationModelSource {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthorizationModelSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthorizationModelSpec) DeepCopyInto(out *AuthorizationModelSpec) {
 	*out = *in
@@ -239,6 +393,21 @@ func (in *AuthorizationModelSpec) DeepCopyInto(out *AuthorizationModelSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]ModelChannel, len(*in))
+		copy(*out, *in)
+	}
+	if in.RolloutStrategy != nil {
+		in, out := &in.RolloutStrategy, &out.RolloutStrategy
+		*out = new(RolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutomaticRollback != nil {
+		in, out := &in.AutomaticRollback, &out.AutomaticRollback
+		*out = new(AutomaticRollback)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationModelSpec.
@@ -254,6 +423,34 @@ func (in *AuthorizationModelSpec) DeepCopy() *AuthorizationModelSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthorizationModelStatus) DeepCopyInto(out *AuthorizationModelStatus) {
 	*out = *in
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]BoundWorkload, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailedVersions != nil {
+		in, out := &in.FailedVersions, &out.FailedVersions
+		*out = make([]FailedVersion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationModelStatus.
@@ -266,6 +463,65 @@ func (in *AuthorizationModelStatus) DeepCopy() *AuthorizationModelStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthorizationModelVersionStatus) DeepCopyInto(out *AuthorizationModelVersionStatus) {
+	*out = *in
+	if in.CreatedAt != nil {
+		in, out := &in.CreatedAt, &out.CreatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.FailedAssertions != nil {
+		in, out := &in.FailedAssertions, &out.FailedAssertions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationModelVersionStatus.
+func (in *AuthorizationModelVersionStatus) DeepCopy() *AuthorizationModelVersionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthorizationModelVersionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutomaticRollback) DeepCopyInto(out *AutomaticRollback) {
+	*out = *in
+	if in.ProgressDeadline != nil {
+		in, out := &in.ProgressDeadline, &out.ProgressDeadline
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutomaticRollback.
+func (in *AutomaticRollback) DeepCopy() *AutomaticRollback {
+	if in == nil {
+		return nil
+	}
+	out := new(AutomaticRollback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BoundWorkload) DeepCopyInto(out *BoundWorkload) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BoundWorkload.
+func (in *BoundWorkload) DeepCopy() *BoundWorkload {
+	if in == nil {
+		return nil
+	}
+	out := new(BoundWorkload)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in ByVersionAndCreatedAtDesc) DeepCopyInto(out *ByVersionAndCreatedAtDesc) {
 	{
@@ -288,41 +544,63 @@ func (in ByVersionAndCreatedAtDesc) DeepCopy() ByVersionAndCreatedAtDesc {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ModelVersion) DeepCopyInto(out *ModelVersion) {
+func (in *CheckAssertion) DeepCopyInto(out *CheckAssertion) {
 	*out = *in
+	if in.Assertions != nil {
+		in, out := &in.Assertions, &out.Assertions
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelVersion.
-func (in *ModelVersion) DeepCopy() *ModelVersion {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheckAssertion.
+func (in *CheckAssertion) DeepCopy() *CheckAssertion {
 	if in == nil {
 		return nil
 	}
-	out := new(ModelVersion)
+	out := new(CheckAssertion)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Store) DeepCopyInto(out *Store) {
+func (in *ClientCredentials) DeepCopyInto(out *ClientCredentials) {
+	*out = *in
+	out.ClientSecretRef = in.ClientSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientCredentials.
+func (in *ClientCredentials) DeepCopy() *ClientCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterFGAConnection) DeepCopyInto(out *ClusterFGAConnection) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	in.Status.DeepCopyInto(&out.Status)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Store.
-func (in *Store) DeepCopy() *Store {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterFGAConnection.
+func (in *ClusterFGAConnection) DeepCopy() *ClusterFGAConnection {
 	if in == nil {
 		return nil
 	}
-	out := new(Store)
+	out := new(ClusterFGAConnection)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Store) DeepCopyObject() runtime.Object {
+func (in *ClusterFGAConnection) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -330,31 +608,31 @@ func (in *Store) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StoreList) DeepCopyInto(out *StoreList) {
+func (in *ClusterFGAConnectionList) DeepCopyInto(out *ClusterFGAConnectionList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Store, len(*in))
+		*out = make([]ClusterFGAConnection, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreList.
-func (in *StoreList) DeepCopy() *StoreList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterFGAConnectionList.
+func (in *ClusterFGAConnectionList) DeepCopy() *ClusterFGAConnectionList {
 	if in == nil {
 		return nil
 	}
-	out := new(StoreList)
+	out := new(ClusterFGAConnectionList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *StoreList) DeepCopyObject() runtime.Object {
+func (in *ClusterFGAConnectionList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -362,26 +640,594 @@ func (in *StoreList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StoreSpec) DeepCopyInto(out *StoreSpec) {
+func (in *ConnectionReference) DeepCopyInto(out *ConnectionReference) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreSpec.
-func (in *StoreSpec) DeepCopy() *StoreSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionReference.
+func (in *ConnectionReference) DeepCopy() *ConnectionReference {
 	if in == nil {
 		return nil
 	}
-	out := new(StoreSpec)
+	out := new(ConnectionReference)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StoreStatus) DeepCopyInto(out *StoreStatus) {
+func (in *ConnectionTLS) DeepCopyInto(out *ConnectionTLS) {
 	*out = *in
-	if in.CreatedAt != nil {
-		in, out := &in.CreatedAt, &out.CreatedAt
-		*out = (*in).DeepCopy()
+	if in.CaSecretRef != nil {
+		in, out := &in.CaSecretRef, &out.CaSecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.ClientCertificateSecretRef != nil {
+		in, out := &in.ClientCertificateSecretRef, &out.ClientCertificateSecretRef
+		*out = new(SecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionTLS.
+func (in *ConnectionTLS) DeepCopy() *ConnectionTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FGAConnection) DeepCopyInto(out *FGAConnection) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FGAConnection.
+func (in *FGAConnection) DeepCopy() *FGAConnection {
+	if in == nil {
+		return nil
+	}
+	out := new(FGAConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FGAConnection) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FGAConnectionList) DeepCopyInto(out *FGAConnectionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FGAConnection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FGAConnectionList.
+func (in *FGAConnectionList) DeepCopy() *FGAConnectionList {
+	if in == nil {
+		return nil
+	}
+	out := new(FGAConnectionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FGAConnectionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FGAConnectionSpec) DeepCopyInto(out *FGAConnectionSpec) {
+	*out = *in
+	if in.ApiTokenSecretRef != nil {
+		in, out := &in.ApiTokenSecretRef, &out.ApiTokenSecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.ClientCredentials != nil {
+		in, out := &in.ClientCredentials, &out.ClientCredentials
+		*out = new(ClientCredentials)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ConnectionTLS)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FGAConnectionSpec.
+func (in *FGAConnectionSpec) DeepCopy() *FGAConnectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FGAConnectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailedVersion) DeepCopyInto(out *FailedVersion) {
+	*out = *in
+	in.FailedAt.DeepCopyInto(&out.FailedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailedVersion.
+func (in *FailedVersion) DeepCopy() *FailedVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(FailedVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListObjectsAssertion) DeepCopyInto(out *ListObjectsAssertion) {
+	*out = *in
+	if in.Assertions != nil {
+		in, out := &in.Assertions, &out.Assertions
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListObjectsAssertion.
+func (in *ListObjectsAssertion) DeepCopy() *ListObjectsAssertion {
+	if in == nil {
+		return nil
+	}
+	out := new(ListObjectsAssertion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelChannel) DeepCopyInto(out *ModelChannel) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelChannel.
+func (in *ModelChannel) DeepCopy() *ModelChannel {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelChannel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelVersion) DeepCopyInto(out *ModelVersion) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelVersion.
+func (in *ModelVersion) DeepCopy() *ModelVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModularAuthorizationModel) DeepCopyInto(out *ModularAuthorizationModel) {
+	*out = *in
+	if in.ManifestFrom != nil {
+		in, out := &in.ManifestFrom, &out.ManifestFrom
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Modules != nil {
+		in, out := &in.Modules, &out.Modules
+		*out = make([]ModuleFile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModularAuthorizationModel.
+func (in *ModularAuthorizationModel) DeepCopy() *ModularAuthorizationModel {
+	if in == nil {
+		return nil
+	}
+	out := new(ModularAuthorizationModel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModuleFile) DeepCopyInto(out *ModuleFile) {
+	*out = *in
+	if in.ContentsFrom != nil {
+		in, out := &in.ContentsFrom, &out.ContentsFrom
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleFile.
+func (in *ModuleFile) DeepCopy() *ModuleFile {
+	if in == nil {
+		return nil
+	}
+	out := new(ModuleFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RelationshipTuple) DeepCopyInto(out *RelationshipTuple) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RelationshipTuple.
+func (in *RelationshipTuple) DeepCopy() *RelationshipTuple {
+	if in == nil {
+		return nil
+	}
+	out := new(RelationshipTuple)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RelationshipTupleError) DeepCopyInto(out *RelationshipTupleError) {
+	*out = *in
+	out.RelationshipTuple = in.RelationshipTuple
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RelationshipTupleError.
+func (in *RelationshipTupleError) DeepCopy() *RelationshipTupleError {
+	if in == nil {
+		return nil
+	}
+	out := new(RelationshipTupleError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RelationshipTuples) DeepCopyInto(out *RelationshipTuples) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RelationshipTuples.
+func (in *RelationshipTuples) DeepCopy() *RelationshipTuples {
+	if in == nil {
+		return nil
+	}
+	out := new(RelationshipTuples)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RelationshipTuples) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RelationshipTuplesList) DeepCopyInto(out *RelationshipTuplesList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RelationshipTuples, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RelationshipTuplesList.
+func (in *RelationshipTuplesList) DeepCopy() *RelationshipTuplesList {
+	if in == nil {
+		return nil
+	}
+	out := new(RelationshipTuplesList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RelationshipTuplesList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RelationshipTuplesSpec) DeepCopyInto(out *RelationshipTuplesSpec) {
+	*out = *in
+	out.StoreRef = in.StoreRef
+	if in.Tuples != nil {
+		in, out := &in.Tuples, &out.Tuples
+		*out = make([]RelationshipTuple, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RelationshipTuplesSpec.
+func (in *RelationshipTuplesSpec) DeepCopy() *RelationshipTuplesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RelationshipTuplesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RelationshipTuplesStatus) DeepCopyInto(out *RelationshipTuplesStatus) {
+	*out = *in
+	if in.LastApplied != nil {
+		in, out := &in.LastApplied, &out.LastApplied
+		*out = (*in).DeepCopy()
+	}
+	if in.ManagedTuples != nil {
+		in, out := &in.ManagedTuples, &out.ManagedTuples
+		*out = make([]RelationshipTuple, len(*in))
+		copy(*out, *in)
+	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]RelationshipTupleError, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RelationshipTuplesStatus.
+func (in *RelationshipTuplesStatus) DeepCopy() *RelationshipTuplesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RelationshipTuplesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
+	*out = *in
+	if in.CurrentBatch != nil {
+		in, out := &in.CurrentBatch, &out.CurrentBatch
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastBatchTime != nil {
+		in, out := &in.LastBatchTime, &out.LastBatchTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
+	*out = *in
+	if in.PauseBetweenBatches != nil {
+		in, out := &in.PauseBetweenBatches, &out.PauseBetweenBatches
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ProgressDeadline != nil {
+		in, out := &in.ProgressDeadline, &out.ProgressDeadline
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategy.
+func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretReference) DeepCopyInto(out *SecretReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretReference.
+func (in *SecretReference) DeepCopy() *SecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Store) DeepCopyInto(out *Store) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Store.
+func (in *Store) DeepCopy() *Store {
+	if in == nil {
+		return nil
+	}
+	out := new(Store)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Store) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreList) DeepCopyInto(out *StoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Store, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreList.
+func (in *StoreList) DeepCopy() *StoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(StoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreReference) DeepCopyInto(out *StoreReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreReference.
+func (in *StoreReference) DeepCopy() *StoreReference {
+	if in == nil {
+		return nil
+	}
+	out := new(StoreReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreSpec) DeepCopyInto(out *StoreSpec) {
+	*out = *in
+	if in.ConnectionRef != nil {
+		in, out := &in.ConnectionRef, &out.ConnectionRef
+		*out = new(ConnectionReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreSpec.
+func (in *StoreSpec) DeepCopy() *StoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoreStatus) DeepCopyInto(out *StoreStatus) {
+	*out = *in
+	if in.CreatedAt != nil {
+		in, out := &in.CreatedAt, &out.CreatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastVerified != nil {
+		in, out := &in.LastVerified, &out.LastVerified
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -21,8 +21,12 @@ import (
 	"fga-operator/internal/configurations"
 	"fga-operator/internal/controller/authorizationmodel"
 	"fga-operator/internal/controller/authorizationmodelrequest"
+	"fga-operator/internal/controller/relationshiptuples"
+	"fga-operator/internal/controller/store"
 	"fga-operator/internal/observability"
 	"fga-operator/internal/openfga"
+	requestwebhook "fga-operator/internal/webhook/authorizationmodelrequest"
+	podwebhook "fga-operator/internal/webhook/pod"
 	"flag"
 	"os"
 
@@ -61,6 +65,7 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var enableWebhooks bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -70,6 +75,10 @@ func main() {
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"If set, the admission webhooks are served. Requires a serving certificate for the webhook server.")
+	var openFgaFlags openfga.Flags
+	openFgaFlags.BindFlags(flag.CommandLine)
 	opts := zap.Options{
 		Development: true,
 	}
@@ -125,18 +134,20 @@ func main() {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
-	config, err := openfga.NewConfig()
+	config, err := openfga.NewConfig(openFgaFlags)
 	if err != nil {
 		setupLog.Error(err, "unable to create config")
 		os.Exit(1)
 	}
+	permissionServiceFactory := openfga.NewOpenFgaServiceFactory(ctrl.Log.WithName("openfga"))
 
 	if err = (&authorizationmodelrequest.AuthorizationModelRequestReconciler{
 		Client:                   mgr.GetClient(),
 		Scheme:                   mgr.GetScheme(),
 		Recorder:                 mgr.GetEventRecorderFor(authorizationmodelrequest.EventRecorderLabel),
-		PermissionServiceFactory: openfga.OpenFgaServiceFactory{},
+		PermissionServiceFactory: permissionServiceFactory,
 		Config:                   config,
+		APIReader:                mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AuthorizationModelRequest")
 		os.Exit(1)
@@ -148,10 +159,52 @@ func main() {
 		Scheme:                 mgr.GetScheme(),
 		Recorder:               mgr.GetEventRecorderFor(authorizationmodel.EventRecorderLabel),
 		ReconciliationInterval: &reconciliationInterval,
+		APIReader:              mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AuthorizationModel")
 		os.Exit(1)
 	}
+
+	storeVerificationInterval := configurations.GetStoreVerificationInterval(setupLog)
+	if err = (&store.StoreReconciler{
+		Client:                   mgr.GetClient(),
+		Scheme:                   mgr.GetScheme(),
+		Recorder:                 mgr.GetEventRecorderFor(store.EventRecorderLabel),
+		PermissionServiceFactory: permissionServiceFactory,
+		Config:                   config,
+		VerificationInterval:     &storeVerificationInterval,
+		APIReader:                mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Store")
+		os.Exit(1)
+	}
+	tuplesResyncInterval := configurations.GetTuplesResyncInterval(setupLog)
+	if err = (&relationshiptuples.RelationshipTuplesReconciler{
+		Client:                   mgr.GetClient(),
+		Scheme:                   mgr.GetScheme(),
+		Recorder:                 mgr.GetEventRecorderFor(relationshiptuples.EventRecorderLabel),
+		PermissionServiceFactory: permissionServiceFactory,
+		Config:                   config,
+		ResyncInterval:           &tuplesResyncInterval,
+		APIReader:                mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RelationshipTuples")
+		os.Exit(1)
+	}
+	if enableWebhooks {
+		if err = (&podwebhook.EnvInjector{
+			Client: mgr.GetClient(),
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Pod")
+			os.Exit(1)
+		}
+		if err = (&requestwebhook.Validator{
+			Client: mgr.GetClient(),
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "AuthorizationModelRequest")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
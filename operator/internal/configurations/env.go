@@ -10,20 +10,41 @@ import (
 const ReconciliationInterval = "RECONCILIATION_INTERVAL"
 const DefaultReconciliationInterval = 10 * time.Second
 
+const StoreVerificationInterval = "STORE_VERIFICATION_INTERVAL"
+const DefaultStoreVerificationInterval = time.Minute
+
+const TuplesResyncInterval = "TUPLES_RESYNC_INTERVAL"
+const DefaultTuplesResyncInterval = 5 * time.Minute
+
 func GetReconciliationInterval(setupLog logr.Logger) time.Duration {
-	reconciliationInterval := os.Getenv(ReconciliationInterval)
+	return getDuration(setupLog, ReconciliationInterval, DefaultReconciliationInterval)
+}
+
+// GetStoreVerificationInterval returns the interval between verifications of stores in OpenFGA.
+func GetStoreVerificationInterval(setupLog logr.Logger) time.Duration {
+	return getDuration(setupLog, StoreVerificationInterval, DefaultStoreVerificationInterval)
+}
+
+// GetTuplesResyncInterval returns the interval at which relationship tuples are applied again, such that tuples
+// deleted in OpenFGA by others are restored.
+func GetTuplesResyncInterval(setupLog logr.Logger) time.Duration {
+	return getDuration(setupLog, TuplesResyncInterval, DefaultTuplesResyncInterval)
+}
+
+func getDuration(setupLog logr.Logger, name string, defaultDuration time.Duration) time.Duration {
+	value := os.Getenv(name)
 
-	if reconciliationInterval == "" {
-		setupLog.Info(fmt.Sprintf("%s not set, using default", ReconciliationInterval), "defaultDuration", DefaultReconciliationInterval)
-		return DefaultReconciliationInterval
+	if value == "" {
+		setupLog.Info(fmt.Sprintf("%s not set, using default", name), "defaultDuration", defaultDuration)
+		return defaultDuration
 	}
 
-	requeueAfter, err := time.ParseDuration(reconciliationInterval)
+	duration, err := time.ParseDuration(value)
 	if err != nil {
-		setupLog.Error(err, fmt.Sprintf("Invalid %s value, using default", ReconciliationInterval), "reconciliationInterval", reconciliationInterval, "defaultDuration", DefaultReconciliationInterval)
-		return DefaultReconciliationInterval
+		setupLog.Error(err, fmt.Sprintf("Invalid %s value, using default", name), "value", value, "defaultDuration", defaultDuration)
+		return defaultDuration
 	}
 
-	setupLog.Info(fmt.Sprintf("Using %s from environment", ReconciliationInterval), "requeueAfter", requeueAfter)
-	return requeueAfter
+	setupLog.Info(fmt.Sprintf("Using %s from environment", name), "duration", duration)
+	return duration
 }
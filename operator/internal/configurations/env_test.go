@@ -67,3 +67,31 @@ func TestGetRequeueAfterFromEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestGetStoreVerificationIntervalFromEnv(t *testing.T) {
+	testCases := []struct {
+		envValue       string
+		expectedResult time.Duration
+		description    string
+	}{
+		{"", DefaultStoreVerificationInterval, fmt.Sprintf("%s not set, expect default value", StoreVerificationInterval)},
+		{"5m", 5 * time.Minute, fmt.Sprintf("%s set to 5 minutes", StoreVerificationInterval)},
+		{"invalid-value", DefaultStoreVerificationInterval, fmt.Sprintf("%s set to invalid value, expect default value", StoreVerificationInterval)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			t.Setenv(StoreVerificationInterval, testCase.envValue)
+			logger := newTestLogger()
+
+			// Act
+			duration := GetStoreVerificationInterval(logger)
+
+			// Assert
+			if duration != testCase.expectedResult {
+				t.Errorf("expected %v, got %v", testCase.expectedResult, duration)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package connections
+
+import (
+	"context"
+	extensionsv1 "fga-operator/api/v1"
+	"fga-operator/internal/openfga"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Resolve returns the configuration of the referenced FGAConnection or ClusterFGAConnection, or the
+// default configuration of the operator when no connection is referenced. The namespace is the one of
+// the referencing resource. The reader should read from the API server, such that secrets aren't cached.
+// The request configuration of the default configuration, e.g. retries and rate limit, applies to all
+// connections.
+func Resolve(
+	ctx context.Context,
+	reader client.Reader,
+	defaultConfig openfga.Config,
+	namespace string,
+	ref *extensionsv1.ConnectionReference) (openfga.Config, error) {
+	if ref == nil {
+		return defaultConfig, nil
+	}
+
+	var spec extensionsv1.FGAConnectionSpec
+	var connection string
+	if ref.IsCluster() {
+		clusterConnection := &extensionsv1.ClusterFGAConnection{}
+		if err := reader.Get(ctx, types.NamespacedName{Name: ref.Name}, clusterConnection); err != nil {
+			return openfga.Config{}, notFound(err, ref.String())
+		}
+		spec = clusterConnection.Spec
+		connection = ref.String()
+		namespace = ""
+	} else {
+		namespacedConnection := &extensionsv1.FGAConnection{}
+		if err := reader.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, namespacedConnection); err != nil {
+			return openfga.Config{}, notFound(err, ref.String())
+		}
+		spec = namespacedConnection.Spec
+		connection = fmt.Sprintf("%s/%s/%s", extensionsv1.ConnectionKindNamespaced, namespace, ref.Name)
+	}
+
+	secrets := secretReader{reader: reader, namespace: namespace}
+	config, err := newConfig(ctx, secrets, spec)
+	if err != nil {
+		return openfga.Config{}, fmt.Errorf("connection %s: %w", ref.String(), err)
+	}
+	config.Connection = connection
+	config.Requests = defaultConfig.Requests
+	if err := config.Validate(); err != nil {
+		return openfga.Config{}, fmt.Errorf("invalid connection %s: %w", ref.String(), err)
+	}
+	return config, nil
+}
+
+func newConfig(ctx context.Context, secrets secretReader, spec extensionsv1.FGAConnectionSpec) (openfga.Config, error) {
+	config := openfga.Config{
+		ApiUrl:   spec.ApiUrl,
+		AuthMode: openfga.AuthMode(spec.AuthMode),
+	}
+	if spec.ApiTokenSecretRef != nil {
+		apiToken, err := secrets.readKey(ctx, spec.ApiTokenSecretRef.Namespace, spec.ApiTokenSecretRef.Name, spec.ApiTokenSecretRef.Key)
+		if err != nil {
+			return openfga.Config{}, err
+		}
+		config.ApiToken = apiToken
+	}
+	if clientCredentials := spec.ClientCredentials; clientCredentials != nil {
+		secretRef := clientCredentials.ClientSecretRef
+		clientSecret, err := secrets.readKey(ctx, secretRef.Namespace, secretRef.Name, secretRef.Key)
+		if err != nil {
+			return openfga.Config{}, err
+		}
+		config.ClientId = clientCredentials.ClientId
+		config.ClientSecret = clientSecret
+		config.ApiTokenIssuer = clientCredentials.ApiTokenIssuer
+		config.ApiAudience = clientCredentials.ApiAudience
+		config.ApiScopes = clientCredentials.ApiScopes
+	}
+	if spec.TLS == nil {
+		return config, nil
+	}
+	config.InsecureSkipVerify = spec.TLS.InsecureSkipVerify
+	if caRef := spec.TLS.CaSecretRef; caRef != nil {
+		ca, err := secrets.readKey(ctx, caRef.Namespace, caRef.Name, caRef.Key)
+		if err != nil {
+			return openfga.Config{}, err
+		}
+		config.Ca = ca
+	}
+	if certificateRef := spec.TLS.ClientCertificateSecretRef; certificateRef != nil {
+		clientCert, err := secrets.readKey(ctx, certificateRef.Namespace, certificateRef.Name, corev1.TLSCertKey)
+		if err != nil {
+			return openfga.Config{}, err
+		}
+		clientKey, err := secrets.readKey(ctx, certificateRef.Namespace, certificateRef.Name, corev1.TLSPrivateKeyKey)
+		if err != nil {
+			return openfga.Config{}, err
+		}
+		config.ClientCert = clientCert
+		config.ClientKey = clientKey
+	}
+	return config, nil
+}
+
+// secretReader reads the secrets of a connection. The secrets of an FGAConnection are in its namespace,
+// such that it can't be used to read secrets of other namespaces, while the secrets of a
+// ClusterFGAConnection must set their namespace.
+type secretReader struct {
+	reader client.Reader
+	// namespace of the FGAConnection, or empty for a ClusterFGAConnection.
+	namespace string
+}
+
+func (s secretReader) readKey(ctx context.Context, namespace, name, key string) (string, error) {
+	switch {
+	case s.namespace == "" && namespace == "":
+		return "", fmt.Errorf("namespace of secret %s is required for a %s", name, extensionsv1.ConnectionKindCluster)
+	case s.namespace == "":
+	case namespace != "" && namespace != s.namespace:
+		return "", fmt.Errorf("secret %s must be in the namespace %s of the %s", name, s.namespace, extensionsv1.ConnectionKindNamespaced)
+	default:
+		namespace = s.namespace
+	}
+
+	secret := &corev1.Secret{}
+	if err := s.reader.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		return "", notFound(err, fmt.Sprintf("secret %s/%s", namespace, name))
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s/%s", key, namespace, name)
+	}
+	return string(value), nil
+}
+
+func notFound(err error, name string) error {
+	if errors.IsNotFound(err) {
+		return fmt.Errorf("%s not found", name)
+	}
+	return err
+}
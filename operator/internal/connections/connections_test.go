@@ -0,0 +1,259 @@
+package connections
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	extensionsv1 "fga-operator/api/v1"
+	"fga-operator/internal/openfga"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"math/big"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, objects ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := extensionsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
+
+func newSecret(namespace, name string, data map[string]string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data:       map[string][]byte{},
+	}
+	for key, value := range data {
+		secret.Data[key] = []byte(value)
+	}
+	return secret
+}
+
+func TestResolve(t *testing.T) {
+	// Arrange
+	certificate, key := newCertificate(t)
+	requests := openfga.RequestConfig{PageSize: 20, StoreIndexTTL: time.Minute, MaxRetries: 3}
+	defaultConfig := openfga.Config{ApiUrl: "http://openfga:8080", ApiToken: "default", Requests: requests}
+	namespacedConnection := &extensionsv1.FGAConnection{
+		ObjectMeta: metav1.ObjectMeta{Name: "eu-west-1", Namespace: "default"},
+		Spec: extensionsv1.FGAConnectionSpec{
+			ApiUrl:            "https://openfga.eu-west-1.example.com",
+			ApiTokenSecretRef: &extensionsv1.SecretKeyReference{Name: "openfga", Key: "api-token"},
+		},
+	}
+	clusterConnection := &extensionsv1.ClusterFGAConnection{
+		ObjectMeta: metav1.ObjectMeta{Name: "compliance"},
+		Spec: extensionsv1.FGAConnectionSpec{
+			ApiUrl:   "https://openfga.compliance.example.com",
+			AuthMode: extensionsv1.ConnectionAuthModeClientCredentials,
+			ClientCredentials: &extensionsv1.ClientCredentials{
+				ClientId:        "fga-operator",
+				ClientSecretRef: extensionsv1.SecretKeyReference{Name: "openfga", Namespace: "fga-operator", Key: "client-secret"},
+				ApiTokenIssuer:  "auth.example.com",
+				ApiAudience:     "https://openfga.compliance.example.com/",
+			},
+			TLS: &extensionsv1.ConnectionTLS{
+				CaSecretRef:                &extensionsv1.SecretKeyReference{Name: "openfga-tls", Namespace: "fga-operator", Key: "ca.crt"},
+				ClientCertificateSecretRef: &extensionsv1.SecretReference{Name: "openfga-tls", Namespace: "fga-operator"},
+			},
+		},
+	}
+	k8sClient := newTestClient(t,
+		namespacedConnection,
+		clusterConnection,
+		newSecret("default", "openfga", map[string]string{"api-token": "eu-west-1"}),
+		newSecret("fga-operator", "openfga", map[string]string{"client-secret": "secret"}),
+		newSecret("fga-operator", "openfga-tls", map[string]string{"ca.crt": certificate, "tls.crt": certificate, "tls.key": key}),
+	)
+
+	testCases := []struct {
+		description string
+		ref         *extensionsv1.ConnectionReference
+		expected    openfga.Config
+	}{
+		{
+			description: "no connection",
+			expected:    defaultConfig,
+		},
+		{
+			description: "namespaced connection",
+			ref:         &extensionsv1.ConnectionReference{Name: "eu-west-1"},
+			expected: openfga.Config{
+				Connection: "FGAConnection/default/eu-west-1",
+				ApiUrl:     "https://openfga.eu-west-1.example.com",
+				ApiToken:   "eu-west-1",
+				Requests:   requests,
+			},
+		},
+		{
+			description: "cluster connection",
+			ref:         &extensionsv1.ConnectionReference{Kind: extensionsv1.ConnectionKindCluster, Name: "compliance"},
+			expected: openfga.Config{
+				Connection:     "ClusterFGAConnection/compliance",
+				ApiUrl:         "https://openfga.compliance.example.com",
+				AuthMode:       openfga.AuthModeClientCredentials,
+				ClientId:       "fga-operator",
+				ClientSecret:   "secret",
+				ApiTokenIssuer: "auth.example.com",
+				ApiAudience:    "https://openfga.compliance.example.com/",
+				Ca:             certificate,
+				ClientCert:     certificate,
+				ClientKey:      key,
+				Requests:       requests,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Act
+			config, err := Resolve(context.Background(), k8sClient, defaultConfig, "default", testCase.ref)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(testCase.expected, config); diff != "" {
+				t.Errorf("unexpected config (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	testCases := []struct {
+		description string
+		spec        extensionsv1.FGAConnectionSpec
+		cluster     bool
+		expected    string
+	}{
+		{
+			description: "missing secret",
+			spec: extensionsv1.FGAConnectionSpec{
+				ApiUrl:            "https://openfga.example.com",
+				ApiTokenSecretRef: &extensionsv1.SecretKeyReference{Name: "missing", Key: "api-token"},
+			},
+			expected: "connection FGAConnection/connection: secret default/missing not found",
+		},
+		{
+			description: "missing key",
+			spec: extensionsv1.FGAConnectionSpec{
+				ApiUrl:            "https://openfga.example.com",
+				ApiTokenSecretRef: &extensionsv1.SecretKeyReference{Name: "openfga", Key: "token"},
+			},
+			expected: "connection FGAConnection/connection: key token not found in secret default/openfga",
+		},
+		{
+			description: "secret in other namespace",
+			spec: extensionsv1.FGAConnectionSpec{
+				ApiUrl:            "https://openfga.example.com",
+				ApiTokenSecretRef: &extensionsv1.SecretKeyReference{Name: "openfga", Namespace: "fga-operator", Key: "api-token"},
+			},
+			expected: "connection FGAConnection/connection: secret openfga must be in the namespace default of the FGAConnection",
+		},
+		{
+			description: "secret of cluster connection without namespace",
+			spec: extensionsv1.FGAConnectionSpec{
+				ApiUrl:            "https://openfga.example.com",
+				ApiTokenSecretRef: &extensionsv1.SecretKeyReference{Name: "openfga", Key: "api-token"},
+			},
+			cluster:  true,
+			expected: "connection ClusterFGAConnection/connection: namespace of secret openfga is required for a ClusterFGAConnection",
+		},
+		{
+			description: "invalid configuration",
+			spec: extensionsv1.FGAConnectionSpec{
+				ApiUrl:   "https://openfga.example.com",
+				AuthMode: extensionsv1.ConnectionAuthModeApiToken,
+			},
+			expected: "invalid connection FGAConnection/connection: environment variable OPENFGA_API_TOKEN not found",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			ref := &extensionsv1.ConnectionReference{Name: "connection"}
+			var connection client.Object = &extensionsv1.FGAConnection{
+				ObjectMeta: metav1.ObjectMeta{Name: "connection", Namespace: "default"},
+				Spec:       testCase.spec,
+			}
+			if testCase.cluster {
+				ref.Kind = extensionsv1.ConnectionKindCluster
+				connection = &extensionsv1.ClusterFGAConnection{
+					ObjectMeta: metav1.ObjectMeta{Name: "connection"},
+					Spec:       testCase.spec,
+				}
+			}
+			k8sClient := newTestClient(t,
+				connection,
+				newSecret("default", "openfga", map[string]string{"api-token": "token"}),
+				newSecret("fga-operator", "openfga", map[string]string{"api-token": "token"}),
+			)
+
+			// Act
+			_, err := Resolve(context.Background(), k8sClient, openfga.Config{}, "default", ref)
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), testCase.expected) {
+				t.Errorf("expected error containing %q, got %v", testCase.expected, err)
+			}
+		})
+	}
+}
+
+func TestResolveMissingConnection(t *testing.T) {
+	// Arrange
+	k8sClient := newTestClient(t)
+
+	// Act
+	_, err := Resolve(context.Background(), k8sClient, openfga.Config{}, "default", &extensionsv1.ConnectionReference{Name: "missing"})
+
+	// Assert
+	if err == nil || err.Error() != "FGAConnection/missing not found" {
+		t.Errorf("expected connection not found, got %v", err)
+	}
+}
+
+// newCertificate returns a self-signed certificate and its key in PEM.
+func newCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fga-operator"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
@@ -18,18 +18,27 @@ package authorizationmodel
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	extensionsv1 "fga-operator/api/v1"
 	"fga-operator/internal/observability"
+	"fmt"
 	"github.com/go-logr/logr"
 	appsV1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -42,6 +51,9 @@ type AuthorizationModelReconciler struct {
 	Recorder record.EventRecorder
 	Clock
 	ReconciliationInterval *time.Duration
+	// APIReader reads the delivery config maps directly from the API server, such that config maps
+	// aren't cached. Defaults to the client.
+	APIReader client.Reader
 }
 
 type Clock interface {
@@ -50,7 +62,7 @@ type Clock interface {
 
 const (
 	EventRecorderLabel = "AuthorizationModelReconciler"
-	deploymentIndexKey = ".metadata.labels." + extensionsv1.OpenFgaStoreLabel
+	workloadIndexKey   = ".metadata.labels." + extensionsv1.OpenFgaStoreLabel
 )
 
 type EventReason string
@@ -60,12 +72,32 @@ const (
 	EventReasonAuthorizationModelIdUpdateFailed EventReason = "AuthorizationModelIdUpdateFailed"
 	EventReasonFailedListingDeployments         EventReason = "FailedListingDeployments"
 	EventReasonFailedUpdatingDeployment         EventReason = "FailedUpdatingDeployment"
+	EventReasonFailedListingStatefulSets        EventReason = "FailedListingStatefulSets"
+	EventReasonFailedUpdatingStatefulSet        EventReason = "FailedUpdatingStatefulSet"
+	EventReasonFailedListingDaemonSets          EventReason = "FailedListingDaemonSets"
+	EventReasonFailedUpdatingDaemonSet          EventReason = "FailedUpdatingDaemonSet"
+	EventReasonFailedListingJobs                EventReason = "FailedListingJobs"
+	EventReasonFailedUpdatingJob                EventReason = "FailedUpdatingJob"
+	EventReasonFailedListingCronJobs            EventReason = "FailedListingCronJobs"
+	EventReasonFailedUpdatingCronJob            EventReason = "FailedUpdatingCronJob"
+	EventReasonJobTemplateImmutable             EventReason = "JobTemplateImmutable"
+	EventReasonStatusUpdateFailed               EventReason = "StatusUpdateFailed"
+	EventReasonRolloutBatchStarted              EventReason = "RolloutBatchStarted"
+	EventReasonRolloutHalted                    EventReason = "RolloutHalted"
+	EventReasonRolloutCompleted                 EventReason = "RolloutCompleted"
+	EventReasonWorkloadRolledBack               EventReason = "WorkloadRolledBack"
+	EventReasonFailedFetchingConfigMap          EventReason = "FailedFetchingConfigMap"
+	EventReasonFailedUpdatingConfigMap          EventReason = "FailedUpdatingConfigMap"
 )
 
 //+kubebuilder:rbac:groups=extensions.fga-operator,resources=authorizationmodels,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=extensions.fga-operator,resources=authorizationmodels/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=extensions.fga-operator,resources=authorizationmodels/finalizers,verbs=update
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=list;watch;update
+//+kubebuilder:rbac:groups=batch,resources=jobs;cronjobs,verbs=list;watch;update
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=patch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -84,6 +116,7 @@ func (r *AuthorizationModelReconciler) Reconcile(ctx context.Context, req ctrl.R
 		logger.Error(err, "unable to fetch authorization model", "authorizationModelName", req.Name)
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	previousStatus := authorizationModel.Status.DeepCopy()
 
 	store := &extensionsv1.Store{}
 	if err := r.Get(ctx, req.NamespacedName, store); err != nil {
@@ -97,40 +130,171 @@ func (r *AuthorizationModelReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	var deployments appsV1.DeploymentList
-	if err := r.List(ctx, &deployments, client.InNamespace(req.Namespace), client.MatchingFields{deploymentIndexKey: store.Name}); err != nil {
-		r.Recorder.Event(
-			authorizationModel,
-			v1.EventTypeWarning,
-			string(EventReasonFailedListingDeployments),
-			err.Error(),
-		)
-		logger.Error(err, "unable to list deployments")
+	workloads, err := r.listWorkloads(ctx, authorizationModel, req.Namespace, store.Name, &logger)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	updates := updateStoreIdOnDeployments(deployments, store, reconcileTimestamp)
+	bound := newBoundWorkloads(workloads)
 
-	updateFailures := updateAuthorizationModelIdOnDeployment(deployments, updates, authorizationModel, reconcileTimestamp, &logger)
+	rollbacks := rollbackCheck{}
+	if authorizationModel.Spec.AutomaticRollback != nil {
+		rollbacks = checkRollbacks(workloads, authorizationModel.Spec.AutomaticRollback, reconcileTimestamp)
+		r.recordRollbackEvents(authorizationModel, rollbacks.rolledBack)
+		if rollbacks.watching && rollbacks.requeueAfter < requeueResult.RequeueAfter {
+			requeueResult.RequeueAfter = rollbacks.requeueAfter
+		}
+	}
+	setFailedVersions(authorizationModel, rollbacks.rolledBack)
+
+	updates := updateStoreIdOnWorkloads(workloads, store, reconcileTimestamp)
+	updateDeliveryOnWorkloads(workloads, updates)
+
+	updateFailures := updateAuthorizationModelIdOnWorkloads(workloads, updates, authorizationModel.WithoutFailedVersions(), reconcileTimestamp, &logger)
 	for _, updateError := range updateFailures {
 		r.createAuthorizationModelEvent(authorizationModel, EventReasonAuthorizationModelIdUpdateFailed, updateError.err)
 		r.Recorder.Event(
-			&updateError.deployment,
+			updateError.workload.Object,
 			v1.EventTypeWarning,
 			string(EventReasonAuthorizationModelIdUpdateFailed),
 			updateError.err.Error(),
 		)
+		bound.failed(updateError.workload, updateError.err)
+	}
+	removeStaleEnvVars(workloads, updates)
+
+	if authorizationModel.Spec.RolloutStrategy == nil {
+		authorizationModel.Status.Rollout = nil
+	} else {
+		rollout := planRollout(authorizationModel, workloads, updates, reconcileTimestamp)
+		r.recordRolloutEvents(authorizationModel, rollout)
+		updates = rollout.batch
+		authorizationModel.Status.Rollout = rollout.status
+		if rollout.requeueAfter > 0 && rollout.requeueAfter < requeueResult.RequeueAfter {
+			requeueResult.RequeueAfter = rollout.requeueAfter
+		}
+	}
+	if authorizationModel.Spec.AutomaticRollback != nil {
+		rollbacks.recordRollbackAnnotations(updates, bound)
+	}
+	// Rollbacks don't wait for the rollout.
+	for identifier, workload := range rollbacks.updates {
+		updates[identifier] = workload
 	}
 
-	for _, deployment := range updates {
-		if err := r.updateDeployment(ctx, &deployment, req.Name, &logger); err != nil {
-			r.createAuthorizationModelEvent(authorizationModel, EventReasonFailedUpdatingDeployment, err)
+	anyUpdated := false
+	for _, workload := range updates {
+		updated, err := r.updateWorkload(ctx, workload, req.Name, &logger)
+		if err != nil {
+			if kind, ok := getWorkloadKind(workload.Kind); ok {
+				r.createAuthorizationModelEvent(authorizationModel, kind.eventReasonUpdateFailed, err)
+			}
+			bound.failed(workload, err)
+			continue
+		}
+		if updated {
+			bound.updated(workload)
+			anyUpdated = true
 		}
 	}
 
+	if err := r.updateStatus(ctx, authorizationModel, previousStatus, bound, anyUpdated, reconcileTimestamp, &logger); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	return requeueResult, nil
 }
 
+func (r *AuthorizationModelReconciler) updateStatus(
+	ctx context.Context,
+	authorizationModel *extensionsv1.AuthorizationModel,
+	previous *extensionsv1.AuthorizationModelStatus,
+	bound boundWorkloads,
+	anyUpdated bool,
+	reconcileTimestamp time.Time,
+	log *logr.Logger,
+) error {
+	setWorkloadsStatus(authorizationModel, bound, anyUpdated, reconcileTimestamp)
+	if equality.Semantic.DeepEqual(previous, &authorizationModel.Status) {
+		return nil
+	}
+	if err := r.Status().Update(ctx, authorizationModel); err != nil {
+		r.createAuthorizationModelEvent(authorizationModel, EventReasonStatusUpdateFailed, err)
+		log.Error(err, "unable to update authorization model status", "authorizationModelName", authorizationModel.Name)
+		return err
+	}
+	return nil
+}
+
+func (r *AuthorizationModelReconciler) listWorkloads(
+	ctx context.Context,
+	authorizationModel *extensionsv1.AuthorizationModel,
+	namespace string,
+	storeName string,
+	log *logr.Logger,
+) ([]Workload, error) {
+	workloads := make([]Workload, 0)
+	for _, kind := range workloadKinds {
+		list := kind.newList()
+		if err := r.List(ctx, list, client.InNamespace(namespace), client.MatchingFields{workloadIndexKey: storeName}); err != nil {
+			r.createAuthorizationModelEvent(authorizationModel, kind.eventReasonListFailed, err)
+			log.Error(err, "unable to list workloads", "kind", kind.kind)
+			return nil, err
+		}
+		kindWorkloads, err := workloadsFromList(kind.kind, list)
+		if err != nil {
+			r.createAuthorizationModelEvent(authorizationModel, kind.eventReasonListFailed, err)
+			log.Error(err, "unable to extract workloads", "kind", kind.kind)
+			return nil, err
+		}
+		workloads = append(workloads, kindWorkloads...)
+	}
+	for i := range workloads {
+		if deliveryMode(workloads[i].Object) == extensionsv1.DeliveryModeEnv {
+			continue
+		}
+		configMap, err := r.getDeliveryConfigMap(ctx, workloads[i])
+		if err != nil {
+			r.createAuthorizationModelEvent(authorizationModel, EventReasonFailedFetchingConfigMap, err)
+			log.Error(err, "unable to fetch delivery config map", "kind", workloads[i].Kind, "workloadName", workloads[i].Object.GetName())
+			return nil, err
+		}
+		workloads[i].ConfigMap = configMap
+	}
+	return workloads, nil
+}
+
+// getDeliveryConfigMap returns the ConfigMap delivering the IDs to the workload, which is new when it
+// doesn't exist yet.
+func (r *AuthorizationModelReconciler) getDeliveryConfigMap(ctx context.Context, workload Workload) (*v1.ConfigMap, error) {
+	configMap := newDeliveryConfigMap(workload)
+	err := r.apiReader().Get(ctx, client.ObjectKeyFromObject(configMap), configMap)
+	if errors.IsNotFound(err) {
+		return newDeliveryConfigMap(workload), nil
+	}
+	return configMap, err
+}
+
+func (r *AuthorizationModelReconciler) apiReader() client.Reader {
+	if r.APIReader == nil {
+		return r.Client
+	}
+	return r.APIReader
+}
+
+// applyDeliveryConfigMap creates or updates the ConfigMap of the workload. New ConfigMaps are owned by
+// the workload, such that they are deleted with it.
+func (r *AuthorizationModelReconciler) applyDeliveryConfigMap(ctx context.Context, workload Workload) error {
+	configMap := workload.ConfigMap
+	if configMap.ResourceVersion != "" {
+		return r.Update(ctx, configMap)
+	}
+	if err := ctrl.SetControllerReference(workload.Object, configMap, r.Scheme); err != nil {
+		return err
+	}
+	return r.Create(ctx, configMap)
+}
+
 func (r *AuthorizationModelReconciler) createAuthorizationModelEvent(
 	authorizationModel *extensionsv1.AuthorizationModel,
 	eventReason EventReason,
@@ -143,26 +307,106 @@ func (r *AuthorizationModelReconciler) createAuthorizationModelEvent(
 	)
 }
 
-func (r *AuthorizationModelReconciler) updateDeployment(
+func (r *AuthorizationModelReconciler) recordRolloutEvents(authorizationModel *extensionsv1.AuthorizationModel, rollout rollout) {
+	status := rollout.status
+	switch {
+	case rollout.halted:
+		r.Recorder.Event(authorizationModel, v1.EventTypeWarning, string(EventReasonRolloutHalted), status.Message)
+	case rollout.started:
+		r.Recorder.Event(authorizationModel, v1.EventTypeNormal, string(EventReasonRolloutBatchStarted),
+			fmt.Sprintf("Batch %d updates %s, %d workloads pending", status.Batches, strings.Join(status.CurrentBatch, ", "), status.PendingWorkloads))
+	case rollout.completed:
+		r.Recorder.Event(authorizationModel, v1.EventTypeNormal, string(EventReasonRolloutCompleted),
+			fmt.Sprintf("Updated %d workloads in %d batches", status.UpdatedWorkloads, status.Batches))
+	}
+}
+
+func (r *AuthorizationModelReconciler) recordRollbackEvents(authorizationModel *extensionsv1.AuthorizationModel, rolledBack []rolledBackWorkload) {
+	for _, rolledBackWorkload := range rolledBack {
+		failedVersion := rolledBackWorkload.failedVersion
+		message := fmt.Sprintf("%s, reverted %s from version %s to version %s", failedVersion.Reason, failedVersion.Workload,
+			failedVersion.Version, rolledBackWorkload.workload.Object.GetAnnotations()[extensionsv1.OpenFgaAuthModelVersionLabel])
+		r.Recorder.Event(authorizationModel, v1.EventTypeWarning, string(EventReasonWorkloadRolledBack), message)
+		r.Recorder.Event(rolledBackWorkload.workload.Object, v1.EventTypeWarning, string(EventReasonWorkloadRolledBack), message)
+	}
+}
+
+// warnImmutableTemplate emits the JobTemplateImmutable event once per outdated pod template. The checksum of the pod
+// template the workload should have is recorded in an annotation, which is patched without the pod template.
+func (r *AuthorizationModelReconciler) warnImmutableTemplate(ctx context.Context, workload Workload) error {
+	template, err := json.Marshal(workload.PodTemplate().Spec)
+	if err != nil {
+		return err
+	}
+	checksum := fmt.Sprintf("%x", sha256.Sum256(template))
+	if workload.Object.GetAnnotations()[extensionsv1.OpenFgaOutdatedTemplateChecksumAnnotation] == checksum {
+		return nil
+	}
+
+	r.Recorder.Event(
+		workload.Object,
+		v1.EventTypeWarning,
+		string(EventReasonJobTemplateImmutable),
+		fmt.Sprintf("%s %s has outdated OpenFGA environment variables, but its pod template can't be updated after creation", workload.Kind, workload.Object.GetName()),
+	)
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{extensionsv1.OpenFgaOutdatedTemplateChecksumAnnotation: checksum},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return r.Patch(ctx, workload.Object, client.RawPatch(types.MergePatchType, patch))
+}
+
+// updateWorkload updates the workload in the cluster and returns false when the update was skipped.
+func (r *AuthorizationModelReconciler) updateWorkload(
 	ctx context.Context,
-	deployment *appsV1.Deployment,
+	workload Workload,
 	modelName string,
 	log *logr.Logger,
 
-) error {
-	if err := r.Update(ctx, deployment); err != nil {
+) (bool, error) {
+	kind, ok := getWorkloadKind(workload.Kind)
+	if !ok {
+		err := fmt.Errorf("unknown workload kind %q", workload.Kind)
+		log.Error(err, "unable to update workload", "kind", workload.Kind, "workloadName", workload.Object.GetName())
+		return false, err
+	}
+	if workload.ConfigMap != nil {
+		if err := r.applyDeliveryConfigMap(ctx, workload); err != nil {
+			r.Recorder.Event(
+				workload.Object,
+				v1.EventTypeWarning,
+				string(EventReasonFailedUpdatingConfigMap),
+				err.Error(),
+			)
+			log.Error(err, "unable to update delivery config map", "kind", workload.Kind, "workloadName", workload.Object.GetName())
+			return false, err
+		}
+	}
+	if kind.immutableTemplate {
+		log.V(0).Info("skipping update of workload with immutable pod template", "kind", workload.Kind, "workloadName", workload.Object.GetName())
+		return false, r.warnImmutableTemplate(ctx, workload)
+	}
+
+	if err := r.Update(ctx, workload.Object); err != nil {
 		r.Recorder.Event(
-			deployment,
+			workload.Object,
 			v1.EventTypeWarning,
-			string(EventReasonFailedUpdatingDeployment),
+			string(kind.eventReasonUpdateFailed),
 			err.Error(),
 		)
-		log.Error(err, "unable to update deployment", "deploymentName", deployment.Name)
-		return err
+		log.Error(err, "unable to update workload", "kind", workload.Kind, "workloadName", workload.Object.GetName())
+		return false, err
 	}
-	observability.RecordDeploymentUpdated(deployment.Name, modelName)
-	log.V(0).Info("deployment updated", "deploymentName", deployment.Name)
-	return nil
+	if workload.Kind == DeploymentKind {
+		observability.RecordDeploymentUpdated(workload.Object.GetName(), modelName)
+	}
+	observability.RecordWorkloadUpdated(workload.Kind, workload.Object.GetName(), modelName)
+	log.V(0).Info("workload updated", "kind", workload.Kind, "workloadName", workload.Object.GetName())
+	return true, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -171,15 +415,10 @@ func (r *AuthorizationModelReconciler) SetupWithManager(mgr ctrl.Manager) error
 		r.Clock = clock.RealClock{}
 	}
 
-	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &appsV1.Deployment{}, deploymentIndexKey, func(rawObj client.Object) []string {
-		deployment := rawObj.(*appsV1.Deployment)
-		labelValue, exists := deployment.Labels[extensionsv1.OpenFgaStoreLabel]
-		if !exists {
-			return nil
+	for _, kind := range workloadKinds {
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), kind.newObject(), workloadIndexKey, indexWorkloadByStoreLabel); err != nil {
+			return err
 		}
-		return []string{labelValue}
-	}); err != nil {
-		return err
 	}
 
 	deletePredicate := predicate.Funcs{
@@ -192,8 +431,15 @@ func (r *AuthorizationModelReconciler) SetupWithManager(mgr ctrl.Manager) error
 		},
 	}
 
+	// The status of a Deployment doesn't change its generation, such that the predicates only apply to the
+	// authorization models. Only the Deployments waiting to become available with a new authorization model
+	// ID are watched, the progress deadline of the automatic rollback is checked by requeueing.
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&extensionsv1.AuthorizationModel{}).
-		WithEventFilter(deletePredicate).
+		For(&extensionsv1.AuthorizationModel{}, builder.WithPredicates(predicate.GenerationChangedPredicate{}, deletePredicate)).
+		Watches(
+			&appsV1.Deployment{},
+			handler.EnqueueRequestsFromMapFunc(requestsForDeployment),
+			builder.WithPredicates(predicate.NewPredicateFuncs(isWatchedDeployment)),
+		).
 		Complete(r)
 }
@@ -24,6 +24,7 @@ import (
 	. "github.com/onsi/gomega"
 	appsV1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"strings"
@@ -163,9 +164,139 @@ var _ = Describe("AuthorizationModel Controller", func() {
 			validateDeployment(deploymentName, name, storeId, authModelId, modelVersion)
 			validateNoEventsFound(eventRecorder.Events)
 		})
+
+		It("given bound deployment then record workload in status", func() {
+			// Arrange
+			authModelId := getLowercaseUUID()
+			deploymentName := getLowercaseUUID()
+			modelVersion := extensionsv1.ModelVersion{Major: 1}
+
+			deployment := createDeploymentWithAnnotations(name, deploymentName, map[string]string{
+				extensionsv1.OpenFgaStoreLabel: name,
+			})
+			Expect(k8sClient.Create(ctx, &deployment)).To(Succeed())
+
+			authorizationModel := extensionsv1.AuthorizationModel{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: name,
+				},
+				Spec: extensionsv1.AuthorizationModelSpec{
+					Instances: []extensionsv1.AuthorizationModelInstance{
+						{
+							Id:                 authModelId,
+							Version:            modelVersion,
+							AuthorizationModel: getLowercaseUUID(),
+						},
+					},
+				},
+			}
+
+			// Act
+			Expect(k8sClient.Create(ctx, &authorizationModel)).To(Succeed())
+
+			// Assert
+			Eventually(func() ([]extensionsv1.BoundWorkload, error) {
+				updated := &extensionsv1.AuthorizationModel{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: name}, updated); err != nil {
+					return nil, err
+				}
+				return updated.Status.Workloads, nil
+			}, duration, interval).Should(Equal([]extensionsv1.BoundWorkload{
+				{
+					Kind:                 DeploymentKind,
+					Name:                 deploymentName,
+					Version:              modelVersion.String(),
+					AuthorizationModelId: authModelId,
+				},
+			}))
+			updated := &extensionsv1.AuthorizationModel{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: name}, updated)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(updated.Status.Conditions, extensionsv1.ConditionWorkloadsSynced)).To(BeTrue())
+			Expect(updated.Status.LastUpdateTime.Time.UTC()).To(Equal(mockTime))
+		})
+
+		It("given stateful set with auth model version label then update stateful set to correct version", func() {
+			// Arrange
+			authModelId := getLowercaseUUID()
+			statefulSetName := getLowercaseUUID()
+			modelVersion := extensionsv1.ModelVersion{
+				Major: 0,
+				Minor: 0,
+				Patch: 1,
+			}
+
+			statefulSet := createStatefulSetWithLabels(name, statefulSetName, map[string]string{
+				extensionsv1.OpenFgaStoreLabel:            name,
+				extensionsv1.OpenFgaAuthModelVersionLabel: modelVersion.String(),
+			})
+			Expect(k8sClient.Create(ctx, &statefulSet)).To(Succeed())
+
+			authorizationModel := extensionsv1.AuthorizationModel{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: name,
+				},
+				Spec: extensionsv1.AuthorizationModelSpec{
+					Instances: []extensionsv1.AuthorizationModelInstance{
+						{
+							Id: getLowercaseUUID(),
+							Version: extensionsv1.ModelVersion{
+								Major: 1,
+								Minor: 2,
+								Patch: 3,
+							},
+							AuthorizationModel: getLowercaseUUID(),
+						},
+						{
+							Id:                 authModelId,
+							Version:            modelVersion,
+							AuthorizationModel: getLowercaseUUID(),
+						},
+					},
+				},
+			}
+
+			// Act
+			Expect(k8sClient.Create(ctx, &authorizationModel)).To(Succeed())
+
+			// Assert
+			validateWorkload(func() (metav1.Object, *corev1.PodTemplateSpec, error) {
+				statefulSet := &appsV1.StatefulSet{}
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: statefulSetName, Namespace: name}, statefulSet)
+				return statefulSet, &statefulSet.Spec.Template, err
+			}, storeId, authModelId, modelVersion)
+			validateNoEventsFound(eventRecorder.Events)
+		})
 	})
 })
 
+func createStatefulSetWithLabels(namespaceName, statefulSetName string, labels map[string]string) appsV1.StatefulSet {
+	return appsV1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespaceName,
+			Name:      statefulSetName,
+			Labels:    labels,
+		},
+		Spec: appsV1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": statefulSetName},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": statefulSetName}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Image: getLowercaseUUID(),
+							Name:  getLowercaseUUID(),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func createDeploymentWithAnnotations(namespaceName, deploymentName string, annotations map[string]string) appsV1.Deployment {
 	return appsV1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -207,17 +338,28 @@ func createDeploymentWithAnnotations(namespaceName, deploymentName string, annot
 }
 
 func validateDeployment(deploymentName, namespaceName, storeId, authModelId string, modelVersion extensionsv1.ModelVersion) {
-	Eventually(func() error {
+	validateWorkload(func() (metav1.Object, *corev1.PodTemplateSpec, error) {
 		deployment := &appsV1.Deployment{}
-		if err := k8sClient.Get(ctx, types.NamespacedName{
+		err := k8sClient.Get(ctx, types.NamespacedName{
 			Name:      deploymentName,
 			Namespace: namespaceName,
-		}, deployment); err != nil {
+		}, deployment)
+		return deployment, &deployment.Spec.Template, err
+	}, storeId, authModelId, modelVersion)
+}
+
+func validateWorkload(
+	getWorkload func() (metav1.Object, *corev1.PodTemplateSpec, error),
+	storeId, authModelId string,
+	modelVersion extensionsv1.ModelVersion) {
+	Eventually(func() error {
+		workload, template, err := getWorkload()
+		if err != nil {
 			return err
 		}
 
 		// Validate that all containers have the necessary environment variables
-		for _, container := range deployment.Spec.Template.Spec.Containers {
+		for _, container := range template.Spec.Containers {
 			foundStoreId := false
 			foundAuthModelId := false
 
@@ -239,15 +381,16 @@ func validateDeployment(deploymentName, namespaceName, storeId, authModelId stri
 			}
 		}
 
-		// Validate that the deployment has the annotation OpenFgaAuthModel with the correct version
-		if deployment.Annotations[extensionsv1.OpenFgaAuthModelVersionLabel] != modelVersion.String() {
-			return fmt.Errorf("deployment does not have annotation %s with value %s", extensionsv1.OpenFgaAuthModelVersionLabel, modelVersion.String())
+		// Validate that the workload has the annotation OpenFgaAuthModel with the correct version
+		annotations := workload.GetAnnotations()
+		if annotations[extensionsv1.OpenFgaAuthModelVersionLabel] != modelVersion.String() {
+			return fmt.Errorf("workload does not have annotation %s with value %s", extensionsv1.OpenFgaAuthModelVersionLabel, modelVersion.String())
 		}
-		if deployment.Annotations[extensionsv1.OpenFgaStoreIdUpdatedAtAnnotation] != MockTimeAsString() {
-			return fmt.Errorf("deployment does not have annotation %s with value %s", extensionsv1.OpenFgaStoreIdUpdatedAtAnnotation, MockTimeAsString())
+		if annotations[extensionsv1.OpenFgaStoreIdUpdatedAtAnnotation] != MockTimeAsString() {
+			return fmt.Errorf("workload does not have annotation %s with value %s", extensionsv1.OpenFgaStoreIdUpdatedAtAnnotation, MockTimeAsString())
 		}
-		if deployment.Annotations[extensionsv1.OpenFgaAuthIdUpdatedAtAnnotation] != MockTimeAsString() {
-			return fmt.Errorf("deployment does not have annotation %s with value %s", extensionsv1.OpenFgaAuthIdUpdatedAtAnnotation, MockTimeAsString())
+		if annotations[extensionsv1.OpenFgaAuthIdUpdatedAtAnnotation] != MockTimeAsString() {
+			return fmt.Errorf("workload does not have annotation %s with value %s", extensionsv1.OpenFgaAuthIdUpdatedAtAnnotation, MockTimeAsString())
 		}
 
 		return nil
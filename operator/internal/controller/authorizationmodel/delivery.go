@@ -0,0 +1,217 @@
+package authorizationmodel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	extensionsv1 "fga-operator/api/v1"
+	"fga-operator/internal/injection"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+	"strings"
+)
+
+const (
+	// deliveryVolumeName is the name of the volume of the delivery ConfigMap in the pod template.
+	deliveryVolumeName = "openfga"
+
+	// DeliveryMountPath is the directory the delivery ConfigMap is mounted at in the containers, with a file
+	// per key, e.g. /etc/openfga/OPENFGA_AUTH_MODEL_ID.
+	DeliveryMountPath = "/etc/openfga"
+)
+
+// deliveredValues are the names of the values a workload gets, which are the keys of the delivery ConfigMap.
+var deliveredValues = []string{
+	extensionsv1.OpenFgaStoreIdEnv,
+	extensionsv1.OpenFgaAuthModelIdEnv,
+	extensionsv1.OpenFgaAuthModelVersionEnv,
+	extensionsv1.OpenFgaApiUrlEnv,
+}
+
+// DeliveryConfigMapName returns the name of the ConfigMap delivering the IDs to the workload.
+func DeliveryConfigMapName(kind, name string) string {
+	return fmt.Sprintf("%s-%s-openfga", strings.ToLower(kind), name)
+}
+
+func deliveryMode(object metav1.Object) extensionsv1.DeliveryMode {
+	switch mode := extensionsv1.DeliveryMode(object.GetAnnotations()[extensionsv1.OpenFgaDeliveryAnnotation]); mode {
+	case extensionsv1.DeliveryModeConfigMap, extensionsv1.DeliveryModeConfigMapKeyRef:
+		return mode
+	default:
+		return extensionsv1.DeliveryModeEnv
+	}
+}
+
+// newDeliveryConfigMap returns the delivery ConfigMap of a workload which doesn't exist yet.
+func newDeliveryConfigMap(workload Workload) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DeliveryConfigMapName(workload.Kind, workload.Object.GetName()),
+			Namespace: workload.Object.GetNamespace(),
+			Labels:    map[string]string{extensionsv1.OpenFgaStoreLabel: workload.Object.GetLabels()[extensionsv1.OpenFgaStoreLabel]},
+		},
+		Data: map[string]string{},
+	}
+}
+
+// workloadInjection returns the injection of the workload, referencing the keys of its delivery ConfigMap with
+// DeliveryModeConfigMapKeyRef.
+func workloadInjection(workload Workload) injection.Injection {
+	workloadInjection := injection.FromObject(workload.Object)
+	if deliveryMode(workload.Object) == extensionsv1.DeliveryModeConfigMapKeyRef {
+		return workloadInjection.WithConfigMap(DeliveryConfigMapName(workload.Kind, workload.Object.GetName()))
+	}
+	return workloadInjection
+}
+
+// setWorkloadValue delivers the value to the workload, as environment variable or in its ConfigMap, and
+// returns true when the workload changed. Workloads referencing the keys of the ConfigMap get the reference
+// to the key, and the checksum of the ConfigMap on their pod template.
+func setWorkloadValue(workload Workload, name, value string) bool {
+	envInjection := workloadInjection(workload)
+	template := workload.PodTemplate()
+	if workload.ConfigMap == nil {
+		return envInjection.SetEnvVar(&template.Spec, name, value)
+	}
+	if workload.ConfigMap.Data == nil {
+		workload.ConfigMap.Data = map[string]string{}
+	}
+	changed := false
+	if current, ok := workload.ConfigMap.Data[name]; !ok || current != value {
+		workload.ConfigMap.Data[name] = value
+		changed = true
+	}
+	if envInjection.ConfigMapName() == "" {
+		return changed
+	}
+	referenced := envInjection.SetEnvVar(&template.Spec, name, value)
+	checksum := configMapChecksum(workload.ConfigMap)
+	if template.Annotations[extensionsv1.OpenFgaConfigMapChecksumAnnotation] != checksum {
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[extensionsv1.OpenFgaConfigMapChecksumAnnotation] = checksum
+		changed = true
+	}
+	return changed || referenced
+}
+
+// configMapChecksum returns the checksum of the data of the ConfigMap, which changes the pod template of
+// workloads referencing its keys whenever a value changes, since environment variables are only read when a
+// container starts.
+func configMapChecksum(configMap *corev1.ConfigMap) string {
+	keys := make([]string, 0, len(configMap.Data))
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%s\n", key, configMap.Data[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// getWorkloadValue returns the value delivered to the workload.
+func getWorkloadValue(workload Workload, name string) string {
+	if workload.ConfigMap != nil {
+		return workload.ConfigMap.Data[name]
+	}
+	return getPodTemplateEnvVar(workload, name)
+}
+
+// updateDeliveryOnWorkloads mounts the ConfigMap into the targeted containers of the workloads with the
+// ConfigMap delivery, and removes the environment variables they got before, which would be outdated otherwise.
+// Workloads referencing the keys of the ConfigMap get their environment variables when the values are set.
+func updateDeliveryOnWorkloads(workloads []Workload, updates map[WorkloadIdentifier]Workload) {
+	for _, workload := range workloads {
+		if workload.ConfigMap == nil || deliveryMode(workload.Object) != extensionsv1.DeliveryModeConfigMap {
+			continue
+		}
+		envInjection := workloadInjection(workload)
+		template := workload.PodTemplate()
+		changed := mountDeliveryConfigMap(template, workload.ConfigMap.Name, envInjection)
+		for _, name := range deliveredValues {
+			if envInjection.RemoveEnvVar(&template.Spec, name) {
+				changed = true
+			}
+		}
+		if changed {
+			updates[workload.identifier()] = workload
+		}
+	}
+}
+
+// removeStaleEnvVars removes the environment variables the workloads got before, which they don't get anymore since
+// their container is no longer targeted or the name of the variable changed, and records the environment variables
+// of the updated workloads. Workloads with the ConfigMap delivery don't get environment variables.
+func removeStaleEnvVars(workloads []Workload, updates map[WorkloadIdentifier]Workload) {
+	for _, workload := range workloads {
+		template := workload.PodTemplate()
+		injected := map[string][]string{}
+		if workload.ConfigMap == nil || deliveryMode(workload.Object) != extensionsv1.DeliveryModeConfigMap {
+			injected = workloadInjection(workload).Injected(&template.Spec)
+		}
+		if injection.RemoveStale(&template.Spec, injection.Recorded(workload.Object, &template.Spec), injected) {
+			updates[workload.identifier()] = workload
+		}
+		if _, ok := updates[workload.identifier()]; ok {
+			injection.Record(workload.Object, injected)
+		}
+	}
+}
+
+// mountDeliveryConfigMap adds the volume of the ConfigMap to the pod template and mounts it into every
+// targeted container, and returns true when the pod template changed. The ConfigMap isn't mounted with a sub
+// path, since the kubelet doesn't update files mounted with a sub path.
+func mountDeliveryConfigMap(template *corev1.PodTemplateSpec, configMapName string, envInjection injection.Injection) bool {
+	updated := false
+	hasVolume := false
+	for i := range template.Spec.Volumes {
+		volume := &template.Spec.Volumes[i]
+		if volume.Name != deliveryVolumeName {
+			continue
+		}
+		hasVolume = true
+		if volume.ConfigMap == nil || volume.ConfigMap.Name != configMapName {
+			volume.VolumeSource = configMapVolumeSource(configMapName)
+			updated = true
+		}
+	}
+	if !hasVolume {
+		template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+			Name:         deliveryVolumeName,
+			VolumeSource: configMapVolumeSource(configMapName),
+		})
+		updated = true
+	}
+
+	for _, container := range envInjection.Targets(&template.Spec) {
+		hasMount := false
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == deliveryVolumeName {
+				hasMount = true
+				break
+			}
+		}
+		if hasMount {
+			continue
+		}
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      deliveryVolumeName,
+			MountPath: DeliveryMountPath,
+			ReadOnly:  true,
+		})
+		updated = true
+	}
+	return updated
+}
+
+func configMapVolumeSource(configMapName string) corev1.VolumeSource {
+	return corev1.VolumeSource{
+		ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: configMapName},
+		},
+	}
+}
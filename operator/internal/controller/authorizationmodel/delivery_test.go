@@ -0,0 +1,177 @@
+package authorizationmodel
+
+import (
+	extensionsv1 "fga-operator/api/v1"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"testing"
+	"time"
+)
+
+func createConfigMapWorkload(name string, envVars []corev1.EnvVar, data map[string]string) Workload {
+	workload := createDeploymentWorkload("default", name, envVars,
+		map[string]string{extensionsv1.OpenFgaDeliveryAnnotation: string(extensionsv1.DeliveryModeConfigMap)})
+	workload.ConfigMap = newDeliveryConfigMap(workload)
+	workload.ConfigMap.Data = data
+	return workload
+}
+
+func TestUpdateDeliveryOnWorkloads(t *testing.T) {
+	// Arrange
+	workload := createConfigMapWorkload("configmap", []corev1.EnvVar{
+		{Name: "FOO", Value: "bar"},
+		{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "stale"},
+	}, nil)
+	env := createDeploymentWorkload("default", "env", []corev1.EnvVar{}, map[string]string{})
+	updates := map[WorkloadIdentifier]Workload{}
+
+	// Act
+	updateDeliveryOnWorkloads([]Workload{workload, env}, updates)
+
+	// Assert
+	if diff := cmp.Diff([]string{"Deployment/configmap"}, batchNames(updates)); diff != "" {
+		t.Errorf("updates mismatch (-expected +actual):\n%s", diff)
+	}
+	template := workload.PodTemplate()
+	expectedVolumes := []corev1.Volume{{Name: deliveryVolumeName, VolumeSource: configMapVolumeSource("deployment-configmap-openfga")}}
+	if diff := cmp.Diff(expectedVolumes, template.Spec.Volumes); diff != "" {
+		t.Errorf("volumes mismatch (-expected +actual):\n%s", diff)
+	}
+	expectedMounts := []corev1.VolumeMount{{Name: deliveryVolumeName, MountPath: DeliveryMountPath, ReadOnly: true}}
+	if diff := cmp.Diff(expectedMounts, template.Spec.Containers[0].VolumeMounts); diff != "" {
+		t.Errorf("volume mounts mismatch (-expected +actual):\n%s", diff)
+	}
+	if diff := cmp.Diff([]corev1.EnvVar{{Name: "FOO", Value: "bar"}}, template.Spec.Containers[0].Env); diff != "" {
+		t.Errorf("env mismatch (-expected +actual):\n%s", diff)
+	}
+
+	// Act
+	updates = map[WorkloadIdentifier]Workload{}
+	updateDeliveryOnWorkloads([]Workload{workload}, updates)
+
+	// Assert
+	if len(updates) != 0 {
+		t.Errorf("expected mounted workload not to be updated again")
+	}
+}
+
+func TestUpdateDeliveryOnWorkloadsRemovesEnvVarsOfTargets(t *testing.T) {
+	// Arrange
+	env := []corev1.EnvVar{
+		{Name: "FOO", Value: "bar"},
+		{Name: "FGA_STORE", Value: "store-id"},
+		{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "model-id"},
+		{Name: "FGA_MODEL_VERSION", Value: "1.0.0"},
+		{Name: extensionsv1.OpenFgaApiUrlEnv, Value: "http://openfga:8080"},
+	}
+	workload := createConfigMapWorkload("configmap", env, nil)
+	workload.Object.SetAnnotations(map[string]string{
+		extensionsv1.OpenFgaDeliveryAnnotation:            string(extensionsv1.DeliveryModeConfigMap),
+		extensionsv1.OpenFgaStoreIdEnvAnnotation:          "FGA_STORE",
+		extensionsv1.OpenFgaAuthModelVersionEnvAnnotation: "FGA_MODEL_VERSION",
+		extensionsv1.OpenFgaApiUrlEnvAnnotation:           extensionsv1.OpenFgaApiUrlEnv,
+		extensionsv1.OpenFgaContainersAnnotation:          "migrate",
+	})
+	template := workload.PodTemplate()
+	template.Spec.InitContainers = []corev1.Container{{Name: "migrate", Env: append([]corev1.EnvVar{}, env...)}}
+	expectedEnv := append([]corev1.EnvVar{}, env...)
+	updates := map[WorkloadIdentifier]Workload{}
+
+	// Act
+	updateDeliveryOnWorkloads([]Workload{workload}, updates)
+
+	// Assert
+	if _, ok := updates[workload.identifier()]; !ok {
+		t.Errorf("expected workload to be updated")
+	}
+	if diff := cmp.Diff([]corev1.EnvVar{{Name: "FOO", Value: "bar"}}, template.Spec.InitContainers[0].Env); diff != "" {
+		t.Errorf("init container env mismatch (-expected +actual):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedEnv, template.Spec.Containers[0].Env); diff != "" {
+		t.Errorf("untargeted container env mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestUpdateAuthorizationModelIdOnConfigMapWorkload(t *testing.T) {
+	// Arrange
+	logger := log.Log
+	workload := createConfigMapWorkload("configmap", []corev1.EnvVar{}, map[string]string{extensionsv1.OpenFgaAuthModelIdEnv: "old-id"})
+	updates := updateStoreIdOnWorkloads([]Workload{workload}, &extensionsv1.Store{Spec: extensionsv1.StoreSpec{Id: "store-id"}}, time.Now())
+
+	// Act
+	failures := updateAuthorizationModelIdOnWorkloads([]Workload{workload}, updates, &MockAuthorizationModel{}, time.Now(), &logger)
+
+	// Assert
+	if len(failures) != 0 {
+		t.Fatalf("unexpected failures %v", failures)
+	}
+	expected := map[string]string{
+		extensionsv1.OpenFgaStoreIdEnv:          "store-id",
+		extensionsv1.OpenFgaAuthModelIdEnv:      "auth-model-id",
+		extensionsv1.OpenFgaAuthModelVersionEnv: "1.2.3",
+	}
+	if diff := cmp.Diff(expected, workload.ConfigMap.Data); diff != "" {
+		t.Errorf("config map mismatch (-expected +actual):\n%s", diff)
+	}
+	if env := workload.PodTemplate().Spec.Containers[0].Env; len(env) != 0 {
+		t.Errorf("expected no environment variables, got %v", env)
+	}
+	if getWorkloadValue(workload, extensionsv1.OpenFgaAuthModelIdEnv) != "auth-model-id" {
+		t.Errorf("expected the authorization model id to be read from the config map")
+	}
+}
+
+func TestSetWorkloadValueWithConfigMapKeyRef(t *testing.T) {
+	// Arrange
+	workload := createDeploymentWorkload("default", "keyref",
+		[]corev1.EnvVar{{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "stale"}},
+		map[string]string{
+			extensionsv1.OpenFgaDeliveryAnnotation:            string(extensionsv1.DeliveryModeConfigMapKeyRef),
+			extensionsv1.OpenFgaAuthModelVersionEnvAnnotation: "FGA_MODEL_VERSION",
+		})
+	workload.ConfigMap = newDeliveryConfigMap(workload)
+	updates := updateStoreIdOnWorkloads([]Workload{workload}, &extensionsv1.Store{Spec: extensionsv1.StoreSpec{Id: "store-id"}}, time.Now())
+	logger := log.Log
+
+	// Act
+	failures := updateAuthorizationModelIdOnWorkloads([]Workload{workload}, updates, &MockAuthorizationModel{}, time.Now(), &logger)
+
+	// Assert
+	if len(failures) != 0 {
+		t.Fatalf("unexpected failures %v", failures)
+	}
+	keyRef := func(envName, key string) corev1.EnvVar {
+		return corev1.EnvVar{Name: envName, ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "deployment-keyref-openfga"},
+			Key:                  key,
+		}}}
+	}
+	expectedEnv := []corev1.EnvVar{
+		keyRef(extensionsv1.OpenFgaAuthModelIdEnv, extensionsv1.OpenFgaAuthModelIdEnv),
+		keyRef(extensionsv1.OpenFgaStoreIdEnv, extensionsv1.OpenFgaStoreIdEnv),
+		keyRef("FGA_MODEL_VERSION", extensionsv1.OpenFgaAuthModelVersionEnv),
+	}
+	template := workload.PodTemplate()
+	if diff := cmp.Diff(expectedEnv, template.Spec.Containers[0].Env); diff != "" {
+		t.Errorf("env mismatch (-expected +actual):\n%s", diff)
+	}
+	if _, ok := updates[workload.identifier()]; !ok {
+		t.Errorf("expected workload to be updated")
+	}
+	checksum := template.Annotations[extensionsv1.OpenFgaConfigMapChecksumAnnotation]
+	if checksum != configMapChecksum(workload.ConfigMap) {
+		t.Errorf("expected checksum of the config map on the pod template, got %q", checksum)
+	}
+
+	// Act
+	changed := setWorkloadValue(workload, extensionsv1.OpenFgaAuthModelIdEnv, "new-id")
+
+	// Assert
+	if !changed || template.Annotations[extensionsv1.OpenFgaConfigMapChecksumAnnotation] == checksum {
+		t.Errorf("expected a new value to change the checksum of the pod template")
+	}
+	if setWorkloadValue(workload, extensionsv1.OpenFgaAuthModelIdEnv, "new-id") {
+		t.Errorf("expected the same value not to change the workload")
+	}
+}
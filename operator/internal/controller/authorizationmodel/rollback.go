@@ -0,0 +1,178 @@
+package authorizationmodel
+
+import (
+	"context"
+	extensionsv1 "fga-operator/api/v1"
+	"fmt"
+	appsV1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"time"
+)
+
+// rolledBackWorkload is a Deployment which has been reverted to its previous authorization model ID.
+type rolledBackWorkload struct {
+	workload      Workload
+	failedVersion extensionsv1.FailedVersion
+}
+
+// rollbackCheck is the outcome of checking the Deployments waiting to become available with a new
+// authorization model ID.
+type rollbackCheck struct {
+	// updates holds the Deployments which are rolled back or done waiting, and must be updated.
+	updates map[WorkloadIdentifier]Workload
+
+	rolledBack []rolledBackWorkload
+
+	// watching is set when any Deployment is still waiting to become available.
+	watching bool
+
+	// requeueAfter is the time until the first progress deadline of the watched Deployments runs out.
+	requeueAfter time.Duration
+}
+
+// checkRollbacks checks the Deployments which got a new authorization model ID while the automatic rollback
+// was enabled, i.e. the Deployments with the rollback annotations. Available Deployments are done and lose
+// their rollback annotations. Deployments which aren't available within the progress deadline, or which
+// Kubernetes reports as failing to progress, are reverted to the authorization model ID of their rollback
+// annotations.
+func checkRollbacks(workloads []Workload, rollback *extensionsv1.AutomaticRollback, now time.Time) rollbackCheck {
+	result := rollbackCheck{updates: map[WorkloadIdentifier]Workload{}}
+	deadline := rollbackProgressDeadline(rollback)
+	for _, workload := range workloads {
+		deployment, ok := workload.Object.(*appsV1.Deployment)
+		if !ok {
+			continue
+		}
+		previousId, ok := deployment.Annotations[extensionsv1.OpenFgaRollbackAuthModelIdAnnotation]
+		if !ok {
+			continue
+		}
+
+		if isDeploymentAvailable(deployment) {
+			removeRollbackAnnotations(workload)
+			result.updates[workload.identifier()] = workload
+			continue
+		}
+
+		updatedAt, err := time.Parse(time.RFC3339, deployment.Annotations[extensionsv1.OpenFgaAuthIdUpdatedAtAnnotation])
+		deadlineExceeded := isDeploymentProgressDeadlineExceeded(deployment)
+		if !deadlineExceeded && err == nil && now.Sub(updatedAt) <= deadline {
+			remaining := deadline - now.Sub(updatedAt)
+			if !result.watching || remaining < result.requeueAfter {
+				result.requeueAfter = remaining
+			}
+			result.watching = true
+			continue
+		}
+
+		reason := fmt.Sprintf("Deployment %s didn't become available within %s", deployment.Name, deadline)
+		if deadlineExceeded {
+			reason = fmt.Sprintf("Deployment %s exceeded its progress deadline", deployment.Name)
+		}
+		failedVersion := extensionsv1.FailedVersion{
+			Version:              deployment.Annotations[extensionsv1.OpenFgaAuthModelVersionLabel],
+			AuthorizationModelId: getWorkloadValue(workload, extensionsv1.OpenFgaAuthModelIdEnv),
+			Workload:             workloadName(workload),
+			Reason:               reason,
+			FailedAt:             metav1.NewTime(now),
+		}
+
+		previousVersion := deployment.Annotations[extensionsv1.OpenFgaRollbackAuthModelVersionAnnotation]
+		setWorkloadValue(workload, extensionsv1.OpenFgaAuthModelIdEnv, previousId)
+		setWorkloadValue(workload, extensionsv1.OpenFgaAuthModelVersionEnv, previousVersion)
+		setAnnotation(workload, extensionsv1.OpenFgaAuthIdUpdatedAtAnnotation, now.UTC().Format(time.RFC3339))
+		setAnnotation(workload, extensionsv1.OpenFgaAuthModelVersionLabel, previousVersion)
+		removeRollbackAnnotations(workload)
+
+		result.updates[workload.identifier()] = workload
+		result.rolledBack = append(result.rolledBack, rolledBackWorkload{workload: workload, failedVersion: failedVersion})
+	}
+	return result
+}
+
+// isWatchedDeployment returns true when the Deployment waits to become available with a new authorization model ID.
+func isWatchedDeployment(object client.Object) bool {
+	_, ok := object.GetAnnotations()[extensionsv1.OpenFgaRollbackAuthModelIdAnnotation]
+	return ok
+}
+
+// requestsForDeployment maps the Deployment to the authorization model of its store, which has the name of the store.
+func requestsForDeployment(_ context.Context, deployment client.Object) []reconcile.Request {
+	storeName, ok := deployment.GetLabels()[extensionsv1.OpenFgaStoreLabel]
+	if !ok || storeName == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: deployment.GetNamespace(), Name: storeName}}}
+}
+
+// recordRollbackAnnotations records the authorization model ID and version the Deployments had before they
+// are updated, which they are reverted to when they don't become available. Deployments without an
+// authorization model ID can't be reverted, and rolled back Deployments aren't reverted again. Deployments
+// which aren't available before the update aren't watched, since their failure isn't caused by the
+// authorization model.
+func (c rollbackCheck) recordRollbackAnnotations(updates map[WorkloadIdentifier]Workload, bound boundWorkloads) {
+	rolledBack := make(map[WorkloadIdentifier]struct{}, len(c.rolledBack))
+	for _, rolledBackWorkload := range c.rolledBack {
+		rolledBack[rolledBackWorkload.workload.identifier()] = struct{}{}
+	}
+	for identifier, workload := range updates {
+		if _, ok := rolledBack[identifier]; ok {
+			continue
+		}
+		previous, ok := bound[identifier]
+		if workload.Kind != DeploymentKind || !ok || previous.AuthorizationModelId == "" {
+			continue
+		}
+		if !isDeploymentAvailable(workload.Object.(*appsV1.Deployment)) {
+			continue
+		}
+		if previous.AuthorizationModelId == getWorkloadValue(workload, extensionsv1.OpenFgaAuthModelIdEnv) {
+			continue
+		}
+		setAnnotation(workload, extensionsv1.OpenFgaRollbackAuthModelIdAnnotation, previous.AuthorizationModelId)
+		setAnnotation(workload, extensionsv1.OpenFgaRollbackAuthModelVersionAnnotation, previous.Version)
+	}
+}
+
+// setFailedVersions adds the failed versions of the rolled back Deployments to the status, and removes the
+// failed versions which are no longer an instance of the authorization model.
+func setFailedVersions(authorizationModel *extensionsv1.AuthorizationModel, rolledBack []rolledBackWorkload) {
+	instances := make(map[string]struct{}, len(authorizationModel.Spec.Instances))
+	for _, instance := range authorizationModel.Spec.Instances {
+		instances[instance.Id] = struct{}{}
+	}
+	var failedVersions []extensionsv1.FailedVersion
+	failed := map[string]struct{}{}
+	for _, failedVersion := range authorizationModel.Status.FailedVersions {
+		if _, ok := instances[failedVersion.AuthorizationModelId]; ok {
+			failedVersions = append(failedVersions, failedVersion)
+			failed[failedVersion.AuthorizationModelId] = struct{}{}
+		}
+	}
+	for _, rolledBackWorkload := range rolledBack {
+		failedVersion := rolledBackWorkload.failedVersion
+		if _, ok := failed[failedVersion.AuthorizationModelId]; ok {
+			continue
+		}
+		failedVersions = append(failedVersions, failedVersion)
+		failed[failedVersion.AuthorizationModelId] = struct{}{}
+	}
+	authorizationModel.Status.FailedVersions = failedVersions
+}
+
+func removeRollbackAnnotations(workload Workload) {
+	annotations := workload.Object.GetAnnotations()
+	delete(annotations, extensionsv1.OpenFgaRollbackAuthModelIdAnnotation)
+	delete(annotations, extensionsv1.OpenFgaRollbackAuthModelVersionAnnotation)
+	workload.Object.SetAnnotations(annotations)
+}
+
+func rollbackProgressDeadline(rollback *extensionsv1.AutomaticRollback) time.Duration {
+	if rollback.ProgressDeadline == nil {
+		return defaultProgressDeadline
+	}
+	return rollback.ProgressDeadline.Duration
+}
@@ -0,0 +1,246 @@
+package authorizationmodel
+
+import (
+	"context"
+	extensionsv1 "fga-operator/api/v1"
+	"github.com/google/go-cmp/cmp"
+	appsV1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"testing"
+	"time"
+)
+
+func watchedDeployment(name string, available bool, updatedAt time.Time) Workload {
+	workload := rolloutDeployment(name, available)
+	deployment := workload.Object.(*appsV1.Deployment)
+	deployment.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "id-2"}}
+	deployment.Annotations = map[string]string{
+		extensionsv1.OpenFgaAuthIdUpdatedAtAnnotation:          updatedAt.UTC().Format(time.RFC3339),
+		extensionsv1.OpenFgaAuthModelVersionLabel:              "1.1.0",
+		extensionsv1.OpenFgaRollbackAuthModelIdAnnotation:      "id-1",
+		extensionsv1.OpenFgaRollbackAuthModelVersionAnnotation: "1.0.0",
+	}
+	return workload
+}
+
+func boundDeployment(name string, available bool) Workload {
+	workload := rolloutDeployment(name, available)
+	workload.PodTemplate().Spec.Containers[0].Env = []corev1.EnvVar{{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "id-1"}}
+	setAnnotation(workload, extensionsv1.OpenFgaAuthModelVersionLabel, "1.0.0")
+	return workload
+}
+
+func TestCheckRollbacks(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	rollback := &extensionsv1.AutomaticRollback{ProgressDeadline: &metav1.Duration{Duration: 5 * time.Minute}}
+
+	testCases := []struct {
+		description        string
+		workload           Workload
+		expectedUpdated    bool
+		expectedWatching   bool
+		expectedRequeue    time.Duration
+		expectedId         string
+		expectedVersion    string
+		expectedFailed     []extensionsv1.FailedVersion
+		expectedAnnotation bool
+	}{
+		{
+			description:     "available deployment is done",
+			workload:        watchedDeployment("available", true, now.Add(-time.Minute)),
+			expectedUpdated: true,
+			expectedId:      "id-2",
+			expectedVersion: "1.1.0",
+		},
+		{
+			description:        "unavailable deployment within deadline is watched",
+			workload:           watchedDeployment("progressing", false, now.Add(-time.Minute)),
+			expectedWatching:   true,
+			expectedRequeue:    4 * time.Minute,
+			expectedId:         "id-2",
+			expectedVersion:    "1.1.0",
+			expectedAnnotation: true,
+		},
+		{
+			description:     "unavailable deployment after deadline is rolled back",
+			workload:        watchedDeployment("failing", false, now.Add(-6*time.Minute)),
+			expectedUpdated: true,
+			expectedId:      "id-1",
+			expectedVersion: "1.0.0",
+			expectedFailed: []extensionsv1.FailedVersion{{
+				Version:              "1.1.0",
+				AuthorizationModelId: "id-2",
+				Workload:             "Deployment/failing",
+				Reason:               "Deployment failing didn't become available within 5m0s",
+				FailedAt:             metav1.NewTime(now),
+			}},
+		},
+		{
+			description: "deployment exceeding its progress deadline is rolled back",
+			workload: func() Workload {
+				workload := watchedDeployment("stuck", false, now.Add(-time.Minute))
+				workload.Object.(*appsV1.Deployment).Status.Conditions = []appsV1.DeploymentCondition{{
+					Type: appsV1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded",
+				}}
+				return workload
+			}(),
+			expectedUpdated: true,
+			expectedId:      "id-1",
+			expectedVersion: "1.0.0",
+			expectedFailed: []extensionsv1.FailedVersion{{
+				Version:              "1.1.0",
+				AuthorizationModelId: "id-2",
+				Workload:             "Deployment/stuck",
+				Reason:               "Deployment stuck exceeded its progress deadline",
+				FailedAt:             metav1.NewTime(now),
+			}},
+		},
+		{
+			description: "progress deadline of a previous generation is ignored",
+			workload: func() Workload {
+				workload := watchedDeployment("stale", false, now.Add(-time.Minute))
+				deployment := workload.Object.(*appsV1.Deployment)
+				deployment.Generation = 3
+				deployment.Status.Conditions = []appsV1.DeploymentCondition{{
+					Type: appsV1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded",
+				}}
+				return workload
+			}(),
+			expectedWatching:   true,
+			expectedRequeue:    4 * time.Minute,
+			expectedId:         "id-2",
+			expectedVersion:    "1.1.0",
+			expectedAnnotation: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Act
+			result := checkRollbacks([]Workload{testCase.workload}, rollback, now)
+
+			// Assert
+			if _, updated := result.updates[testCase.workload.identifier()]; updated != testCase.expectedUpdated {
+				t.Errorf("expected updated %t, got %t", testCase.expectedUpdated, updated)
+			}
+			if result.watching != testCase.expectedWatching {
+				t.Errorf("expected watching %t, got %t", testCase.expectedWatching, result.watching)
+			}
+			if result.requeueAfter != testCase.expectedRequeue {
+				t.Errorf("expected requeue after %s, got %s", testCase.expectedRequeue, result.requeueAfter)
+			}
+			if id := getPodTemplateEnvVar(testCase.workload, extensionsv1.OpenFgaAuthModelIdEnv); id != testCase.expectedId {
+				t.Errorf("expected authorization model id %s, got %s", testCase.expectedId, id)
+			}
+			annotations := testCase.workload.Object.GetAnnotations()
+			if version := annotations[extensionsv1.OpenFgaAuthModelVersionLabel]; version != testCase.expectedVersion {
+				t.Errorf("expected version %s, got %s", testCase.expectedVersion, version)
+			}
+			if _, ok := annotations[extensionsv1.OpenFgaRollbackAuthModelIdAnnotation]; ok != testCase.expectedAnnotation {
+				t.Errorf("expected rollback annotation %t, got %t", testCase.expectedAnnotation, ok)
+			}
+			var failed []extensionsv1.FailedVersion
+			for _, rolledBack := range result.rolledBack {
+				failed = append(failed, rolledBack.failedVersion)
+			}
+			if diff := cmp.Diff(testCase.expectedFailed, failed); diff != "" {
+				t.Errorf("failed versions mismatch (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRequestsForDeployment(t *testing.T) {
+	// Arrange
+	deployment := rolloutDeployment("watched", false).Object
+	deployment.SetLabels(map[string]string{extensionsv1.OpenFgaStoreLabel: "store"})
+
+	// Act
+	requests := requestsForDeployment(context.Background(), deployment)
+
+	// Assert
+	expected := []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "default", Name: "store"}}}
+	if diff := cmp.Diff(expected, requests); diff != "" {
+		t.Errorf("requests mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestRecordRollbackAnnotations(t *testing.T) {
+	// Arrange
+	updated := boundDeployment("updated", true)
+	failing := boundDeployment("failing", false)
+	created := rolloutDeployment("created", true)
+	bound := newBoundWorkloads([]Workload{updated, failing, created})
+	for _, workload := range []Workload{updated, failing, created} {
+		updatePodTemplateEnvVar(workload.PodTemplate(), extensionsv1.OpenFgaAuthModelIdEnv, "id-2")
+	}
+
+	// Act
+	rollbackCheck{}.recordRollbackAnnotations(updatesOf(updated, failing, created), bound)
+
+	// Assert
+	expected := map[string]string{
+		extensionsv1.OpenFgaAuthModelVersionLabel:              "1.0.0",
+		extensionsv1.OpenFgaRollbackAuthModelIdAnnotation:      "id-1",
+		extensionsv1.OpenFgaRollbackAuthModelVersionAnnotation: "1.0.0",
+	}
+	if diff := cmp.Diff(expected, updated.Object.GetAnnotations()); diff != "" {
+		t.Errorf("annotations mismatch (-expected +actual):\n%s", diff)
+	}
+	if _, ok := created.Object.GetAnnotations()[extensionsv1.OpenFgaRollbackAuthModelIdAnnotation]; ok {
+		t.Errorf("expected no rollback annotation on a workload without authorization model id")
+	}
+	if _, ok := failing.Object.GetAnnotations()[extensionsv1.OpenFgaRollbackAuthModelIdAnnotation]; ok {
+		t.Errorf("expected no rollback annotation on a workload which wasn't available before the update")
+	}
+}
+
+func TestRollbackSkipsDeploymentFailingBeforeUpdate(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	rollback := &extensionsv1.AutomaticRollback{ProgressDeadline: &metav1.Duration{Duration: 5 * time.Minute}}
+	failing := boundDeployment("failing", false)
+	bound := newBoundWorkloads([]Workload{failing})
+	updatePodTemplateEnvVar(failing.PodTemplate(), extensionsv1.OpenFgaAuthModelIdEnv, "id-2")
+	setAnnotation(failing, extensionsv1.OpenFgaAuthIdUpdatedAtAnnotation, now.Add(-6*time.Minute).Format(time.RFC3339))
+	rollbackCheck{}.recordRollbackAnnotations(updatesOf(failing), bound)
+
+	// Act
+	result := checkRollbacks([]Workload{failing}, rollback, now)
+
+	// Assert
+	if len(result.updates) != 0 || len(result.rolledBack) != 0 {
+		t.Errorf("expected no rollback, got %d updates and %d rolled back", len(result.updates), len(result.rolledBack))
+	}
+	if id := getPodTemplateEnvVar(failing, extensionsv1.OpenFgaAuthModelIdEnv); id != "id-2" {
+		t.Errorf("expected authorization model id id-2, got %s", id)
+	}
+}
+
+func TestSetFailedVersions(t *testing.T) {
+	// Arrange
+	authorizationModel := &extensionsv1.AuthorizationModel{
+		Spec: extensionsv1.AuthorizationModelSpec{
+			Instances: []extensionsv1.AuthorizationModelInstance{{Id: "id-1"}, {Id: "id-2"}},
+		},
+		Status: extensionsv1.AuthorizationModelStatus{
+			FailedVersions: []extensionsv1.FailedVersion{{AuthorizationModelId: "id-2"}, {AuthorizationModelId: "removed"}},
+		},
+	}
+	rolledBack := []rolledBackWorkload{
+		{failedVersion: extensionsv1.FailedVersion{AuthorizationModelId: "id-2", Workload: "Deployment/b"}},
+		{failedVersion: extensionsv1.FailedVersion{AuthorizationModelId: "id-1", Workload: "Deployment/a"}},
+	}
+
+	// Act
+	setFailedVersions(authorizationModel, rolledBack)
+
+	// Assert
+	expected := []extensionsv1.FailedVersion{{AuthorizationModelId: "id-2"}, {AuthorizationModelId: "id-1", Workload: "Deployment/a"}}
+	if diff := cmp.Diff(expected, authorizationModel.Status.FailedVersions); diff != "" {
+		t.Errorf("failed versions mismatch (-expected +actual):\n%s", diff)
+	}
+}
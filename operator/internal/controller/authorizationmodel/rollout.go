@@ -0,0 +1,235 @@
+package authorizationmodel
+
+import (
+	extensionsv1 "fga-operator/api/v1"
+	"fmt"
+	appsV1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultProgressDeadline is the time the Deployments of a batch have to become available when the
+	// rollout strategy doesn't set a progress deadline.
+	defaultProgressDeadline = 10 * time.Minute
+
+	// rolloutPollInterval is the interval at which a progressing rollout checks its last batch, since
+	// the controller only watches the Deployments of the automatic rollback.
+	rolloutPollInterval = 10 * time.Second
+)
+
+// rollout is the outcome of planning the next step of a rollout.
+type rollout struct {
+	status *extensionsv1.RolloutStatus
+
+	// batch holds the workloads to update in this reconciliation.
+	batch map[WorkloadIdentifier]Workload
+
+	// requeueAfter is the time after which the rollout should be checked again, and zero when it isn't
+	// progressing.
+	requeueAfter time.Duration
+
+	// started, halted and completed are set when the rollout started a batch, halted or completed in
+	// this reconciliation.
+	started   bool
+	halted    bool
+	completed bool
+}
+
+// planRollout decides which of the pending updates are applied, given the rollout strategy of the
+// authorization model and the progress of the rollout so far. A new rollout is started whenever the
+// generation of the authorization model changes, e.g. when a version is added.
+//
+// Updates of workloads with an immutable pod template don't take part in the rollout, since they are
+// never applied. The same holds for workloads of an unknown kind, whose update fails.
+func planRollout(
+	authorizationModel *extensionsv1.AuthorizationModel,
+	workloads []Workload,
+	updates map[WorkloadIdentifier]Workload,
+	now time.Time) rollout {
+	strategy := authorizationModel.Spec.RolloutStrategy
+	result := rollout{batch: map[WorkloadIdentifier]Workload{}}
+
+	var pending []Workload
+	for identifier, workload := range updates {
+		if kind, ok := getWorkloadKind(workload.Kind); !ok || kind.immutableTemplate {
+			result.batch[identifier] = workload
+			continue
+		}
+		pending = append(pending, workload)
+	}
+	sortWorkloads(pending)
+
+	previous := authorizationModel.Status.Rollout
+	if previous == nil || previous.ObservedGeneration != authorizationModel.Generation {
+		result.status = &extensionsv1.RolloutStatus{
+			ObservedGeneration: authorizationModel.Generation,
+			Phase:              extensionsv1.RolloutPhaseProgressing,
+		}
+	} else {
+		result.status = previous.DeepCopy()
+	}
+	status := result.status
+	status.PendingWorkloads = int32(len(pending))
+
+	switch status.Phase {
+	case extensionsv1.RolloutPhaseHalted:
+		return result
+	case extensionsv1.RolloutPhaseCompleted:
+		// Workloads bound after the rollout completed are updated at once.
+		for _, workload := range pending {
+			result.batch[workload.identifier()] = workload
+		}
+		status.PendingWorkloads = 0
+		return result
+	}
+
+	if strategy.WaitForAvailable {
+		unavailable, deadlineExceeded := unavailableDeployments(workloads, status.CurrentBatch)
+		if len(unavailable) > 0 {
+			deadline := progressDeadline(strategy)
+			if deadlineExceeded || (status.LastBatchTime != nil && now.Sub(status.LastBatchTime.Time) > deadline) {
+				status.Phase = extensionsv1.RolloutPhaseHalted
+				status.Message = fmt.Sprintf("Deployments %s didn't become available within %s",
+					strings.Join(unavailable, ", "), deadline)
+				result.halted = true
+				return result
+			}
+			result.requeueAfter = rolloutPollInterval
+			return result
+		}
+	}
+
+	if strategy.PauseBetweenBatches != nil && status.LastBatchTime != nil {
+		if remaining := status.LastBatchTime.Add(strategy.PauseBetweenBatches.Duration).Sub(now); remaining > 0 {
+			result.requeueAfter = remaining
+			return result
+		}
+	}
+
+	if len(pending) == 0 {
+		status.Phase = extensionsv1.RolloutPhaseCompleted
+		status.CurrentBatch = nil
+		status.Message = ""
+		result.completed = status.Batches > 0
+		return result
+	}
+
+	size := batchSize(strategy, mutableWorkloads(workloads))
+	if size > len(pending) {
+		size = len(pending)
+	}
+	status.CurrentBatch = make([]string, 0, size)
+	for _, workload := range pending[:size] {
+		result.batch[workload.identifier()] = workload
+		status.CurrentBatch = append(status.CurrentBatch, workloadName(workload))
+	}
+	status.Batches++
+	status.UpdatedWorkloads += int32(size)
+	status.PendingWorkloads -= int32(size)
+	status.LastBatchTime = &metav1.Time{Time: now}
+	result.started = true
+	result.requeueAfter = rolloutPollInterval
+	return result
+}
+
+// batchSize returns the number of workloads per batch, which is at least one.
+func batchSize(strategy *extensionsv1.RolloutStrategy, total int) int {
+	size := int(strategy.BatchSize)
+	if strategy.BatchPercentage > 0 {
+		size = (total*int(strategy.BatchPercentage) + 99) / 100
+	}
+	if size < 1 {
+		return 1
+	}
+	return size
+}
+
+func progressDeadline(strategy *extensionsv1.RolloutStrategy) time.Duration {
+	if strategy.ProgressDeadline == nil {
+		return defaultProgressDeadline
+	}
+	return strategy.ProgressDeadline.Duration
+}
+
+// unavailableDeployments returns the Deployments of the batch which aren't available yet, and whether
+// Kubernetes reported any of them as failing to progress. Workloads which no longer exist are done.
+func unavailableDeployments(workloads []Workload, batch []string) ([]string, bool) {
+	inBatch := make(map[string]struct{}, len(batch))
+	for _, name := range batch {
+		inBatch[name] = struct{}{}
+	}
+	var unavailable []string
+	deadlineExceeded := false
+	for _, workload := range workloads {
+		deployment, ok := workload.Object.(*appsV1.Deployment)
+		if !ok {
+			continue
+		}
+		if _, ok := inBatch[workloadName(workload)]; !ok || isDeploymentAvailable(deployment) {
+			continue
+		}
+		unavailable = append(unavailable, deployment.Name)
+		deadlineExceeded = deadlineExceeded || isDeploymentProgressDeadlineExceeded(deployment)
+	}
+	sort.Strings(unavailable)
+	return unavailable, deadlineExceeded
+}
+
+// isDeploymentAvailable returns true when the Deployment observed its latest pod template, and all of its
+// replicas are updated and available.
+func isDeploymentAvailable(deployment *appsV1.Deployment) bool {
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return false
+	}
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	return deployment.Status.UpdatedReplicas >= replicas &&
+		deployment.Status.AvailableReplicas >= replicas &&
+		deployment.Status.Replicas == deployment.Status.UpdatedReplicas
+}
+
+// isDeploymentProgressDeadlineExceeded returns true when Kubernetes reports the Deployment as failing to progress.
+// The condition is ignored until the Deployment observed its latest pod template, since it can be left over from
+// a previous rollout.
+func isDeploymentProgressDeadlineExceeded(deployment *appsV1.Deployment) bool {
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return false
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsV1.DeploymentProgressing &&
+			condition.Status == corev1.ConditionFalse &&
+			condition.Reason == "ProgressDeadlineExceeded" {
+			return true
+		}
+	}
+	return false
+}
+
+func mutableWorkloads(workloads []Workload) int {
+	count := 0
+	for _, workload := range workloads {
+		if kind, ok := getWorkloadKind(workload.Kind); ok && !kind.immutableTemplate {
+			count++
+		}
+	}
+	return count
+}
+
+func sortWorkloads(workloads []Workload) {
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].Kind != workloads[j].Kind {
+			return workloads[i].Kind < workloads[j].Kind
+		}
+		return workloads[i].Object.GetName() < workloads[j].Object.GetName()
+	})
+}
+
+func workloadName(workload Workload) string {
+	return workload.Kind + "/" + workload.Object.GetName()
+}
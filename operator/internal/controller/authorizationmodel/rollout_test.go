@@ -0,0 +1,389 @@
+package authorizationmodel
+
+import (
+	"context"
+	extensionsv1 "fga-operator/api/v1"
+	"github.com/google/go-cmp/cmp"
+	appsV1 "k8s.io/api/apps/v1"
+	batchV1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"testing"
+	"time"
+)
+
+func rolloutDeployment(name string, available bool) Workload {
+	deployment := createDeploymentWithNameAndAnnotations("default", name, []corev1.EnvVar{}, map[string]string{})
+	deployment.Generation = 2
+	deployment.Status.ObservedGeneration = 2
+	if available {
+		deployment.Status = appsV1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	}
+	return newWorkload(DeploymentKind, &deployment)
+}
+
+func updatesOf(workloads ...Workload) map[WorkloadIdentifier]Workload {
+	updates := map[WorkloadIdentifier]Workload{}
+	for _, workload := range workloads {
+		updates[workload.identifier()] = workload
+	}
+	return updates
+}
+
+func batchNames(batch map[WorkloadIdentifier]Workload) []string {
+	var names []string
+	for _, workload := range batch {
+		names = append(names, workloadName(workload))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestPlanRollout(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	minutesAgo := func(minutes int) *metav1.Time {
+		return &metav1.Time{Time: now.Add(-time.Duration(minutes) * time.Minute)}
+	}
+	a, b, c, d := rolloutDeployment("a", true), rolloutDeployment("b", true), rolloutDeployment("c", false), rolloutDeployment("d", false)
+	job := newWorkload(JobKind, &batchV1.Job{ObjectMeta: metav1.ObjectMeta{Name: "job"}})
+
+	testCases := []struct {
+		description      string
+		strategy         extensionsv1.RolloutStrategy
+		previous         *extensionsv1.RolloutStatus
+		workloads        []Workload
+		updates          map[WorkloadIdentifier]Workload
+		expectedBatch    []string
+		expectedPhase    extensionsv1.RolloutPhase
+		expectedBatches  int32
+		expectedPending  int32
+		expectedRequeue  time.Duration
+		expectedHalted   bool
+		expectedComplete bool
+	}{
+		{
+			description:     "new rollout starts the first batch",
+			strategy:        extensionsv1.RolloutStrategy{BatchSize: 2},
+			workloads:       []Workload{a, b, c, d},
+			updates:         updatesOf(d, c, b, a),
+			expectedBatch:   []string{"Deployment/a", "Deployment/b"},
+			expectedPhase:   extensionsv1.RolloutPhaseProgressing,
+			expectedBatches: 1,
+			expectedPending: 2,
+			expectedRequeue: rolloutPollInterval,
+		},
+		{
+			description:     "batch percentage is rounded up",
+			strategy:        extensionsv1.RolloutStrategy{BatchPercentage: 30},
+			workloads:       []Workload{a, b, c, d},
+			updates:         updatesOf(a, b, c, d),
+			expectedBatch:   []string{"Deployment/a", "Deployment/b"},
+			expectedPhase:   extensionsv1.RolloutPhaseProgressing,
+			expectedBatches: 1,
+			expectedPending: 2,
+			expectedRequeue: rolloutPollInterval,
+		},
+		{
+			description:     "workloads with immutable template aren't batched",
+			strategy:        extensionsv1.RolloutStrategy{BatchSize: 1},
+			workloads:       []Workload{a, b, job},
+			updates:         updatesOf(a, b, job),
+			expectedBatch:   []string{"Deployment/a", "Job/job"},
+			expectedPhase:   extensionsv1.RolloutPhaseProgressing,
+			expectedBatches: 1,
+			expectedPending: 1,
+			expectedRequeue: rolloutPollInterval,
+		},
+		{
+			description: "next batch after the previous batch is available",
+			strategy:    extensionsv1.RolloutStrategy{BatchSize: 1, WaitForAvailable: true},
+			previous: &extensionsv1.RolloutStatus{
+				ObservedGeneration: 1, Phase: extensionsv1.RolloutPhaseProgressing, Batches: 1, UpdatedWorkloads: 1,
+				CurrentBatch: []string{"Deployment/a"}, LastBatchTime: minutesAgo(1),
+			},
+			workloads:       []Workload{a, b},
+			updates:         updatesOf(b),
+			expectedBatch:   []string{"Deployment/b"},
+			expectedPhase:   extensionsv1.RolloutPhaseProgressing,
+			expectedBatches: 2,
+			expectedRequeue: rolloutPollInterval,
+		},
+		{
+			description: "waits for the previous batch to become available",
+			strategy:    extensionsv1.RolloutStrategy{BatchSize: 1, WaitForAvailable: true},
+			previous: &extensionsv1.RolloutStatus{
+				ObservedGeneration: 1, Phase: extensionsv1.RolloutPhaseProgressing, Batches: 1, UpdatedWorkloads: 1,
+				CurrentBatch: []string{"Deployment/c"}, LastBatchTime: minutesAgo(1),
+			},
+			workloads:       []Workload{b, c},
+			updates:         updatesOf(b),
+			expectedPhase:   extensionsv1.RolloutPhaseProgressing,
+			expectedBatches: 1,
+			expectedPending: 1,
+			expectedRequeue: rolloutPollInterval,
+		},
+		{
+			description: "halts when the previous batch isn't available within the progress deadline",
+			strategy: extensionsv1.RolloutStrategy{
+				BatchSize: 1, WaitForAvailable: true, ProgressDeadline: &metav1.Duration{Duration: 5 * time.Minute},
+			},
+			previous: &extensionsv1.RolloutStatus{
+				ObservedGeneration: 1, Phase: extensionsv1.RolloutPhaseProgressing, Batches: 1, UpdatedWorkloads: 1,
+				CurrentBatch: []string{"Deployment/c"}, LastBatchTime: minutesAgo(6),
+			},
+			workloads:       []Workload{b, c},
+			updates:         updatesOf(b),
+			expectedPhase:   extensionsv1.RolloutPhaseHalted,
+			expectedBatches: 1,
+			expectedPending: 1,
+			expectedHalted:  true,
+		},
+		{
+			description: "halted rollout doesn't update workloads",
+			strategy:    extensionsv1.RolloutStrategy{BatchSize: 1},
+			previous: &extensionsv1.RolloutStatus{
+				ObservedGeneration: 1, Phase: extensionsv1.RolloutPhaseHalted, Batches: 1, UpdatedWorkloads: 1,
+			},
+			workloads:       []Workload{a, b},
+			updates:         updatesOf(b),
+			expectedPhase:   extensionsv1.RolloutPhaseHalted,
+			expectedBatches: 1,
+			expectedPending: 1,
+		},
+		{
+			description: "halted rollout is replaced when the authorization model changes",
+			strategy:    extensionsv1.RolloutStrategy{BatchSize: 1},
+			previous: &extensionsv1.RolloutStatus{
+				ObservedGeneration: 0, Phase: extensionsv1.RolloutPhaseHalted, Batches: 1, UpdatedWorkloads: 1,
+			},
+			workloads:       []Workload{a, b},
+			updates:         updatesOf(b),
+			expectedBatch:   []string{"Deployment/b"},
+			expectedPhase:   extensionsv1.RolloutPhaseProgressing,
+			expectedBatches: 1,
+			expectedRequeue: rolloutPollInterval,
+		},
+		{
+			description: "pauses between batches",
+			strategy:    extensionsv1.RolloutStrategy{BatchSize: 1, PauseBetweenBatches: &metav1.Duration{Duration: 5 * time.Minute}},
+			previous: &extensionsv1.RolloutStatus{
+				ObservedGeneration: 1, Phase: extensionsv1.RolloutPhaseProgressing, Batches: 1, UpdatedWorkloads: 1,
+				CurrentBatch: []string{"Deployment/a"}, LastBatchTime: minutesAgo(2),
+			},
+			workloads:       []Workload{a, b},
+			updates:         updatesOf(b),
+			expectedPhase:   extensionsv1.RolloutPhaseProgressing,
+			expectedBatches: 1,
+			expectedPending: 1,
+			expectedRequeue: 3 * time.Minute,
+		},
+		{
+			description: "completes when no workload is pending",
+			strategy:    extensionsv1.RolloutStrategy{BatchSize: 1, WaitForAvailable: true},
+			previous: &extensionsv1.RolloutStatus{
+				ObservedGeneration: 1, Phase: extensionsv1.RolloutPhaseProgressing, Batches: 2, UpdatedWorkloads: 2,
+				CurrentBatch: []string{"Deployment/b"}, LastBatchTime: minutesAgo(1),
+			},
+			workloads:        []Workload{a, b},
+			updates:          updatesOf(),
+			expectedPhase:    extensionsv1.RolloutPhaseCompleted,
+			expectedBatches:  2,
+			expectedComplete: true,
+		},
+		{
+			description: "completed rollout updates new workloads at once",
+			strategy:    extensionsv1.RolloutStrategy{BatchSize: 1},
+			previous: &extensionsv1.RolloutStatus{
+				ObservedGeneration: 1, Phase: extensionsv1.RolloutPhaseCompleted, Batches: 1, UpdatedWorkloads: 1,
+			},
+			workloads:       []Workload{a, b, c},
+			updates:         updatesOf(b, c),
+			expectedBatch:   []string{"Deployment/b", "Deployment/c"},
+			expectedPhase:   extensionsv1.RolloutPhaseCompleted,
+			expectedBatches: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			strategy := testCase.strategy
+			authorizationModel := &extensionsv1.AuthorizationModel{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec:       extensionsv1.AuthorizationModelSpec{RolloutStrategy: &strategy},
+				Status:     extensionsv1.AuthorizationModelStatus{Rollout: testCase.previous},
+			}
+
+			// Act
+			result := planRollout(authorizationModel, testCase.workloads, testCase.updates, now)
+
+			// Assert
+			if diff := cmp.Diff(testCase.expectedBatch, batchNames(result.batch)); diff != "" {
+				t.Errorf("batch mismatch (-expected +actual):\n%s", diff)
+			}
+			if result.status.Phase != testCase.expectedPhase {
+				t.Errorf("expected phase %s, got %s", testCase.expectedPhase, result.status.Phase)
+			}
+			if result.status.Batches != testCase.expectedBatches {
+				t.Errorf("expected %d batches, got %d", testCase.expectedBatches, result.status.Batches)
+			}
+			if result.status.PendingWorkloads != testCase.expectedPending {
+				t.Errorf("expected %d pending workloads, got %d", testCase.expectedPending, result.status.PendingWorkloads)
+			}
+			if result.requeueAfter != testCase.expectedRequeue {
+				t.Errorf("expected requeue after %s, got %s", testCase.expectedRequeue, result.requeueAfter)
+			}
+			if result.halted != testCase.expectedHalted || result.completed != testCase.expectedComplete {
+				t.Errorf("expected halted %t and completed %t, got %t and %t",
+					testCase.expectedHalted, testCase.expectedComplete, result.halted, result.completed)
+			}
+		})
+	}
+}
+
+func TestIsDeploymentAvailable(t *testing.T) {
+	replicas := int32(2)
+	testCases := []struct {
+		description string
+		deployment  appsV1.Deployment
+		expected    bool
+	}{
+		{
+			description: "all replicas updated and available",
+			deployment: appsV1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Spec:       appsV1.DeploymentSpec{Replicas: &replicas},
+				Status:     appsV1.DeploymentStatus{ObservedGeneration: 3, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+			expected: true,
+		},
+		{
+			description: "new pod template not observed yet",
+			deployment: appsV1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Spec:       appsV1.DeploymentSpec{Replicas: &replicas},
+				Status:     appsV1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+		},
+		{
+			description: "old replicas still running",
+			deployment: appsV1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Spec:       appsV1.DeploymentSpec{Replicas: &replicas},
+				Status:     appsV1.DeploymentStatus{ObservedGeneration: 3, Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+		},
+		{
+			description: "updated replica not available",
+			deployment: appsV1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Spec:       appsV1.DeploymentSpec{Replicas: &replicas},
+				Status:     appsV1.DeploymentStatus{ObservedGeneration: 3, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Act
+			available := isDeploymentAvailable(&testCase.deployment)
+
+			// Assert
+			if available != testCase.expected {
+				t.Errorf("expected available %t, got %t", testCase.expected, available)
+			}
+		})
+	}
+}
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c *fixedClock) Now() time.Time {
+	return c.now
+}
+
+func TestReconcileRolloutPausesBetweenBatches(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	store := extensionsv1.NewStore("store", "default", "store-id", now)
+	model := extensionsv1.NewAuthorizationModel("store", "default", []extensionsv1.AuthorizationModelDefinition{
+		extensionsv1.NewAuthorizationModelDefinition("model-1", "", extensionsv1.ModelVersion{Major: 1}),
+	}, now)
+	model.Spec.RolloutStrategy = &extensionsv1.RolloutStrategy{
+		BatchSize:           1,
+		PauseBetweenBatches: &metav1.Duration{Duration: time.Minute},
+	}
+	objects := []client.Object{store, &model}
+	for _, name := range []string{"a", "b"} {
+		deployment := createDeploymentWithNameAndAnnotations("default", name, []corev1.EnvVar{}, map[string]string{})
+		deployment.Labels = map[string]string{extensionsv1.OpenFgaStoreLabel: "store"}
+		objects = append(objects, &deployment)
+	}
+	fakeClient := newFakeClient(t, objects...)
+	clock := &fixedClock{now: now}
+	reconciliationInterval := 5 * time.Minute
+	reconciler := &AuthorizationModelReconciler{
+		Client:                 fakeClient,
+		Scheme:                 fakeClient.Scheme(),
+		Recorder:               record.NewFakeRecorder(10),
+		Clock:                  clock,
+		ReconciliationInterval: &reconciliationInterval,
+	}
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "store", Namespace: "default"}}
+	updatedDeployments := func() []string {
+		var names []string
+		for _, name := range []string{"a", "b"} {
+			deployment := &appsV1.Deployment{}
+			if err := fakeClient.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, deployment); err != nil {
+				t.Fatal(err)
+			}
+			if len(deployment.Spec.Template.Spec.Containers[0].Env) > 0 {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+
+	// Act
+	if _, err := reconciler.Reconcile(context.Background(), request); err != nil {
+		t.Fatal(err)
+	}
+	clock.now = now.Add(10 * time.Second)
+	result, err := reconciler.Reconcile(context.Background(), request)
+
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"a"}, updatedDeployments()); diff != "" {
+		t.Errorf("updated deployments mismatch during the pause (-expected +actual):\n%s", diff)
+	}
+	if result.RequeueAfter != 50*time.Second {
+		t.Errorf("expected requeue after the remaining pause, got %s", result.RequeueAfter)
+	}
+
+	// Act
+	clock.now = now.Add(2 * time.Minute)
+	if _, err := reconciler.Reconcile(context.Background(), request); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	if diff := cmp.Diff([]string{"a", "b"}, updatedDeployments()); diff != "" {
+		t.Errorf("updated deployments mismatch after the pause (-expected +actual):\n%s", diff)
+	}
+	updated := &extensionsv1.AuthorizationModel{}
+	if err := fakeClient.Get(context.Background(), request.NamespacedName, updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status.Rollout == nil || updated.Status.Rollout.Batches != 2 {
+		t.Errorf("expected the rollout to continue in a second batch, got %v", updated.Status.Rollout)
+	}
+}
@@ -0,0 +1,122 @@
+package authorizationmodel
+
+import (
+	extensionsv1 "fga-operator/api/v1"
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+	"time"
+)
+
+const (
+	conditionReasonSynchronized       = "Synchronized"
+	conditionReasonUpdateFailed       = "WorkloadUpdateFailed"
+	conditionReasonRolloutProgressing = "RolloutProgressing"
+	conditionReasonRolloutHalted      = "RolloutHalted"
+)
+
+// boundWorkloads collects the status of the workloads bound to an authorization model during a reconciliation.
+type boundWorkloads map[WorkloadIdentifier]*extensionsv1.BoundWorkload
+
+// newBoundWorkloads must be called before the workloads are changed, such that the status reflects
+// what is currently in the cluster.
+func newBoundWorkloads(workloads []Workload) boundWorkloads {
+	bound := make(boundWorkloads, len(workloads))
+	for _, workload := range workloads {
+		status := boundWorkloadFrom(workload)
+		bound[workload.identifier()] = &status
+	}
+	return bound
+}
+
+func boundWorkloadFrom(workload Workload) extensionsv1.BoundWorkload {
+	return extensionsv1.BoundWorkload{
+		Kind:                 workload.Kind,
+		Name:                 workload.Object.GetName(),
+		Version:              workload.Object.GetAnnotations()[extensionsv1.OpenFgaAuthModelVersionLabel],
+		AuthorizationModelId: getWorkloadValue(workload, extensionsv1.OpenFgaAuthModelIdEnv),
+	}
+}
+
+// updated is called when the workload has been updated in the cluster.
+func (b boundWorkloads) updated(workload Workload) {
+	status := boundWorkloadFrom(workload)
+	b[workload.identifier()] = &status
+}
+
+func (b boundWorkloads) failed(workload Workload, err error) {
+	if status, ok := b[workload.identifier()]; ok {
+		status.Error = err.Error()
+	}
+}
+
+func (b boundWorkloads) sorted() []extensionsv1.BoundWorkload {
+	workloads := make([]extensionsv1.BoundWorkload, 0, len(b))
+	for _, status := range b {
+		workloads = append(workloads, *status)
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].Kind != workloads[j].Kind {
+			return workloads[i].Kind < workloads[j].Kind
+		}
+		return workloads[i].Name < workloads[j].Name
+	})
+	return workloads
+}
+
+func (b boundWorkloads) failures() int {
+	failures := 0
+	for _, status := range b {
+		if status.Error != "" {
+			failures++
+		}
+	}
+	return failures
+}
+
+// setWorkloadsStatus sets the status of the authorization model from the bound workloads and its rollout.
+// The last update time is only changed when any workload was updated.
+func setWorkloadsStatus(
+	authorizationModel *extensionsv1.AuthorizationModel,
+	bound boundWorkloads,
+	anyUpdated bool,
+	now time.Time) {
+	status := &authorizationModel.Status
+	status.Workloads = bound.sorted()
+	if anyUpdated {
+		status.LastUpdateTime = &metav1.Time{Time: now}
+	}
+
+	condition := metav1.Condition{
+		Type:               extensionsv1.ConditionWorkloadsSynced,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: authorizationModel.Generation,
+		LastTransitionTime: metav1.NewTime(now),
+		Reason:             conditionReasonSynchronized,
+	}
+	rollout := status.Rollout
+	switch failures := bound.failures(); {
+	case failures > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = conditionReasonUpdateFailed
+		condition.Message = fmt.Sprintf("%d of %d workloads failed to update", failures, len(bound))
+	case rollout != nil && rollout.Phase == extensionsv1.RolloutPhaseHalted:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = conditionReasonRolloutHalted
+		condition.Message = rollout.Message
+	case rollout != nil && rollout.Phase == extensionsv1.RolloutPhaseProgressing:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = conditionReasonRolloutProgressing
+		condition.Message = fmt.Sprintf("%d workloads pending, batch %d of the rollout is in progress", rollout.PendingWorkloads, rollout.Batches)
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+func getPodTemplateEnvVar(workload Workload, name string) string {
+	template := workload.PodTemplate()
+	if template == nil {
+		return ""
+	}
+	return workloadInjection(workload).EnvVar(&template.Spec, name)
+}
@@ -0,0 +1,96 @@
+package authorizationmodel
+
+import (
+	extensionsv1 "fga-operator/api/v1"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestSetWorkloadsStatus(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	current := createDeploymentWorkload("default", "current",
+		[]corev1.EnvVar{{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "id-1"}},
+		map[string]string{extensionsv1.OpenFgaAuthModelVersionLabel: "1.0.0"})
+	outdated := createDeploymentWorkload("default", "outdated",
+		[]corev1.EnvVar{{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "id-1"}},
+		map[string]string{extensionsv1.OpenFgaAuthModelVersionLabel: "1.0.0"})
+	failing := createDeploymentWorkload("default", "failing", []corev1.EnvVar{}, map[string]string{})
+
+	bound := newBoundWorkloads([]Workload{outdated, failing, current})
+	updatePodTemplateEnvVar(outdated.PodTemplate(), extensionsv1.OpenFgaAuthModelIdEnv, "id-2")
+	setAnnotation(outdated, extensionsv1.OpenFgaAuthModelVersionLabel, "2.0.0")
+	bound.updated(outdated)
+	bound.failed(failing, fmt.Errorf("update failed"))
+
+	authorizationModel := &extensionsv1.AuthorizationModel{}
+
+	// Act
+	setWorkloadsStatus(authorizationModel, bound, true, now)
+
+	// Assert
+	expected := []extensionsv1.BoundWorkload{
+		{Kind: DeploymentKind, Name: "current", Version: "1.0.0", AuthorizationModelId: "id-1"},
+		{Kind: DeploymentKind, Name: "failing", Error: "update failed"},
+		{Kind: DeploymentKind, Name: "outdated", Version: "2.0.0", AuthorizationModelId: "id-2"},
+	}
+	if diff := cmp.Diff(expected, authorizationModel.Status.Workloads); diff != "" {
+		t.Errorf("workloads mismatch (-expected +actual):\n%s", diff)
+	}
+	if diff := cmp.Diff(&metav1.Time{Time: now}, authorizationModel.Status.LastUpdateTime); diff != "" {
+		t.Errorf("last update time mismatch (-expected +actual):\n%s", diff)
+	}
+	condition := meta.FindStatusCondition(authorizationModel.Status.Conditions, extensionsv1.ConditionWorkloadsSynced)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Message != "1 of 3 workloads failed to update" {
+		t.Errorf("unexpected condition %v", condition)
+	}
+}
+
+func TestSetWorkloadsStatusWithoutUpdatesKeepsLastUpdateTime(t *testing.T) {
+	// Arrange
+	lastUpdate := metav1.NewTime(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	authorizationModel := &extensionsv1.AuthorizationModel{
+		Status: extensionsv1.AuthorizationModelStatus{LastUpdateTime: &lastUpdate},
+	}
+	bound := newBoundWorkloads([]Workload{
+		createDeploymentWorkload("default", "deployment", []corev1.EnvVar{}, map[string]string{}),
+	})
+
+	// Act
+	setWorkloadsStatus(authorizationModel, bound, false, time.Now())
+
+	// Assert
+	if diff := cmp.Diff(&lastUpdate, authorizationModel.Status.LastUpdateTime); diff != "" {
+		t.Errorf("last update time mismatch (-expected +actual):\n%s", diff)
+	}
+	if !meta.IsStatusConditionTrue(authorizationModel.Status.Conditions, extensionsv1.ConditionWorkloadsSynced) {
+		t.Errorf("expected condition %s to be true", extensionsv1.ConditionWorkloadsSynced)
+	}
+}
+
+func TestSetWorkloadsStatusWithHaltedRollout(t *testing.T) {
+	// Arrange
+	authorizationModel := &extensionsv1.AuthorizationModel{
+		Status: extensionsv1.AuthorizationModelStatus{
+			Rollout: &extensionsv1.RolloutStatus{Phase: extensionsv1.RolloutPhaseHalted, Message: "Deployments a didn't become available within 10m0s"},
+		},
+	}
+	bound := newBoundWorkloads([]Workload{
+		createDeploymentWorkload("default", "a", []corev1.EnvVar{}, map[string]string{}),
+	})
+
+	// Act
+	setWorkloadsStatus(authorizationModel, bound, false, time.Now())
+
+	// Assert
+	condition := meta.FindStatusCondition(authorizationModel.Status.Conditions, extensionsv1.ConditionWorkloadsSynced)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != conditionReasonRolloutHalted ||
+		condition.Message != "Deployments a didn't become available within 10m0s" {
+		t.Errorf("unexpected condition %v", condition)
+	}
+}
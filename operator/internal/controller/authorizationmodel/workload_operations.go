@@ -0,0 +1,103 @@
+package authorizationmodel
+
+import (
+	extensionsv1 "fga-operator/api/v1"
+	"fga-operator/internal/injection"
+	"fga-operator/internal/interfaces"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"time"
+)
+
+func updateStoreIdOnWorkloads(
+	workloads []Workload,
+	store *extensionsv1.Store,
+	reconcileTimestamp time.Time,
+) map[WorkloadIdentifier]Workload {
+	updates := map[WorkloadIdentifier]Workload{}
+	for _, workload := range workloads {
+		if setWorkloadValue(workload, extensionsv1.OpenFgaStoreIdEnv, store.Spec.Id) {
+			setAnnotation(workload, extensionsv1.OpenFgaStoreIdUpdatedAtAnnotation, reconcileTimestamp.UTC().Format(time.RFC3339))
+
+			updates[workload.identifier()] = workload
+		}
+		if store.Status.ApiUrl != "" && setWorkloadValue(workload, extensionsv1.OpenFgaApiUrlEnv, store.Status.ApiUrl) {
+			updates[workload.identifier()] = workload
+		}
+	}
+
+	return updates
+}
+
+type updateAuthorizationModelIdFailure struct {
+	workload Workload
+	err      error
+}
+
+// updateAuthorizationModelIdOnWorkloads updates the Authorization Model ID for each workload in the list.
+//
+// It modifies the `currentUpdated` map in place, updating workloads with new environment variables
+// and annotations. If a workload is already in `currentUpdated`, it will be replaced with its updated version.
+//
+// Parameters:
+//   - `workloads`: List of Kubernetes workloads to update.
+//   - `currentUpdated`: Map of workloads being updated (mutated in place).
+//   - `authorizationModel`: Interface for fetching the authorization model version.
+//   - `reconcileTimestamp`: Timestamp for workload annotations.
+//   - `log`: Logger for error and info logging.
+//
+// Returns:
+//   - `[]updateAuthorizationModelIdFailure`: Contains updated workload failures.
+//
+// Note: `currentUpdated` is mutated directly.
+func updateAuthorizationModelIdOnWorkloads(
+	workloads []Workload,
+	currentUpdated map[WorkloadIdentifier]Workload,
+	authorizationModel interfaces.AuthorizationModelInterface,
+	reconcileTimestamp time.Time,
+	log *logr.Logger,
+) []updateAuthorizationModelIdFailure {
+	errors := make([]updateAuthorizationModelIdFailure, 0)
+
+	for _, workload := range workloads {
+		authInstance, err := authorizationModel.GetVersionFromObject(workload.Object)
+		workloadIdentifier := workload.identifier()
+
+		if err != nil {
+			errors = append(errors, updateAuthorizationModelIdFailure{workload: workload, err: err})
+			log.Error(err, "unable to get auth instance from workload", "kind", workload.Kind, "workloadName", workload.Object.GetName())
+			continue
+		}
+
+		if updatedWorkload, ok := currentUpdated[workloadIdentifier]; ok {
+			workload = updatedWorkload
+		}
+
+		updatedId := setWorkloadValue(workload, extensionsv1.OpenFgaAuthModelIdEnv, authInstance.Id)
+		updatedVersion := setWorkloadValue(workload, extensionsv1.OpenFgaAuthModelVersionEnv, authInstance.Version.String())
+		if !updatedId && !updatedVersion {
+			log.V(1).Info("workload had correct auth id", "kind", workload.Kind, "authInstance", authInstance)
+			continue
+		}
+
+		setAnnotation(workload, extensionsv1.OpenFgaAuthIdUpdatedAtAnnotation, reconcileTimestamp.UTC().Format(time.RFC3339))
+		setAnnotation(workload, extensionsv1.OpenFgaAuthModelVersionLabel, authInstance.Version.String())
+
+		currentUpdated[workloadIdentifier] = workload
+	}
+
+	return errors
+}
+
+func setAnnotation(workload Workload, key, value string) {
+	annotations := workload.Object.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[key] = value
+	workload.Object.SetAnnotations(annotations)
+}
+
+func updatePodTemplateEnvVar(template *corev1.PodTemplateSpec, envVarName, envVarValue string) bool {
+	return injection.Injection{}.Set(&template.Spec, corev1.EnvVar{Name: envVarName, Value: envVarValue})
+}
@@ -0,0 +1,548 @@
+package authorizationmodel
+
+import (
+	"context"
+	"errors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"testing"
+
+	extensionsv1 "fga-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	appsV1 "k8s.io/api/apps/v1"
+	batchV1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type MockAuthorizationModel struct{}
+
+func (m *MockAuthorizationModel) GetVersionFromObject(object metav1.Object) (extensionsv1.AuthorizationModelInstance, error) {
+	if object.GetName() == "error-deployment" {
+		return extensionsv1.AuthorizationModelInstance{}, errors.New("mock error")
+	}
+	return extensionsv1.AuthorizationModelInstance{
+		Id: "auth-model-id",
+		Version: extensionsv1.ModelVersion{
+			Major: 1,
+			Minor: 2,
+			Patch: 3,
+		},
+	}, nil
+}
+
+// newFakeClient returns a fake client holding the given objects, which serves the workload index and the status
+// of authorization models like the manager does.
+func newFakeClient(t *testing.T, objects ...client.Object) client.WithWatch {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := extensionsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	builder := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(&extensionsv1.AuthorizationModel{})
+	for _, kind := range workloadKinds {
+		builder = builder.WithIndex(kind.newObject(), workloadIndexKey, indexWorkloadByStoreLabel)
+	}
+	return builder.Build()
+}
+
+func createDeployment(envVars []corev1.EnvVar) *appsV1.Deployment {
+	return &appsV1.Deployment{
+		Spec: appsV1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "test-container",
+							Env:  envVars,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func createDeploymentWithNameAndAnnotations(namespace, name string, envVars []corev1.EnvVar, annotations map[string]string) appsV1.Deployment {
+	return appsV1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Annotations: annotations,
+		},
+		Spec: appsV1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "test-container",
+							Env:  envVars,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func createDeploymentWorkload(namespace, name string, envVars []corev1.EnvVar, annotations map[string]string) Workload {
+	deployment := createDeploymentWithNameAndAnnotations(namespace, name, envVars, annotations)
+	return newWorkload(DeploymentKind, &deployment)
+}
+
+func TestUpdateStoreIdOnWorkloads(t *testing.T) {
+	store := &extensionsv1.Store{
+		Spec: extensionsv1.StoreSpec{
+			Id: "store-id",
+		},
+	}
+	reconcileTimestamp := time.Now()
+	reconcileTimestampFormatted := reconcileTimestamp.UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name               string
+		workloads          []Workload
+		store              *extensionsv1.Store
+		reconcileTimestamp time.Time
+		expectedUpdates    map[WorkloadIdentifier]Workload
+	}{
+		{
+			name:               "No deployments",
+			workloads:          []Workload{},
+			store:              store,
+			reconcileTimestamp: reconcileTimestamp,
+			expectedUpdates:    map[WorkloadIdentifier]Workload{},
+		},
+		{
+			name: "Deployment without the target env var",
+			workloads: []Workload{
+				createDeploymentWorkload("namespace1", "deployment1", []corev1.EnvVar{}, map[string]string{}),
+			},
+			store:              store,
+			reconcileTimestamp: reconcileTimestamp,
+			expectedUpdates: map[WorkloadIdentifier]Workload{
+				{kind: DeploymentKind, namespace: "namespace1", name: "deployment1"}: createDeploymentWorkload(
+					"namespace1",
+					"deployment1",
+					[]corev1.EnvVar{
+						{Name: extensionsv1.OpenFgaStoreIdEnv, Value: "store-id"},
+					},
+					map[string]string{
+						extensionsv1.OpenFgaStoreIdUpdatedAtAnnotation: reconcileTimestampFormatted,
+					},
+				),
+			},
+		},
+		{
+			name: "Deployment with the target env var but different value",
+			workloads: []Workload{
+				createDeploymentWorkload(
+					"namespace1",
+					"deployment1",
+					[]corev1.EnvVar{
+						{Name: extensionsv1.OpenFgaStoreIdEnv, Value: "old-value"},
+					},
+					map[string]string{},
+				),
+			},
+			store:              store,
+			reconcileTimestamp: reconcileTimestamp,
+			expectedUpdates: map[WorkloadIdentifier]Workload{
+				{kind: DeploymentKind, namespace: "namespace1", name: "deployment1"}: createDeploymentWorkload(
+					"namespace1",
+					"deployment1",
+					[]corev1.EnvVar{
+						{Name: extensionsv1.OpenFgaStoreIdEnv, Value: "store-id"},
+					},
+					map[string]string{
+						extensionsv1.OpenFgaStoreIdUpdatedAtAnnotation: reconcileTimestampFormatted,
+					},
+				),
+			},
+		},
+		{
+			name: "Deployment with the target env var already set to correct value",
+			workloads: []Workload{
+				createDeploymentWorkload(
+					"namespace1",
+					"deployment1",
+					[]corev1.EnvVar{
+						{Name: extensionsv1.OpenFgaStoreIdEnv, Value: "store-id"},
+					},
+					map[string]string{},
+				),
+			},
+			store:              store,
+			reconcileTimestamp: reconcileTimestamp,
+			expectedUpdates:    map[WorkloadIdentifier]Workload{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates := updateStoreIdOnWorkloads(tt.workloads, tt.store, tt.reconcileTimestamp)
+
+			if diff := cmp.Diff(tt.expectedUpdates, updates); diff != "" {
+				t.Errorf("unexpected updates (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdatePodTemplateEnvVar(t *testing.T) {
+	tests := []struct {
+		name           string
+		initialEnvVars []corev1.EnvVar
+		envVarName     string
+		envVarValue    string
+		expectedEnv    []corev1.EnvVar
+		expectedUpdate bool
+	}{
+		{
+			name:           "EnvVar does not exist and should be added",
+			initialEnvVars: []corev1.EnvVar{},
+			envVarName:     "NEW_VAR",
+			envVarValue:    "new_value",
+			expectedEnv: []corev1.EnvVar{
+				{Name: "NEW_VAR", Value: "new_value"},
+			},
+			expectedUpdate: true,
+		},
+		{
+			name: "EnvVar exists with the same value and should not be updated",
+			initialEnvVars: []corev1.EnvVar{
+				{Name: "EXISTING_VAR", Value: "existing_value"},
+			},
+			envVarName:  "EXISTING_VAR",
+			envVarValue: "existing_value",
+			expectedEnv: []corev1.EnvVar{
+				{Name: "EXISTING_VAR", Value: "existing_value"},
+			},
+			expectedUpdate: false,
+		},
+		{
+			name: "EnvVar exists with a different value and should be updated",
+			initialEnvVars: []corev1.EnvVar{
+				{Name: "EXISTING_VAR", Value: "old_value"},
+			},
+			envVarName:  "EXISTING_VAR",
+			envVarValue: "new_value",
+			expectedEnv: []corev1.EnvVar{
+				{Name: "EXISTING_VAR", Value: "new_value"},
+			},
+			expectedUpdate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := createDeployment(tt.initialEnvVars)
+			updated := updatePodTemplateEnvVar(&deployment.Spec.Template, tt.envVarName, tt.envVarValue)
+
+			if updated != tt.expectedUpdate {
+				t.Errorf("expected update status to be %v, but got %v", tt.expectedUpdate, updated)
+			}
+			if diff := cmp.Diff(tt.expectedEnv, deployment.Spec.Template.Spec.Containers[0].Env); diff != "" {
+				t.Errorf("deployment env mismatch (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdateAuthorizationModelIdOnWorkloads(t *testing.T) {
+	reconcileTimestamp := time.Now()
+	reconcileTimestampFormatted := reconcileTimestamp.UTC().Format(time.RFC3339)
+	logger := log.FromContext(context.Background())
+
+	tests := []struct {
+		name                 string
+		workloads            []Workload
+		updates              map[WorkloadIdentifier]Workload
+		authorizationModel   *MockAuthorizationModel
+		expectedUpdates      map[WorkloadIdentifier]Workload
+		expectedFailureCount int
+	}{
+		{
+			name:                 "No deployments",
+			workloads:            []Workload{},
+			updates:              map[WorkloadIdentifier]Workload{},
+			authorizationModel:   &MockAuthorizationModel{},
+			expectedUpdates:      map[WorkloadIdentifier]Workload{},
+			expectedFailureCount: 0,
+		},
+		{
+			name: "Deployment with error in GetVersionFromObject",
+			workloads: []Workload{
+				createDeploymentWorkload("namespace1", "error-deployment", []corev1.EnvVar{}, map[string]string{}),
+			},
+			updates:              map[WorkloadIdentifier]Workload{},
+			authorizationModel:   &MockAuthorizationModel{},
+			expectedUpdates:      map[WorkloadIdentifier]Workload{},
+			expectedFailureCount: 1,
+		},
+		{
+			name: "Deployment without the target env var",
+			workloads: []Workload{
+				createDeploymentWorkload("namespace1", "deployment1", []corev1.EnvVar{}, map[string]string{}),
+			},
+			updates:            map[WorkloadIdentifier]Workload{},
+			authorizationModel: &MockAuthorizationModel{},
+			expectedUpdates: map[WorkloadIdentifier]Workload{
+				{kind: DeploymentKind, namespace: "namespace1", name: "deployment1"}: createDeploymentWorkload(
+					"namespace1",
+					"deployment1",
+					[]corev1.EnvVar{
+						{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "auth-model-id"},
+					},
+					map[string]string{
+						extensionsv1.OpenFgaAuthIdUpdatedAtAnnotation: reconcileTimestampFormatted,
+						extensionsv1.OpenFgaAuthModelVersionLabel:     "1.2.3",
+					},
+				),
+			},
+			expectedFailureCount: 0,
+		},
+		{
+			name: "Deployment with the target env var already set to correct value",
+			workloads: []Workload{
+				createDeploymentWorkload(
+					"namespace1",
+					"deployment1",
+					[]corev1.EnvVar{
+						{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "auth-model-id"},
+					},
+					map[string]string{},
+				),
+			},
+			updates:              map[WorkloadIdentifier]Workload{},
+			authorizationModel:   &MockAuthorizationModel{},
+			expectedUpdates:      map[WorkloadIdentifier]Workload{},
+			expectedFailureCount: 0,
+		},
+		{
+			name: "Pre-existing updates map with a deployment",
+			workloads: []Workload{
+				createDeploymentWorkload("namespace1", "deployment1", []corev1.EnvVar{}, map[string]string{}),
+			},
+			updates: map[WorkloadIdentifier]Workload{
+				{kind: DeploymentKind, namespace: "namespace1", name: "existing-deployment"}: createDeploymentWorkload(
+					"namespace1",
+					"existing-deployment",
+					[]corev1.EnvVar{
+						{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "existing-value"},
+					},
+					map[string]string{
+						extensionsv1.OpenFgaAuthIdUpdatedAtAnnotation: "existing-timestamp",
+					},
+				),
+			},
+			authorizationModel: &MockAuthorizationModel{},
+			expectedUpdates: map[WorkloadIdentifier]Workload{
+				{kind: DeploymentKind, namespace: "namespace1", name: "existing-deployment"}: createDeploymentWorkload(
+					"namespace1",
+					"existing-deployment",
+					[]corev1.EnvVar{
+						{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "existing-value"},
+					},
+					map[string]string{
+						extensionsv1.OpenFgaAuthIdUpdatedAtAnnotation: "existing-timestamp",
+					},
+				),
+				{kind: DeploymentKind, namespace: "namespace1", name: "deployment1"}: createDeploymentWorkload(
+					"namespace1",
+					"deployment1",
+					[]corev1.EnvVar{
+						{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "auth-model-id"},
+					},
+					map[string]string{
+						extensionsv1.OpenFgaAuthIdUpdatedAtAnnotation: reconcileTimestampFormatted,
+						extensionsv1.OpenFgaAuthModelVersionLabel:     "1.2.3",
+					},
+				),
+			},
+			expectedFailureCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := updateAuthorizationModelIdOnWorkloads(tt.workloads, tt.updates, tt.authorizationModel, reconcileTimestamp, &logger)
+
+			if diff := cmp.Diff(tt.expectedUpdates, tt.updates); diff != "" {
+				t.Errorf("unexpected updates (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Equal(len(failures), tt.expectedFailureCount); diff != true {
+				t.Errorf("unexpected failure (-want +got):\n%d", len(failures))
+			}
+		})
+	}
+}
+
+func TestRemoveStaleEnvVarsAfterNarrowingContainers(t *testing.T) {
+	// Arrange
+	store := &extensionsv1.Store{Spec: extensionsv1.StoreSpec{Id: "store-id"}}
+	logger := log.FromContext(context.Background())
+	workload := createDeploymentWorkload("default", "deployment", nil, map[string]string{})
+	template := workload.PodTemplate()
+	template.Spec.Containers = append(template.Spec.Containers, corev1.Container{Name: "istio-proxy"})
+	workloads := []Workload{workload}
+	updates := updateStoreIdOnWorkloads(workloads, store, time.Now())
+	updateAuthorizationModelIdOnWorkloads(workloads, updates, &MockAuthorizationModel{}, time.Now(), &logger)
+	removeStaleEnvVars(workloads, updates)
+	setAnnotation(workload, extensionsv1.OpenFgaContainersAnnotation, "test-container")
+
+	// Act
+	updates = updateStoreIdOnWorkloads(workloads, store, time.Now())
+	removeStaleEnvVars(workloads, updates)
+
+	// Assert
+	if _, ok := updates[workload.identifier()]; !ok {
+		t.Errorf("expected the workload to be updated")
+	}
+	injected := []corev1.EnvVar{
+		{Name: extensionsv1.OpenFgaStoreIdEnv, Value: "store-id"},
+		{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "auth-model-id"},
+	}
+	if diff := cmp.Diff(injected, template.Spec.Containers[0].Env); diff != "" {
+		t.Errorf("env mismatch of targeted container (-expected +actual):\n%s", diff)
+	}
+	if env := template.Spec.Containers[1].Env; len(env) != 0 {
+		t.Errorf("expected no env on the container which is no longer targeted, got %v", env)
+	}
+	expected := `{"test-container":["OPENFGA_STORE_ID","OPENFGA_AUTH_MODEL_ID"]}`
+	if recorded := workload.Object.GetAnnotations()[extensionsv1.OpenFgaInjectedEnvAnnotation]; recorded != expected {
+		t.Errorf("expected recorded env %s, got %s", expected, recorded)
+	}
+}
+
+func TestUpdateStoreIdOnWorkloadsOfAllKinds(t *testing.T) {
+	store := &extensionsv1.Store{
+		Spec: extensionsv1.StoreSpec{
+			Id: "store-id",
+		},
+	}
+	template := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "test-container"}},
+		},
+	}
+	objectMeta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: "namespace1", Name: name}
+	}
+	workloads := []Workload{
+		newWorkload(StatefulSetKind, &appsV1.StatefulSet{ObjectMeta: objectMeta("stateful-set"), Spec: appsV1.StatefulSetSpec{Template: *template.DeepCopy()}}),
+		newWorkload(DaemonSetKind, &appsV1.DaemonSet{ObjectMeta: objectMeta("daemon-set"), Spec: appsV1.DaemonSetSpec{Template: *template.DeepCopy()}}),
+		newWorkload(JobKind, &batchV1.Job{ObjectMeta: objectMeta("job"), Spec: batchV1.JobSpec{Template: *template.DeepCopy()}}),
+		newWorkload(CronJobKind, &batchV1.CronJob{ObjectMeta: objectMeta("cron-job"), Spec: batchV1.CronJobSpec{
+			JobTemplate: batchV1.JobTemplateSpec{Spec: batchV1.JobSpec{Template: *template.DeepCopy()}},
+		}}),
+	}
+
+	updates := updateStoreIdOnWorkloads(workloads, store, time.Now())
+
+	if len(updates) != len(workloads) {
+		t.Fatalf("expected %d updates, got %d", len(workloads), len(updates))
+	}
+	for _, workload := range workloads {
+		updated, ok := updates[workload.identifier()]
+		if !ok {
+			t.Fatalf("expected %s %s to be updated", workload.Kind, workload.Object.GetName())
+		}
+		expectedEnv := []corev1.EnvVar{{Name: extensionsv1.OpenFgaStoreIdEnv, Value: "store-id"}}
+		if diff := cmp.Diff(expectedEnv, updated.PodTemplate().Spec.Containers[0].Env); diff != "" {
+			t.Errorf("%s env mismatch (-expected +got):\n%s", workload.Kind, diff)
+		}
+		if _, ok := updated.Object.GetAnnotations()[extensionsv1.OpenFgaStoreIdUpdatedAtAnnotation]; !ok {
+			t.Errorf("%s is missing annotation %s", workload.Kind, extensionsv1.OpenFgaStoreIdUpdatedAtAnnotation)
+		}
+	}
+}
+
+func TestWorkloadsFromListSkipsJobsOwnedByCronJob(t *testing.T) {
+	isController := true
+	jobs := &batchV1.JobList{
+		Items: []batchV1.Job{
+			{ObjectMeta: metav1.ObjectMeta{Name: "standalone"}},
+			{ObjectMeta: metav1.ObjectMeta{
+				Name: "scheduled",
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: CronJobKind, Name: "cron-job", Controller: &isController},
+				},
+			}},
+		},
+	}
+
+	workloads, err := workloadsFromList(JobKind, jobs)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(workloads) != 1 || workloads[0].Object.GetName() != "standalone" {
+		t.Errorf("expected only the standalone job, got %v", workloads)
+	}
+}
+
+func TestUpdateWorkloadWarnsOnceForImmutableTemplate(t *testing.T) {
+	// Arrange
+	job := &batchV1.Job{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "job"},
+		Spec: batchV1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app"}},
+		}}},
+	}
+	fakeClient := newFakeClient(t, job)
+	recorder := record.NewFakeRecorder(5)
+	reconciler := &AuthorizationModelReconciler{Client: fakeClient, Scheme: fakeClient.Scheme(), Recorder: recorder}
+	logger := log.Log
+	outdatedJob := func(storeId string) Workload {
+		current := &batchV1.Job{}
+		if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(job), current); err != nil {
+			t.Fatal(err)
+		}
+		current.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: extensionsv1.OpenFgaStoreIdEnv, Value: storeId}}
+		return newWorkload(JobKind, current)
+	}
+
+	for _, storeId := range []string{"store-id", "store-id", "other-store-id"} {
+		// Act
+		updated, err := reconciler.updateWorkload(context.Background(), outdatedJob(storeId), "model", &logger)
+
+		// Assert
+		if err != nil || updated {
+			t.Fatalf("expected the job to be skipped, got updated %v and error %v", updated, err)
+		}
+	}
+	if events := len(recorder.Events); events != 2 {
+		t.Errorf("expected an event per outdated pod template, got %d events", events)
+	}
+}
+
+func TestUpdateWorkloadFailsForUnknownKind(t *testing.T) {
+	// Arrange
+	reconciler := &AuthorizationModelReconciler{Recorder: record.NewFakeRecorder(5)}
+	logger := log.Log
+	workload := newWorkload("ReplicaSet", &appsV1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "replica-set"}})
+
+	// Act
+	updated, err := reconciler.updateWorkload(context.Background(), workload, "model", &logger)
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected an error for an unknown workload kind")
+	}
+	if updated {
+		t.Error("expected the workload not to be updated")
+	}
+}
@@ -0,0 +1,155 @@
+package authorizationmodel
+
+import (
+	extensionsv1 "fga-operator/api/v1"
+	appsV1 "k8s.io/api/apps/v1"
+	batchV1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	DeploymentKind  = "Deployment"
+	StatefulSetKind = "StatefulSet"
+	DaemonSetKind   = "DaemonSet"
+	JobKind         = "Job"
+	CronJobKind     = "CronJob"
+)
+
+// workloadKind describes a kind of pod template bearing resource which can be bound to a store
+// using the `openfga-store` label.
+type workloadKind struct {
+	kind                    string
+	newObject               func() client.Object
+	newList                 func() client.ObjectList
+	eventReasonListFailed   EventReason
+	eventReasonUpdateFailed EventReason
+	// immutableTemplate is set when Kubernetes rejects changes to the pod template after creation.
+	immutableTemplate bool
+}
+
+var workloadKinds = []workloadKind{
+	{
+		kind:                    DeploymentKind,
+		newObject:               func() client.Object { return &appsV1.Deployment{} },
+		newList:                 func() client.ObjectList { return &appsV1.DeploymentList{} },
+		eventReasonListFailed:   EventReasonFailedListingDeployments,
+		eventReasonUpdateFailed: EventReasonFailedUpdatingDeployment,
+	},
+	{
+		kind:                    StatefulSetKind,
+		newObject:               func() client.Object { return &appsV1.StatefulSet{} },
+		newList:                 func() client.ObjectList { return &appsV1.StatefulSetList{} },
+		eventReasonListFailed:   EventReasonFailedListingStatefulSets,
+		eventReasonUpdateFailed: EventReasonFailedUpdatingStatefulSet,
+	},
+	{
+		kind:                    DaemonSetKind,
+		newObject:               func() client.Object { return &appsV1.DaemonSet{} },
+		newList:                 func() client.ObjectList { return &appsV1.DaemonSetList{} },
+		eventReasonListFailed:   EventReasonFailedListingDaemonSets,
+		eventReasonUpdateFailed: EventReasonFailedUpdatingDaemonSet,
+	},
+	{
+		kind:                    JobKind,
+		newObject:               func() client.Object { return &batchV1.Job{} },
+		newList:                 func() client.ObjectList { return &batchV1.JobList{} },
+		eventReasonListFailed:   EventReasonFailedListingJobs,
+		eventReasonUpdateFailed: EventReasonFailedUpdatingJob,
+		immutableTemplate:       true,
+	},
+	{
+		kind:                    CronJobKind,
+		newObject:               func() client.Object { return &batchV1.CronJob{} },
+		newList:                 func() client.ObjectList { return &batchV1.CronJobList{} },
+		eventReasonListFailed:   EventReasonFailedListingCronJobs,
+		eventReasonUpdateFailed: EventReasonFailedUpdatingCronJob,
+	},
+}
+
+// getWorkloadKind returns the workload kind with the given name, and false when the kind isn't supported.
+func getWorkloadKind(kind string) (workloadKind, bool) {
+	for _, workloadKind := range workloadKinds {
+		if workloadKind.kind == kind {
+			return workloadKind, true
+		}
+	}
+	return workloadKind{}, false
+}
+
+// Workload wraps a Kubernetes resource which carries a pod template, like a Deployment or a CronJob.
+type Workload struct {
+	Kind   string
+	Object client.Object
+	// ConfigMap the IDs are delivered in, which is only set for workloads with the ConfigMap delivery.
+	ConfigMap *corev1.ConfigMap
+}
+
+type WorkloadIdentifier struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+func newWorkload(kind string, object client.Object) Workload {
+	return Workload{Kind: kind, Object: object}
+}
+
+func (w Workload) identifier() WorkloadIdentifier {
+	return WorkloadIdentifier{kind: w.Kind, namespace: w.Object.GetNamespace(), name: w.Object.GetName()}
+}
+
+// PodTemplate returns the pod template of the workload, which is where environment variables are injected.
+func (w Workload) PodTemplate() *corev1.PodTemplateSpec {
+	switch object := w.Object.(type) {
+	case *appsV1.Deployment:
+		return &object.Spec.Template
+	case *appsV1.StatefulSet:
+		return &object.Spec.Template
+	case *appsV1.DaemonSet:
+		return &object.Spec.Template
+	case *batchV1.Job:
+		return &object.Spec.Template
+	case *batchV1.CronJob:
+		return &object.Spec.JobTemplate.Spec.Template
+	default:
+		return nil
+	}
+}
+
+// isOwnedByCronJob returns true for Jobs spawned by a CronJob, since these get their
+// environment variables from the template of the CronJob.
+func (w Workload) isOwnedByCronJob() bool {
+	owner := metav1.GetControllerOf(w.Object)
+	return owner != nil && owner.Kind == CronJobKind
+}
+
+func workloadsFromList(kind string, list client.ObjectList) ([]Workload, error) {
+	objects, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	workloads := make([]Workload, 0, len(objects))
+	for _, object := range objects {
+		clientObject, ok := object.(client.Object)
+		if !ok {
+			continue
+		}
+		workload := newWorkload(kind, clientObject)
+		if workload.isOwnedByCronJob() {
+			continue
+		}
+		workloads = append(workloads, workload)
+	}
+	return workloads, nil
+}
+
+func indexWorkloadByStoreLabel(rawObj client.Object) []string {
+	labelValue, exists := rawObj.GetLabels()[extensionsv1.OpenFgaStoreLabel]
+	if !exists {
+		return nil
+	}
+	return []string{labelValue}
+}
@@ -0,0 +1,194 @@
+package authorizationmodelrequest
+
+import (
+	"context"
+	extensionsv1 "fga-operator/api/v1"
+	"fga-operator/internal/openfga"
+	"fmt"
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/yaml"
+	"slices"
+	"sort"
+)
+
+// maxFailedAssertions is the number of failed assertions kept in the status of a version, such that it
+// doesn't grow with the number of assertions.
+const maxFailedAssertions = 20
+
+// assertionsError is returned when assertions of an authorization model fail.
+type assertionsError struct {
+	failed []string
+	total  int
+}
+
+func (e *assertionsError) Error() string {
+	return fmt.Sprintf("%d of %d assertions failed, first: %s", len(e.failed), e.total, e.failed[0])
+}
+
+// verifyAssertions runs the assertions of the instance against the authorization model, and writes its
+// check assertions to OpenFGA when requested once all of them passed. Failing assertions are returned as
+// a permanent error, since they fail until the request is changed.
+func verifyAssertions(
+	ctx context.Context,
+	storeService openfga.StoreService,
+	authorizationModelId string,
+	instance extensionsv1.AuthorizationModelRequestInstance,
+	log *logr.Logger) error {
+	if instance.Assertions == nil {
+		return nil
+	}
+	if err := runAssertions(ctx, storeService, authorizationModelId, instance.Assertions); err != nil {
+		return err
+	}
+	log.V(0).Info("Assertions of authorization model passed",
+		"version", instance.Version.String(),
+		"authModelId", authorizationModelId)
+	if !instance.WriteAssertions {
+		return nil
+	}
+	return storeService.WriteAssertions(ctx, authorizationModelId, checkAssertions(instance.Assertions), log)
+}
+
+// runAssertions runs every assertion, with the tuples of the test sent as contextual tuples. Assertions
+// which OpenFGA rejects, e.g. because their relation isn't in the authorization model, fail. A transient
+// failure stops running the assertions and is returned.
+func runAssertions(
+	ctx context.Context,
+	storeService openfga.StoreService,
+	authorizationModelId string,
+	assertions *extensionsv1.AuthorizationModelAssertions) error {
+	var failed []string
+	total := 0
+	for i, test := range assertions.Tests {
+		name := testName(i, test)
+		contextualTuples := toOpenFgaTuples(append(slices.Clone(assertions.Tuples), test.Tuples...))
+
+		for _, check := range test.Check {
+			for _, relation := range sortedKeys(check.Assertions) {
+				total++
+				expected := check.Assertions[relation]
+				tuple := openfga.Tuple{User: check.User, Relation: relation, Object: check.Object}
+				allowed, err := storeService.Check(ctx, authorizationModelId, tuple, contextualTuples)
+				if err != nil && !openfga.IsPermanent(err) {
+					return err
+				}
+				description := fmt.Sprintf("%s: check %s %s %s", name, check.User, relation, check.Object)
+				switch {
+				case err != nil:
+					failed = append(failed, fmt.Sprintf("%s: %v", description, err))
+				case allowed != expected:
+					failed = append(failed, fmt.Sprintf("%s: expected %t, got %t", description, expected, allowed))
+				}
+			}
+		}
+
+		for _, listObjects := range test.ListObjects {
+			for _, relation := range sortedKeys(listObjects.Assertions) {
+				total++
+				expected := sorted(listObjects.Assertions[relation])
+				objects, err := storeService.ListObjects(ctx, authorizationModelId, listObjects.User, relation, listObjects.Type, contextualTuples)
+				if err != nil && !openfga.IsPermanent(err) {
+					return err
+				}
+				description := fmt.Sprintf("%s: list objects %s %s %s", name, listObjects.User, relation, listObjects.Type)
+				switch {
+				case err != nil:
+					failed = append(failed, fmt.Sprintf("%s: %v", description, err))
+				case !slices.Equal(expected, sorted(objects)):
+					failed = append(failed, fmt.Sprintf("%s: expected %v, got %v", description, expected, sorted(objects)))
+				}
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return openfga.Permanent(&assertionsError{failed: failed, total: total})
+	}
+	return nil
+}
+
+// checkAssertions returns the check assertions of all tests, which are the only assertions OpenFGA stores.
+func checkAssertions(assertions *extensionsv1.AuthorizationModelAssertions) []openfga.Assertion {
+	var result []openfga.Assertion
+	for _, test := range assertions.Tests {
+		for _, check := range test.Check {
+			for _, relation := range sortedKeys(check.Assertions) {
+				result = append(result, openfga.Assertion{
+					Tuple:       openfga.Tuple{User: check.User, Relation: relation, Object: check.Object},
+					Expectation: check.Assertions[relation],
+				})
+			}
+		}
+	}
+	return result
+}
+
+func testName(i int, test extensionsv1.AssertionTest) string {
+	if test.Name == "" {
+		return fmt.Sprintf("test %d", i+1)
+	}
+	return fmt.Sprintf("test %q", test.Name)
+}
+
+func sortedKeys[V any](assertions map[string]V) []string {
+	keys := make([]string, 0, len(assertions))
+	for key := range assertions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sorted(objects []string) []string {
+	result := slices.Clone(objects)
+	sort.Strings(result)
+	return result
+}
+
+func toOpenFgaTuples(tuples []extensionsv1.RelationshipTuple) []openfga.Tuple {
+	result := make([]openfga.Tuple, 0, len(tuples))
+	for _, tuple := range tuples {
+		result = append(result, openfga.Tuple{User: tuple.User, Relation: tuple.Relation, Object: tuple.Object})
+	}
+	return result
+}
+
+// testFile is the part of an OpenFGA test file (.fga.yaml) which holds the assertions.
+type testFile struct {
+	Tuples     []extensionsv1.RelationshipTuple `json:"tuples"`
+	TupleFile  string                           `json:"tuple_file"`
+	TupleFiles []string                         `json:"tuple_files"`
+	Tests      []struct {
+		Name        string                           `json:"name"`
+		Tuples      []extensionsv1.RelationshipTuple `json:"tuples"`
+		Check       []extensionsv1.CheckAssertion    `json:"check"`
+		ListObjects []struct {
+			User       string              `json:"user"`
+			Type       string              `json:"type"`
+			Assertions map[string][]string `json:"assertions"`
+		} `json:"list_objects"`
+	} `json:"tests"`
+}
+
+// parseTestFile returns the assertions of an OpenFGA test file. Tuple files can't be resolved, since the
+// test file is read from a config map, such that the tuples must be inlined.
+func parseTestFile(content string) (*extensionsv1.AuthorizationModelAssertions, error) {
+	file := testFile{}
+	if err := yaml.Unmarshal([]byte(content), &file); err != nil {
+		return nil, fmt.Errorf("invalid test file: %w", err)
+	}
+	if file.TupleFile != "" || len(file.TupleFiles) > 0 {
+		return nil, fmt.Errorf("tuple files of test files aren't supported, inline the tuples instead")
+	}
+	assertions := &extensionsv1.AuthorizationModelAssertions{
+		Tuples: file.Tuples,
+		Tests:  make([]extensionsv1.AssertionTest, 0, len(file.Tests)),
+	}
+	for _, test := range file.Tests {
+		assertionTest := extensionsv1.AssertionTest{Name: test.Name, Tuples: test.Tuples, Check: test.Check}
+		for _, listObjects := range test.ListObjects {
+			assertionTest.ListObjects = append(assertionTest.ListObjects, extensionsv1.ListObjectsAssertion(listObjects))
+		}
+		assertions.Tests = append(assertions.Tests, assertionTest)
+	}
+	return assertions, nil
+}
@@ -0,0 +1,237 @@
+package authorizationmodelrequest
+
+import (
+	"context"
+	"errors"
+	extensionsv1 "fga-operator/api/v1"
+	"fga-operator/internal/openfga"
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+// fakeAssertionsStoreService answers checks with the contextual tuples only, i.e. a user has a relation to an
+// object when the tuple is one of them. Relations other than viewer fail permanently like unknown relations.
+type fakeAssertionsStoreService struct {
+	openfga.StoreService
+	unavailable bool
+	assertions  []openfga.Assertion
+}
+
+func (f *fakeAssertionsStoreService) Check(_ context.Context, _ string, tuple openfga.Tuple, contextualTuples []openfga.Tuple) (bool, error) {
+	if f.unavailable {
+		return false, errors.New("unavailable")
+	}
+	if tuple.Relation != "viewer" {
+		return false, openfga.Permanent(errors.New("relation not found"))
+	}
+	for _, contextualTuple := range contextualTuples {
+		if contextualTuple == tuple {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeAssertionsStoreService) ListObjects(_ context.Context, _ string, user, relation, _ string, contextualTuples []openfga.Tuple) ([]string, error) {
+	var objects []string
+	for _, tuple := range contextualTuples {
+		if tuple.User == user && tuple.Relation == relation {
+			objects = append(objects, tuple.Object)
+		}
+	}
+	return objects, nil
+}
+
+func (f *fakeAssertionsStoreService) WriteAssertions(_ context.Context, _ string, assertions []openfga.Assertion, _ *logr.Logger) error {
+	f.assertions = assertions
+	return nil
+}
+
+func viewer(user, object string) extensionsv1.RelationshipTuple {
+	return extensionsv1.RelationshipTuple{User: user, Relation: "viewer", Object: object}
+}
+
+func TestRunAssertions(t *testing.T) {
+	testCases := []struct {
+		description string
+		test        extensionsv1.AssertionTest
+		expected    []string
+	}{
+		{
+			description: "passing assertions",
+			test: extensionsv1.AssertionTest{
+				Name:   "viewers",
+				Tuples: []extensionsv1.RelationshipTuple{viewer("user:bob", "document:b")},
+				Check: []extensionsv1.CheckAssertion{
+					{User: "user:anne", Object: "document:a", Assertions: map[string]bool{"viewer": true}},
+					{User: "user:anne", Object: "document:b", Assertions: map[string]bool{"viewer": false}},
+				},
+				ListObjects: []extensionsv1.ListObjectsAssertion{
+					{User: "user:bob", Type: "document", Assertions: map[string][]string{"viewer": {"document:b"}}},
+				},
+			},
+		},
+		{
+			description: "failing check",
+			test: extensionsv1.AssertionTest{
+				Name: "viewers",
+				Check: []extensionsv1.CheckAssertion{
+					{User: "user:bob", Object: "document:a", Assertions: map[string]bool{"viewer": true}},
+				},
+			},
+			expected: []string{`test "viewers": check user:bob viewer document:a: expected true, got false`},
+		},
+		{
+			description: "check rejected by OpenFGA",
+			test: extensionsv1.AssertionTest{
+				Check: []extensionsv1.CheckAssertion{
+					{User: "user:anne", Object: "document:a", Assertions: map[string]bool{"viewer": true, "editor": false}},
+				},
+			},
+			expected: []string{`test 1: check user:anne editor document:a: relation not found`},
+		},
+		{
+			description: "failing list objects",
+			test: extensionsv1.AssertionTest{
+				Name: "documents",
+				ListObjects: []extensionsv1.ListObjectsAssertion{
+					{User: "user:anne", Type: "document", Assertions: map[string][]string{"viewer": {"document:b", "document:a"}}},
+				},
+			},
+			expected: []string{`test "documents": list objects user:anne viewer document: expected [document:a document:b], got [document:a]`},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			assertions := &extensionsv1.AuthorizationModelAssertions{
+				Tuples: []extensionsv1.RelationshipTuple{viewer("user:anne", "document:a")},
+				Tests:  []extensionsv1.AssertionTest{testCase.test},
+			}
+
+			// Act
+			err := runAssertions(context.Background(), &fakeAssertionsStoreService{}, "model-id", assertions)
+
+			// Assert
+			var failed []string
+			var failedAssertions *assertionsError
+			if errors.As(err, &failedAssertions) {
+				failed = failedAssertions.failed
+				if !openfga.IsPermanent(err) {
+					t.Errorf("expected failing assertions to be permanent")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(testCase.expected, failed); diff != "" {
+				t.Errorf("unexpected failed assertions (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunAssertionsTransientFailure(t *testing.T) {
+	// Arrange
+	assertions := &extensionsv1.AuthorizationModelAssertions{
+		Tests: []extensionsv1.AssertionTest{{
+			Check: []extensionsv1.CheckAssertion{{User: "user:anne", Object: "document:a", Assertions: map[string]bool{"viewer": true}}},
+		}},
+	}
+
+	// Act
+	err := runAssertions(context.Background(), &fakeAssertionsStoreService{unavailable: true}, "model-id", assertions)
+
+	// Assert
+	if err == nil || openfga.IsPermanent(err) {
+		t.Errorf("expected a transient failure, got %v", err)
+	}
+}
+
+func TestVerifyAssertionsWritesCheckAssertions(t *testing.T) {
+	// Arrange
+	service := &fakeAssertionsStoreService{}
+	instance := extensionsv1.AuthorizationModelRequestInstance{
+		Assertions: &extensionsv1.AuthorizationModelAssertions{
+			Tuples: []extensionsv1.RelationshipTuple{viewer("user:anne", "document:a")},
+			Tests: []extensionsv1.AssertionTest{{
+				Check: []extensionsv1.CheckAssertion{{User: "user:anne", Object: "document:a", Assertions: map[string]bool{"viewer": true}}},
+			}},
+		},
+		WriteAssertions: true,
+	}
+
+	// Act
+	err := verifyAssertions(context.Background(), service, "model-id", instance, &logr.Logger{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []openfga.Assertion{{Tuple: openfga.Tuple{User: "user:anne", Relation: "viewer", Object: "document:a"}, Expectation: true}}
+	if diff := cmp.Diff(expected, service.assertions); diff != "" {
+		t.Errorf("unexpected written assertions (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseTestFile(t *testing.T) {
+	// Arrange
+	content := `
+name: documents
+model_file: ./model.fga
+tuples:
+  - user: user:anne
+    relation: viewer
+    object: document:a
+tests:
+  - name: viewers
+    tuples:
+      - user: user:bob
+        relation: viewer
+        object: document:b
+    check:
+      - user: user:anne
+        object: document:a
+        assertions:
+          viewer: true
+    list_objects:
+      - user: user:bob
+        type: document
+        assertions:
+          viewer:
+            - document:b
+`
+
+	// Act
+	assertions, err := parseTestFile(content)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &extensionsv1.AuthorizationModelAssertions{
+		Tuples: []extensionsv1.RelationshipTuple{viewer("user:anne", "document:a")},
+		Tests: []extensionsv1.AssertionTest{{
+			Name:   "viewers",
+			Tuples: []extensionsv1.RelationshipTuple{viewer("user:bob", "document:b")},
+			Check:  []extensionsv1.CheckAssertion{{User: "user:anne", Object: "document:a", Assertions: map[string]bool{"viewer": true}}},
+			ListObjects: []extensionsv1.ListObjectsAssertion{
+				{User: "user:bob", Type: "document", Assertions: map[string][]string{"viewer": {"document:b"}}},
+			},
+		}},
+	}
+	if diff := cmp.Diff(expected, assertions); diff != "" {
+		t.Errorf("unexpected assertions (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseTestFileWithTupleFile(t *testing.T) {
+	// Act
+	_, err := parseTestFile("tuple_file: ./tuples.yaml\ntests: []\n")
+
+	// Assert
+	if err == nil {
+		t.Error("expected tuple files to be rejected")
+	}
+}
@@ -18,17 +18,22 @@ package authorizationmodelrequest
 
 import (
 	"context"
+	"fga-operator/internal/connections"
 	"fga-operator/internal/observability"
 	"fga-operator/internal/openfga"
 	"fmt"
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"slices"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -51,6 +56,9 @@ const (
 	EventReasonStoreFailed                          EventReason = "StoreFailed"
 	EventReasonAuthorizationModelCreationFailed     EventReason = "AuthorizationModelCreationFailed"
 	EventReasonAuthorizationModelUpdateFailed       EventReason = "AuthorizationModelUpdateFailed"
+	EventReasonAuthorizationModelSourceFailed       EventReason = "AuthorizationModelSourceFailed"
+	EventReasonAuthorizationModelAssertionsFailed   EventReason = "AuthorizationModelAssertionsFailed"
+	EventReasonBreakingChangesDetected              EventReason = "BreakingChangesDetected"
 )
 
 // AuthorizationModelRequestReconciler reconciles a AuthorizationModelRequest object
@@ -61,6 +69,9 @@ type AuthorizationModelRequestReconciler struct {
 	openfga.PermissionServiceFactory
 	openfga.Config
 	Clock
+	// APIReader reads config maps and secrets referenced by requests directly from the API server,
+	// such that secrets aren't cached. Defaults to the client.
+	APIReader client.Reader
 }
 
 type Clock interface {
@@ -71,6 +82,9 @@ type Clock interface {
 //+kubebuilder:rbac:groups=extensions.fga-operator,resources=authorizationmodelrequests/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=extensions.fga-operator,resources=authorizationmodelrequests/finalizers,verbs=update
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get
+//+kubebuilder:rbac:groups=extensions.fga-operator,resources=fgaconnections;clusterfgaconnections,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -100,34 +114,60 @@ func (r *AuthorizationModelRequestReconciler) Reconcile(ctx context.Context, req
 		return ctrl.Result{}, err
 	}
 
-	openFgaService, err := r.PermissionServiceFactory.GetService(r.Config)
+	if err := resolveAuthorizationModels(ctx, r.apiReader(), authorizationRequest); err != nil {
+		err = r.failAuthorizationModelRequestSynchronization(ctx, authorizationRequest, EventReasonAuthorizationModelSourceFailed, err)
+		logger.Error(err, "unable to resolve authorization models")
+		return openfga.ReconcileResult(err)
+	}
+
+	if err := r.checkBreakingChanges(ctx, req, authorizationRequest, &logger); err != nil {
+		err = r.failAuthorizationModelRequestSynchronization(ctx, authorizationRequest, eventReason(err, EventReasonAuthorizationModelSourceFailed), err)
+		logger.Error(err, "unable to check authorization models for breaking changes")
+		return openfga.ReconcileResult(err)
+	}
+
+	openFgaService, err := r.getService(ctx, authorizationRequest)
 	if err != nil {
 		err = r.failAuthorizationModelRequestSynchronization(ctx, authorizationRequest, EventReasonClientInitializationFailed, err)
 		logger.Error(err, "unable to get permission service")
-		return ctrl.Result{}, err
+		return openfga.ReconcileResult(err)
 	}
 
-	err = r.ensureStoreExistsAndSetStoreId(ctx, req, openFgaService, authorizationRequest, &logger)
+	store, err := r.ensureStoreExists(ctx, req, openFgaService, authorizationRequest, &logger)
 	if err != nil {
 		err = r.failAuthorizationModelRequestSynchronization(ctx, authorizationRequest, EventReasonStoreFailed, err)
 		logger.Error(err, "unable to get store")
-		return ctrl.Result{}, err
+		return openfga.ReconcileResult(err)
 	}
+	authorizationRequest.Status.StoreId = store.Spec.Id
 
-	authorizationModel, err := r.getAuthorizationModel(ctx, req, openFgaService, authorizationRequest, reconcileTimestamp, &logger)
+	storeService, err := openFgaService.ForStore(store.Spec.Id)
 	if err != nil {
-		err = r.failAuthorizationModelRequestSynchronization(ctx, authorizationRequest, EventReasonAuthorizationModelCreationFailed, err)
+		err = r.failAuthorizationModelRequestSynchronization(ctx, authorizationRequest, EventReasonStoreFailed, err)
+		logger.Error(err, "unable to get store service")
+		return openfga.ReconcileResult(err)
+	}
+
+	authorizationModel, err := r.getAuthorizationModel(ctx, req, storeService, authorizationRequest, reconcileTimestamp, &logger)
+	if err != nil {
+		err = r.failAuthorizationModelRequestSynchronization(ctx, authorizationRequest, eventReason(err, EventReasonAuthorizationModelCreationFailed), err)
 		logger.Error(err, "unable to get authorization model")
-		return ctrl.Result{}, err
+		return openfga.ReconcileResult(err)
 	}
 
-	if err = r.updateAuthorizationModel(ctx, openFgaService, authorizationRequest, authorizationModel, reconcileTimestamp, &logger); err != nil {
-		err = r.failAuthorizationModelRequestSynchronization(ctx, authorizationRequest, EventReasonAuthorizationModelUpdateFailed, err)
+	if err = checkUnchangedVersions(authorizationRequest, authorizationModel); err != nil {
+		err = r.failAuthorizationModelRequestSynchronization(ctx, authorizationRequest, EventReasonAuthorizationModelSourceFailed, err)
+		logger.Error(err, "authorization model of existing version changed")
+		return openfga.ReconcileResult(err)
+	}
+
+	if err = r.updateAuthorizationModel(ctx, storeService, authorizationRequest, authorizationModel, reconcileTimestamp, &logger); err != nil {
+		err = r.failAuthorizationModelRequestSynchronization(ctx, authorizationRequest, eventReason(err, EventReasonAuthorizationModelUpdateFailed), err)
 		logger.Error(err, "unable to update authorization model")
-		return ctrl.Result{}, err
+		return openfga.ReconcileResult(err)
 	}
 
-	authorizationRequest.Status.State = extensionsv1.Synchronized
+	setSynchronizedStatus(authorizationRequest, authorizationModel, reconcileTimestamp)
 	if err := r.Status().Update(ctx, authorizationRequest); err != nil {
 		logger.Error(err, fmt.Sprintf("unable to set authorization model request in state %s", extensionsv1.Synchronized), "authorizationModelRequestName", req.Name)
 		r.Recorder.Event(
@@ -142,6 +182,24 @@ func (r *AuthorizationModelRequestReconciler) Reconcile(ctx context.Context, req
 	return ctrl.Result{}, nil
 }
 
+// getService returns the permission service for the connection of the request.
+func (r *AuthorizationModelRequestReconciler) getService(
+	ctx context.Context,
+	authorizationRequest *extensionsv1.AuthorizationModelRequest) (openfga.PermissionService, error) {
+	config, err := connections.Resolve(ctx, r.apiReader(), r.Config, authorizationRequest.Namespace, authorizationRequest.Spec.ConnectionRef)
+	if err != nil {
+		return nil, err
+	}
+	return r.PermissionServiceFactory.GetService(config)
+}
+
+func (r *AuthorizationModelRequestReconciler) apiReader() client.Reader {
+	if r.APIReader == nil {
+		return r.Client
+	}
+	return r.APIReader
+}
+
 func (r *AuthorizationModelRequestReconciler) failAuthorizationModelRequestSynchronization(ctx context.Context, authorizationRequest *extensionsv1.AuthorizationModelRequest, eventReason EventReason, err error) error {
 	r.Recorder.Event(
 		authorizationRequest,
@@ -149,16 +207,51 @@ func (r *AuthorizationModelRequestReconciler) failAuthorizationModelRequestSynch
 		string(eventReason),
 		err.Error(),
 	)
-	authorizationRequest.Status.State = extensionsv1.SynchronizationFailed
+	setFailedStatus(authorizationRequest, eventReason, err, r.Now())
 	if statusError := r.Status().Update(ctx, authorizationRequest); statusError != nil {
 		return fmt.Errorf("failed to update status: %w with prior error %v", statusError, err)
 	}
 	return err
 }
 
+// checkBreakingChanges checks the versions which aren't in the AuthorizationModel yet for breaking changes
+// to the previous version of their major version, which may only be stored in the AuthorizationModel. Depending on the breaking change policy, a version with
+// breaking changes is returned as a permanent error, or a warning event is emitted for it.
+func (r *AuthorizationModelRequestReconciler) checkBreakingChanges(
+	ctx context.Context,
+	req ctrl.Request,
+	authorizationModelRequest *extensionsv1.AuthorizationModelRequest,
+	log *logr.Logger) error {
+	policy := authorizationModelRequest.Spec.BreakingChangePolicy
+	if policy == extensionsv1.BreakingChangePolicyIgnore {
+		return nil
+	}
+
+	authorizationModel := &extensionsv1.AuthorizationModel{}
+	if err := r.Get(ctx, req.NamespacedName, authorizationModel); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	for _, found := range findBreakingChanges(authorizationModelRequest, authorizationModel.Spec.Instances) {
+		if policy == extensionsv1.BreakingChangePolicyReject {
+			return &versionError{version: found.version, err: openfga.Permanent(found.err)}
+		}
+		log.V(0).Info("New version contains breaking changes",
+			"authModel", authorizationModelRequest.Name,
+			"version", found.version.String(),
+			"changes", found.err.changes)
+		r.Recorder.Event(
+			authorizationModelRequest,
+			v1.EventTypeWarning,
+			string(EventReasonBreakingChangesDetected),
+			(&versionError{version: found.version, err: found.err}).Error(),
+		)
+	}
+	return nil
+}
+
 func updateAuthorizationModelWithMissingInstances(
 	ctx context.Context,
-	openFgaService openfga.PermissionService,
+	storeService openfga.StoreService,
 	authorizationModelRequest *extensionsv1.AuthorizationModelRequest,
 	authorizationModel *extensionsv1.AuthorizationModel,
 	reconcileTimestamp time.Time,
@@ -182,59 +275,101 @@ func updateAuthorizationModelWithMissingInstances(
 
 	modelInstances := authorizationModel.Spec.Instances
 	for _, modelRequestInstance := range missingInstances {
-		authModelId, err := getAuthorizationModelId(ctx, openFgaService, modelRequestInstance, authorizationModel.Name, log)
+		authModelId, origin, err := getVerifiedAuthorizationModelId(ctx, storeService, modelRequestInstance, authorizationModel.Name, log)
 		if err != nil {
-			return false, err
+			return false, &versionError{version: modelRequestInstance.Version, err: err}
 		}
 
 		log.V(0).Info(fmt.Sprintf("Authorization model resource will updates it's instances with id: %s", authModelId),
 			"authModel", authorizationModel.Name,
 			"version", modelRequestInstance.Version.String(),
 			"authModelId", authModelId)
-		modelInstances = append(modelInstances, extensionsv1.AuthorizationModelInstance{
-			Id:                 authModelId,
-			AuthorizationModel: modelRequestInstance.AuthorizationModel,
-			Version:            modelRequestInstance.Version,
-			CreatedAt:          &metav1.Time{Time: reconcileTimestamp},
-		})
+		modelInstances = append(modelInstances, newAuthorizationModelDefinition(authModelId, origin, modelRequestInstance).IntoInstance(reconcileTimestamp))
 	}
 
 	authorizationModel.Spec.Instances = modelInstances
 	return true, nil
 }
 
+// getVerifiedAuthorizationModelId returns the ID of the authorization model of the instance and where it
+// comes from, once the assertions of the instance passed.
+func getVerifiedAuthorizationModelId(ctx context.Context,
+	storeService openfga.StoreService,
+	modelRequestInstance extensionsv1.AuthorizationModelRequestInstance,
+	authorizationModelName string,
+	log *logr.Logger) (string, extensionsv1.ModelOrigin, error) {
+	authModelId, origin, err := getAuthorizationModelId(ctx, storeService, modelRequestInstance, authorizationModelName, log)
+	if err != nil {
+		return "", "", err
+	}
+	if err := verifyAssertions(ctx, storeService, authModelId, modelRequestInstance, log); err != nil {
+		return "", "", err
+	}
+	return authModelId, origin, nil
+}
+
+// getAuthorizationModelId returns the ID of the authorization model of the instance and where it comes
+// from. A new authorization model is only written to OpenFGA when no identical one exists in the store.
 func getAuthorizationModelId(ctx context.Context,
-	openFgaService openfga.PermissionService,
+	storeService openfga.StoreService,
 	modelRequestInstance extensionsv1.AuthorizationModelRequestInstance,
 	authorizationModelName string,
-	log *logr.Logger) (string, error) {
+	log *logr.Logger) (string, extensionsv1.ModelOrigin, error) {
 	if modelRequestInstance.ExistingAuthorizationModelId != "" {
-		modelExists, err := openFgaService.CheckAuthorizationModelExists(ctx, modelRequestInstance.ExistingAuthorizationModelId)
+		modelExists, err := storeService.CheckAuthorizationModelExists(ctx, modelRequestInstance.ExistingAuthorizationModelId)
 		if err != nil {
-			return "", fmt.Errorf("failed to check if authorization model exists: %w", err)
+			return "", "", fmt.Errorf("failed to check if authorization model exists: %w", err)
 		}
 		if !modelExists {
-			return "", fmt.Errorf("authorization model with id %s does not exist", modelRequestInstance.ExistingAuthorizationModelId)
+			return "", "", fmt.Errorf("authorization model with id %s does not exist", modelRequestInstance.ExistingAuthorizationModelId)
 		}
 		log.V(0).Info(fmt.Sprintf("Authorization model resource will use existing id: %s", modelRequestInstance.ExistingAuthorizationModelId),
 			"authModel", authorizationModelName,
 			"version", modelRequestInstance.Version.String(),
 		)
-		return modelRequestInstance.ExistingAuthorizationModelId, nil
+		return modelRequestInstance.ExistingAuthorizationModelId, extensionsv1.ModelOriginExisting, nil
 
 	} else {
-		authModelId, err := openFgaService.CreateAuthorizationModel(ctx, modelRequestInstance.AuthorizationModel, log)
+		var authModel *openfga.AuthorizationModel
+		var err error
+		if modular := modelRequestInstance.ModularAuthorizationModel; modular != nil {
+			authModel, err = storeService.CreateModularAuthorizationModel(ctx, modular.Manifest, moduleFiles(modular), log)
+		} else if modelRequestInstance.Format == extensionsv1.FormatJSON {
+			authModel, err = storeService.CreateJSONAuthorizationModel(ctx, modelRequestInstance.AuthorizationModel, log)
+		} else {
+			authModel, err = storeService.CreateAuthorizationModel(ctx, modelRequestInstance.AuthorizationModel, log)
+		}
 		if err != nil {
-			return "", err
+			return "", "", err
 		}
+		if authModel.Adopted {
+			observability.RecordOpenFgaAuthorizationModels(observability.Adopted, authorizationModelName)
+			log.V(0).Info("Adopted identical authorization model in OpenFGA",
+				"authModel", authorizationModelName,
+				"version", modelRequestInstance.Version.String(),
+				"authModelId", authModel.Id)
+			return authModel.Id, extensionsv1.ModelOriginAdopted, nil
+		}
+		observability.RecordOpenFgaAuthorizationModels(observability.Created, authorizationModelName)
 		log.V(0).Info("Created new authorization model in OpenFGA",
 			"authModel", authorizationModelName,
 			"version", modelRequestInstance.Version.String(),
-			"authModelId", authModelId)
-		return authModelId, nil
+			"authModelId", authModel.Id)
+		return authModel.Id, extensionsv1.ModelOriginCreated, nil
 	}
 }
 
+// newAuthorizationModelDefinition creates the definition of an instance, with json models rendered to DSL.
+func newAuthorizationModelDefinition(
+	id string,
+	origin extensionsv1.ModelOrigin,
+	instance extensionsv1.AuthorizationModelRequestInstance) extensionsv1.AuthorizationModelDefinition {
+	definition := extensionsv1.NewAuthorizationModelDefinition(id, renderedDsl(instance.Format, instance.AuthorizationModel), instance.Version)
+	definition.Origin = origin
+	definition.ModularAuthorizationModel = instance.ModularAuthorizationModel
+	return definition
+}
+
 func removeObsoleteInstances(
 	authorizationModelRequest *extensionsv1.AuthorizationModelRequest,
 	authorizationModel *extensionsv1.AuthorizationModel,
@@ -264,21 +399,38 @@ func removeObsoleteInstances(
 	return true
 }
 
+// updateAuthorizationModelWorkloadSettings copies the channels, the rollout strategy and the automatic
+// rollback of the request to the authorization model, which applies them to its workloads.
+func updateAuthorizationModelWorkloadSettings(
+	authorizationModelRequest *extensionsv1.AuthorizationModelRequest,
+	authorizationModel *extensionsv1.AuthorizationModel) bool {
+	if slices.Equal(authorizationModelRequest.Spec.Channels, authorizationModel.Spec.Channels) &&
+		equality.Semantic.DeepEqual(authorizationModelRequest.Spec.RolloutStrategy, authorizationModel.Spec.RolloutStrategy) &&
+		equality.Semantic.DeepEqual(authorizationModelRequest.Spec.AutomaticRollback, authorizationModel.Spec.AutomaticRollback) {
+		return false
+	}
+	authorizationModel.Spec.Channels = slices.Clone(authorizationModelRequest.Spec.Channels)
+	authorizationModel.Spec.RolloutStrategy = authorizationModelRequest.Spec.RolloutStrategy.DeepCopy()
+	authorizationModel.Spec.AutomaticRollback = authorizationModelRequest.Spec.AutomaticRollback.DeepCopy()
+	return true
+}
+
 func (r *AuthorizationModelRequestReconciler) updateAuthorizationModel(
 	ctx context.Context,
-	openFgaService openfga.PermissionService,
+	storeService openfga.StoreService,
 	authorizationModelRequest *extensionsv1.AuthorizationModelRequest,
 	authorizationModel *extensionsv1.AuthorizationModel,
 	reconcileTimestamp time.Time,
 	log *logr.Logger) error {
 
-	updateMissing, err := updateAuthorizationModelWithMissingInstances(ctx, openFgaService, authorizationModelRequest, authorizationModel, reconcileTimestamp, log)
+	updateMissing, err := updateAuthorizationModelWithMissingInstances(ctx, storeService, authorizationModelRequest, authorizationModel, reconcileTimestamp, log)
 	if err != nil {
 		return err
 	}
 	removeObsolete := removeObsoleteInstances(authorizationModelRequest, authorizationModel, log)
+	updateSettings := updateAuthorizationModelWorkloadSettings(authorizationModelRequest, authorizationModel)
 
-	if !(updateMissing || removeObsolete) {
+	if !(updateMissing || removeObsolete || updateSettings) {
 		return nil
 	}
 
@@ -295,7 +447,7 @@ func (r *AuthorizationModelRequestReconciler) updateAuthorizationModel(
 func (r *AuthorizationModelRequestReconciler) getAuthorizationModel(
 	ctx context.Context,
 	req ctrl.Request,
-	openFgaService openfga.PermissionService,
+	storeService openfga.StoreService,
 	authorizationModelRequest *extensionsv1.AuthorizationModelRequest,
 	reconcileTimestamp time.Time,
 	log *logr.Logger) (*extensionsv1.AuthorizationModel, error) {
@@ -306,7 +458,7 @@ func (r *AuthorizationModelRequestReconciler) getAuthorizationModel(
 	case client.IgnoreNotFound(err) != nil:
 		return nil, err
 	case errors.IsNotFound(err):
-		authorizationModel, err = r.createAuthorizationModel(ctx, req, openFgaService, authorizationModelRequest, reconcileTimestamp, log)
+		authorizationModel, err = r.createAuthorizationModel(ctx, req, storeService, authorizationModelRequest, reconcileTimestamp, log)
 		if err != nil {
 			return nil, err
 		}
@@ -317,23 +469,23 @@ func (r *AuthorizationModelRequestReconciler) getAuthorizationModel(
 func (r *AuthorizationModelRequestReconciler) createAuthorizationModel(
 	ctx context.Context,
 	req ctrl.Request,
-	openFgaService openfga.PermissionService,
+	storeService openfga.StoreService,
 	authorizationModelRequest *extensionsv1.AuthorizationModelRequest,
 	reconcileTimestamp time.Time,
 	log *logr.Logger) (*extensionsv1.AuthorizationModel, error) {
 
 	definitions := make([]extensionsv1.AuthorizationModelDefinition, len(authorizationModelRequest.Spec.Instances))
 	for i, instance := range authorizationModelRequest.Spec.Instances {
-		authModelId, err := getAuthorizationModelId(ctx, openFgaService, instance, authorizationModelRequest.Name, log)
+		authModelId, origin, err := getVerifiedAuthorizationModelId(ctx, storeService, instance, authorizationModelRequest.Name, log)
 		if err != nil {
-			return nil, err
+			return nil, &versionError{version: instance.Version, err: err}
 		}
-		observability.RecordOpenFgaAuthorizationModels(req.Name)
 
-		definitions[i] = extensionsv1.NewAuthorizationModelDefinition(authModelId, instance.AuthorizationModel, instance.Version)
+		definitions[i] = newAuthorizationModelDefinition(authModelId, origin, instance)
 	}
 
 	authorizationModel := extensionsv1.NewAuthorizationModel(req.Name, req.Namespace, definitions, reconcileTimestamp)
+	updateAuthorizationModelWorkloadSettings(authorizationModelRequest, &authorizationModel)
 
 	if err := ctrl.SetControllerReference(authorizationModelRequest, &authorizationModel, r.Scheme); err != nil {
 		return nil, err
@@ -348,26 +500,48 @@ func (r *AuthorizationModelRequestReconciler) createAuthorizationModel(
 	return &authorizationModel, nil
 }
 
-func (r *AuthorizationModelRequestReconciler) ensureStoreExistsAndSetStoreId(
+func (r *AuthorizationModelRequestReconciler) ensureStoreExists(
 	ctx context.Context,
 	req ctrl.Request,
 	openFgaService openfga.PermissionService,
 	authorizationModelRequest *extensionsv1.AuthorizationModelRequest,
-	log *logr.Logger) error {
+	log *logr.Logger) (*extensionsv1.Store, error) {
 
 	store := &extensionsv1.Store{}
 	err := r.Get(ctx, req.NamespacedName, store)
 	switch {
 	case client.IgnoreNotFound(err) != nil:
-		return err
+		return nil, err
 	case errors.IsNotFound(err):
 		store, err = r.createStoreResource(ctx, req, openFgaService, authorizationModelRequest, log)
 		if err != nil {
-			return err
+			return nil, err
+		}
+	case !extensionsv1.SameConnection(store.Spec.ConnectionRef, authorizationModelRequest.Spec.ConnectionRef):
+		return nil, fmt.Errorf("store %s lives on connection %s, which can't be changed to %s",
+			store.Name, connectionName(store.Spec.ConnectionRef), connectionName(authorizationModelRequest.Spec.ConnectionRef))
+	case store.Spec.DeletionPolicy != deletionPolicy(authorizationModelRequest):
+		store.Spec.DeletionPolicy = deletionPolicy(authorizationModelRequest)
+		if err := r.Update(ctx, store); err != nil {
+			log.Error(err, "unable to update deletion policy of store", "storeName", store.Name)
+			return nil, err
 		}
 	}
-	openFgaService.SetStoreId(store.Spec.Id)
-	return nil
+	return store, nil
+}
+
+func connectionName(ref *extensionsv1.ConnectionReference) string {
+	if ref == nil {
+		return "of the operator"
+	}
+	return ref.String()
+}
+
+func deletionPolicy(authorizationModelRequest *extensionsv1.AuthorizationModelRequest) extensionsv1.DeletionPolicy {
+	if authorizationModelRequest.Spec.DeletionPolicy == "" {
+		return extensionsv1.DeletionPolicyRetain
+	}
+	return authorizationModelRequest.Spec.DeletionPolicy
 }
 
 func (r *AuthorizationModelRequestReconciler) createStoreResource(
@@ -379,7 +553,9 @@ func (r *AuthorizationModelRequestReconciler) createStoreResource(
 
 	var store *openfga.Store
 	var err error
+	origin := extensionsv1.StoreOriginAdopted
 	if authorizationModelRequest.Spec.ExistingStoreId != "" {
+		origin = extensionsv1.StoreOriginExisting
 		store, err = openFgaService.CheckExistingStoresById(ctx, authorizationModelRequest.Spec.ExistingStoreId)
 	} else {
 		store, err = openFgaService.CheckExistingStoresByName(ctx, req.Name)
@@ -391,6 +567,7 @@ func (r *AuthorizationModelRequestReconciler) createStoreResource(
 		return nil, fmt.Errorf("store with id %s does not exist", authorizationModelRequest.Spec.ExistingStoreId)
 	}
 	if store == nil {
+		origin = extensionsv1.StoreOriginCreated
 		store, err = openFgaService.CreateStore(ctx, req.Name, log)
 		observability.RecordOpenFgaStoreEvent(req.Name)
 		if err != nil {
@@ -399,17 +576,32 @@ func (r *AuthorizationModelRequestReconciler) createStoreResource(
 	}
 
 	storeResource := extensionsv1.NewStore(store.Name, req.Namespace, store.Id, store.CreatedAt)
+	storeResource.Spec.DeletionPolicy = deletionPolicy(authorizationModelRequest)
+	storeResource.Spec.ConnectionRef = authorizationModelRequest.Spec.ConnectionRef.DeepCopy()
+	storeResource.Status.Origin = origin
 
 	if err := ctrl.SetControllerReference(authorizationModelRequest, storeResource, r.Scheme); err != nil {
 		return nil, err
 	}
-	if err := r.Create(ctx, storeResource); client.IgnoreAlreadyExists(err) != nil {
+	// The status isn't persisted on creation, such that it's written afterward.
+	status := storeResource.Status
+	err = r.Create(ctx, storeResource)
+	if errors.IsAlreadyExists(err) {
+		return storeResource, nil
+	}
+	if err != nil {
 		log.Error(err, fmt.Sprintf("Failed to create store %s", req.Name))
 		return nil, err
 	}
 	observability.RecordK8StoreEvent(req.Name)
 	log.V(0).Info("Created store in Kubernetes", "storeKubernetes", storeResource)
 
+	storeResource.Status = status
+	if err := r.Status().Update(ctx, storeResource); err != nil {
+		log.Error(err, "unable to update status of store", "storeName", storeResource.Name)
+		return nil, err
+	}
+
 	return storeResource, nil
 }
 
@@ -428,9 +620,14 @@ func (r *AuthorizationModelRequestReconciler) SetupWithManager(mgr ctrl.Manager)
 		},
 	}
 
+	// Config maps don't have a generation, such that the predicates only apply to the requests and
+	// authorization models. Only the metadata of config maps is cached, as their content is read with the
+	// API reader.
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&extensionsv1.AuthorizationModelRequest{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
-		WithEventFilter(deletePredicate).
+		For(&extensionsv1.AuthorizationModelRequest{}, builder.WithPredicates(predicate.GenerationChangedPredicate{}, deletePredicate)).
+		Owns(&extensionsv1.AuthorizationModel{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&v1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, configMap client.Object) []reconcile.Request {
+			return requestsForConfigMap(ctx, r.Client, configMap)
+		}), builder.OnlyMetadata).
 		Complete(r)
 }
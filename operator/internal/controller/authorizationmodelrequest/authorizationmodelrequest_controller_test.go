@@ -26,6 +26,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -173,15 +174,16 @@ var _ = Describe("AuthorizationModelRequest Controller", func() {
 
 			mockFactory := fgainternal.NewMockPermissionServiceFactory(goMockController)
 			mockService := fgainternal.NewMockPermissionService(goMockController)
+			mockStoreService := fgainternal.NewMockStoreService(goMockController)
 			mockFactory.EXPECT().GetService(gomock.Any()).Return(mockService, nil).Times(1)
 
 			mockService.EXPECT().CheckExistingStoresByName(gomock.Any(), gomock.Any()).Times(0)
 			mockService.EXPECT().CheckExistingStoresById(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("store not found")).Times(1)
 			mockService.EXPECT().CreateStore(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
-			mockService.EXPECT().SetStoreId(gomock.Any()).Times(0)
+			mockService.EXPECT().ForStore(gomock.Any()).Times(0)
 
-			mockService.EXPECT().CreateAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
-			mockService.EXPECT().CheckAuthorizationModelExists(gomock.Any(), gomock.Any()).Times(0)
+			mockStoreService.EXPECT().CreateAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			mockStoreService.EXPECT().CheckAuthorizationModelExists(gomock.Any(), gomock.Any()).Times(0)
 
 			fakeRecorder := record.NewFakeRecorder(5)
 			reconciler := &AuthorizationModelRequestReconciler{
@@ -240,6 +242,7 @@ var _ = Describe("AuthorizationModelRequest Controller", func() {
 
 			mockFactory := fgainternal.NewMockPermissionServiceFactory(goMockController)
 			mockService := fgainternal.NewMockPermissionService(goMockController)
+			mockStoreService := fgainternal.NewMockStoreService(goMockController)
 			mockFactory.EXPECT().GetService(gomock.Any()).Return(mockService, nil).Times(1)
 
 			mockService.EXPECT().CheckExistingStoresByName(gomock.Any(), gomock.Any()).Times(0)
@@ -249,10 +252,10 @@ var _ = Describe("AuthorizationModelRequest Controller", func() {
 				CreatedAt: time.Now(),
 			}, nil).Times(1)
 			mockService.EXPECT().CreateStore(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
-			mockService.EXPECT().SetStoreId(gomock.Any()).Times(1)
+			mockService.EXPECT().ForStore(gomock.Any()).Return(mockStoreService, nil).Times(1)
 
-			mockService.EXPECT().CheckAuthorizationModelExists(gomock.Any(), gomock.Any()).Times(1).Return(false, nil)
-			mockService.EXPECT().CreateAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			mockStoreService.EXPECT().CheckAuthorizationModelExists(gomock.Any(), gomock.Any()).Times(1).Return(false, nil)
+			mockStoreService.EXPECT().CreateAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 
 			fakeRecorder := record.NewFakeRecorder(5)
 			reconciler := &AuthorizationModelRequestReconciler{
@@ -318,6 +321,7 @@ var _ = Describe("AuthorizationModelRequest Controller", func() {
 
 			mockFactory := fgainternal.NewMockPermissionServiceFactory(goMockController)
 			mockService := fgainternal.NewMockPermissionService(goMockController)
+			mockStoreService := fgainternal.NewMockStoreService(goMockController)
 			mockFactory.EXPECT().GetService(gomock.Any()).Return(mockService, nil).Times(1)
 
 			mockService.EXPECT().CheckExistingStoresByName(gomock.Any(), gomock.Any()).Times(0)
@@ -327,10 +331,10 @@ var _ = Describe("AuthorizationModelRequest Controller", func() {
 				CreatedAt: time.Now(),
 			}, nil).Times(1)
 			mockService.EXPECT().CreateStore(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
-			mockService.EXPECT().SetStoreId(gomock.Any()).Times(1)
+			mockService.EXPECT().ForStore(gomock.Any()).Return(mockStoreService, nil).Times(1)
 
-			mockService.EXPECT().CreateAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
-			mockService.EXPECT().CheckAuthorizationModelExists(gomock.Any(), gomock.Any()).Times(1).Return(true, nil)
+			mockStoreService.EXPECT().CreateAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			mockStoreService.EXPECT().CheckAuthorizationModelExists(gomock.Any(), gomock.Any()).Times(1).Return(true, nil)
 
 			reconciler := &AuthorizationModelRequestReconciler{
 				Client:                   k8sClient,
@@ -406,6 +410,27 @@ var _ = Describe("AuthorizationModelRequest Controller", func() {
 			}, duration, interval).Should(Equal(extensionsv1.Synchronized))
 		})
 
+		It("should set conditions, store id and versions when reconciled", func() {
+			// Arrange
+			ensureAuthorizationModelRequestExists(ctx, typeNamespacedName)
+
+			// Act
+			_, err := controllerReconciler.Reconcile(ctx, request)
+			Expect(err).To(Not(HaveOccurred()))
+
+			// Assert
+			authModelRequest := &extensionsv1.AuthorizationModelRequest{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, authModelRequest)).To(Succeed())
+			Expect(authModelRequest.Status.StoreId).To(Equal("foo"))
+			Expect(authModelRequest.Status.ObservedGeneration).To(Equal(authModelRequest.Generation))
+			Expect(authModelRequest.Status.Versions).To(HaveLen(1))
+			Expect(authModelRequest.Status.Versions[0].Version).To(Equal(version.String()))
+			Expect(authModelRequest.Status.Versions[0].Id).To(Equal("123"))
+			Expect(meta.IsStatusConditionTrue(authModelRequest.Status.Conditions, extensionsv1.ConditionStoreReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(authModelRequest.Status.Conditions, extensionsv1.ConditionModelsSynced)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(authModelRequest.Status.Conditions, extensionsv1.ConditionReady)).To(BeTrue())
+		})
+
 		It("should show pending when not reconciled", func() {
 			// Arrange
 			ensureAuthorizationModelRequestExists(ctx, typeNamespacedName)
@@ -508,14 +533,15 @@ var _ = Describe("AuthorizationModelRequest Controller", func() {
 			}
 			mockFactory := fgainternal.NewMockPermissionServiceFactory(goMockController)
 			mockService := fgainternal.NewMockPermissionService(goMockController)
+			mockStoreService := fgainternal.NewMockStoreService(goMockController)
 			mockFactory.EXPECT().GetService(gomock.Any()).Return(mockService, nil)
 			mockService.EXPECT().CheckExistingStoresByName(gomock.Any(), gomock.Any()).Return(nil, nil)
 			mockService.EXPECT().CheckExistingStoresById(gomock.Any(), gomock.Any()).Times(0)
 			mockService.EXPECT().CreateStore(gomock.Any(), gomock.Any(), gomock.Any()).Return(&store, nil)
-			mockService.EXPECT().SetStoreId(gomock.Any())
-			mockService.EXPECT().
+			mockService.EXPECT().ForStore(gomock.Any()).Return(mockStoreService, nil)
+			mockStoreService.EXPECT().
 				CreateAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).
-				Return("", fmt.Errorf("error"))
+				Return(nil, fmt.Errorf("error"))
 
 			fakeRecorder := record.NewFakeRecorder(5)
 			reconciler := &AuthorizationModelRequestReconciler{
@@ -545,6 +571,12 @@ var _ = Describe("AuthorizationModelRequest Controller", func() {
 				return authModelRequest.Status.State, nil
 			}, duration, interval).Should(Equal(extensionsv1.SynchronizationFailed))
 			validateEvent(fakeRecorder.Events, EventReasonAuthorizationModelCreationFailed)
+
+			authModelRequest := &extensionsv1.AuthorizationModelRequest{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, authModelRequest)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(authModelRequest.Status.Conditions, extensionsv1.ConditionModelsSynced)).To(BeTrue())
+			Expect(authModelRequest.Status.Versions).To(HaveLen(1))
+			Expect(authModelRequest.Status.Versions[0].LastError).To(Equal("error"))
 		})
 
 		It("given existing store when create store resource then return existing", func() {
@@ -590,17 +622,85 @@ var _ = Describe("AuthorizationModelRequest Controller", func() {
 			Expect(storeResource.Namespace).To(Equal(namespaceName))
 		})
 
+		It("given deletion policy on request when create store resource then store has deletion policy", func() {
+			mockService := fgainternal.NewMockPermissionService(goMockController)
+			mockService.EXPECT().CheckExistingStoresByName(gomock.Any(), gomock.Any()).Return(&fgainternal.Store{
+				Id:        "foo",
+				Name:      resourceName,
+				CreatedAt: time.Now(),
+			}, nil)
+
+			authRequest := createAuthorizationModelRequest(resourceName, namespaceName)
+			authRequest.Spec.DeletionPolicy = extensionsv1.DeletionPolicyDelete
+
+			storeResource, err := controllerReconciler.createStoreResource(
+				ctx, request,
+				mockService, &authRequest, &logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storeResource.Spec.DeletionPolicy).To(Equal(extensionsv1.DeletionPolicyDelete))
+		})
+
+		It("given connection on request when create store resource then store records connection", func() {
+			mockService := fgainternal.NewMockPermissionService(goMockController)
+			mockService.EXPECT().CheckExistingStoresByName(gomock.Any(), gomock.Any()).Return(&fgainternal.Store{
+				Id:        "foo",
+				Name:      resourceName,
+				CreatedAt: time.Now(),
+			}, nil)
+
+			authRequest := createAuthorizationModelRequest(resourceName, namespaceName)
+			authRequest.Spec.ConnectionRef = &extensionsv1.ConnectionReference{Kind: extensionsv1.ConnectionKindCluster, Name: "eu-west-1"}
+
+			storeResource, err := controllerReconciler.createStoreResource(
+				ctx, request,
+				mockService, &authRequest, &logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storeResource.Spec.ConnectionRef).To(Equal(authRequest.Spec.ConnectionRef))
+		})
+
+		It("given store on other connection when ensure store exists then fail", func() {
+			storeResource := extensionsv1.NewStore(resourceName, namespaceName, "foo", time.Now())
+			Expect(k8sClient.Create(ctx, storeResource)).To(Succeed())
+			mockService := fgainternal.NewMockPermissionService(goMockController)
+
+			authRequest := createAuthorizationModelRequest(resourceName, namespaceName)
+			authRequest.Spec.ConnectionRef = &extensionsv1.ConnectionReference{Name: "eu-west-1"}
+
+			_, err := controllerReconciler.ensureStoreExists(
+				ctx, request,
+				mockService, &authRequest, &logger)
+			Expect(err).To(MatchError(ContainSubstring("can't be changed to FGAConnection/eu-west-1")))
+		})
+
+		It("when identical authorization model exists in OpenFGA then adopt it", func() {
+			// Arrange
+			authModelId := uuid.NewString()
+			mockStoreService := fgainternal.NewMockStoreService(goMockController)
+			mockStoreService.EXPECT().
+				CreateAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&fgainternal.AuthorizationModel{Id: authModelId, Adopted: true}, nil)
+			authRequest := createAuthorizationModelRequest(resourceName, namespaceName)
+
+			// Act
+			authModel, err := controllerReconciler.createAuthorizationModel(ctx, request, mockStoreService, &authRequest, time.Now(), &logger)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authModel.Spec.Instances[0].Id).To(Equal(authModelId))
+			Expect(authModel.Spec.Instances[0].Origin).To(Equal(extensionsv1.ModelOriginAdopted))
+		})
+
 		It("when create authorization model then present in kubernetes", func() {
 			// Arrange
 			authModelId := uuid.NewString()
-			mockService := fgainternal.NewMockPermissionService(goMockController)
-			mockService.EXPECT().
+			mockStoreService := fgainternal.NewMockStoreService(goMockController)
+			mockStoreService.EXPECT().
 				CreateAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).
-				Return(authModelId, nil)
+				Return(&fgainternal.AuthorizationModel{Id: authModelId}, nil)
 			authRequest := createAuthorizationModelRequest(resourceName, namespaceName)
 
 			// Act
-			authModel, err := controllerReconciler.createAuthorizationModel(ctx, request, mockService, &authRequest, time.Now(), &logger)
+			authModel, err := controllerReconciler.createAuthorizationModel(ctx, request, mockStoreService, &authRequest, time.Now(), &logger)
 
 			// Assert
 			Expect(err).NotTo(HaveOccurred())
@@ -614,15 +714,15 @@ var _ = Describe("AuthorizationModelRequest Controller", func() {
 
 		It("given no changes in auth model when update then do not changes", func() {
 			// Arrange
-			mockService := fgainternal.NewMockPermissionService(goMockController)
-			mockService.EXPECT().
+			mockStoreService := fgainternal.NewMockStoreService(goMockController)
+			mockStoreService.EXPECT().
 				CreateAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).
 				Times(0)
 			authRequest := createAuthorizationModelRequest(resourceName, namespaceName)
 			authModel := createAuthorizationModel(resourceName, namespaceName)
 
 			// Act
-			err := controllerReconciler.updateAuthorizationModel(ctx, mockService, &authRequest, &authModel, time.Now(), &logger)
+			err := controllerReconciler.updateAuthorizationModel(ctx, mockStoreService, &authRequest, &authModel, time.Now(), &logger)
 
 			// Assert
 			Expect(err).NotTo(HaveOccurred())
@@ -644,16 +744,16 @@ var _ = Describe("AuthorizationModelRequest Controller", func() {
 			Expect(k8sClient.Create(ctx, &authModel)).To(Succeed())
 			authModelRequest := createAuthorizationModelRequestWithSpecs(resourceName, namespaceName, requestInstances)
 			newAuthModelId := uuid.NewString()
-			mockService := fgainternal.NewMockPermissionService(goMockController)
-			mockService.EXPECT().
+			mockStoreService := fgainternal.NewMockStoreService(goMockController)
+			mockStoreService.EXPECT().
 				CreateAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).
-				Return(newAuthModelId, nil)
+				Return(&fgainternal.AuthorizationModel{Id: newAuthModelId}, nil)
 
 			Expect(len(authModel.Spec.Instances)).To(Equal(1))
 			oldAuthModelId := authModel.Spec.Instances[0].Id
 
 			// Act
-			err := controllerReconciler.updateAuthorizationModel(ctx, mockService, &authModelRequest, &authModel, time.Now(), &logger)
+			err := controllerReconciler.updateAuthorizationModel(ctx, mockStoreService, &authModelRequest, &authModel, time.Now(), &logger)
 
 			// Assert
 			Expect(err).NotTo(HaveOccurred())
@@ -683,15 +783,15 @@ var _ = Describe("AuthorizationModelRequest Controller", func() {
 			requestInstances := authorizationModelRequestInstancesFromSingle(modelUpdated, versionUpdated)
 			authModelRequest := createAuthorizationModelRequestWithSpecs(resourceName, namespaceName, requestInstances)
 			newAuthModelId := uuid.NewString()
-			mockService := fgainternal.NewMockPermissionService(goMockController)
-			mockService.EXPECT().
+			mockStoreService := fgainternal.NewMockStoreService(goMockController)
+			mockStoreService.EXPECT().
 				CreateAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).
-				Return(newAuthModelId, nil)
+				Return(&fgainternal.AuthorizationModel{Id: newAuthModelId}, nil)
 
 			Expect(len(authModel.Spec.Instances)).To(Equal(1))
 
 			// Act
-			err := controllerReconciler.updateAuthorizationModel(ctx, mockService, &authModelRequest, &authModel, time.Now(), &logger)
+			err := controllerReconciler.updateAuthorizationModel(ctx, mockStoreService, &authModelRequest, &authModel, time.Now(), &logger)
 
 			// Assert
 			Expect(err).NotTo(HaveOccurred())
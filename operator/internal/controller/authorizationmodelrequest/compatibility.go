@@ -0,0 +1,161 @@
+package authorizationmodelrequest
+
+import (
+	extensionsv1 "fga-operator/api/v1"
+	"fga-operator/internal/openfga"
+	"fmt"
+	"github.com/openfga/language/pkg/go/transformer"
+	"strings"
+)
+
+// breakingChangesError is returned when a new minor or patch version breaks the previous version of its
+// major version.
+type breakingChangesError struct {
+	previous extensionsv1.ModelVersion
+	changes  []string
+}
+
+func (e *breakingChangesError) Error() string {
+	return fmt.Sprintf("breaking changes to version %s, add a new major version instead: %s",
+		e.previous.String(), strings.Join(e.changes, "; "))
+}
+
+// versionChanges are the breaking changes of a version to the previous version of its major version.
+type versionChanges struct {
+	version extensionsv1.ModelVersion
+	err     *breakingChangesError
+}
+
+// findBreakingChanges compares every instance of the request, which isn't one of the stored versions, with
+// the highest lower version in the same major version, of the request or stored in the AuthorizationModel.
+// The previous version is compared with the model of the request, or with the model stored in the
+// AuthorizationModel when the request has no model for it, i.e. when it has an existing ID or was removed
+// from the request. Major versions may contain breaking changes, and instances without a model aren't
+// compared. Models which don't compile aren't compared either, since they fail when they are written.
+func findBreakingChanges(
+	request *extensionsv1.AuthorizationModelRequest,
+	stored []extensionsv1.AuthorizationModelInstance) []versionChanges {
+	existing := make(map[extensionsv1.ModelVersion]struct{}, len(stored))
+	for _, instance := range stored {
+		existing[instance.Version] = struct{}{}
+	}
+	var result []versionChanges
+	for _, instance := range request.Spec.Instances {
+		if _, exists := existing[instance.Version]; exists {
+			continue
+		}
+		previous, found := previousVersion(request.Spec.Instances, stored, instance.Version)
+		if !found {
+			continue
+		}
+		previousModel, ok := compiledPreviousModel(request.Spec.Instances, stored, previous)
+		if !ok {
+			continue
+		}
+		model, ok := compiledModel(instance)
+		if !ok {
+			continue
+		}
+		changes, err := openfga.BreakingChanges(previousModel, model)
+		if err != nil || len(changes) == 0 {
+			continue
+		}
+		result = append(result, versionChanges{
+			version: instance.Version,
+			err:     &breakingChangesError{previous: previous, changes: changes},
+		})
+	}
+	return result
+}
+
+// previousVersion returns the highest version below the version in its major version, of the instances of
+// the request or stored in the AuthorizationModel.
+func previousVersion(
+	instances []extensionsv1.AuthorizationModelRequestInstance,
+	stored []extensionsv1.AuthorizationModelInstance,
+	version extensionsv1.ModelVersion) (extensionsv1.ModelVersion, bool) {
+	versions := make([]extensionsv1.ModelVersion, 0, len(instances)+len(stored))
+	for _, instance := range instances {
+		versions = append(versions, instance.Version)
+	}
+	for _, instance := range stored {
+		versions = append(versions, instance.Version)
+	}
+
+	var previous extensionsv1.ModelVersion
+	found := false
+	for _, candidate := range versions {
+		if candidate.Major != version.Major || !isLowerVersion(candidate, version) {
+			continue
+		}
+		if !found || isLowerVersion(previous, candidate) {
+			previous = candidate
+			found = true
+		}
+	}
+	return previous, found
+}
+
+// compiledPreviousModel returns the authorization model of the version in json, from the instance of the
+// request, or from the instance stored in the AuthorizationModel when the request has no model for it.
+func compiledPreviousModel(
+	instances []extensionsv1.AuthorizationModelRequestInstance,
+	stored []extensionsv1.AuthorizationModelInstance,
+	version extensionsv1.ModelVersion) (string, bool) {
+	for _, instance := range instances {
+		if instance.Version != version {
+			continue
+		}
+		if compiled, ok := compiledModel(instance); ok {
+			return compiled, true
+		}
+	}
+	for _, instance := range stored {
+		if instance.Version == version {
+			return compiledStoredModel(instance)
+		}
+	}
+	return "", false
+}
+
+func isLowerVersion(a, b extensionsv1.ModelVersion) bool {
+	if a.Major != b.Major {
+		return a.Major < b.Major
+	}
+	if a.Minor != b.Minor {
+		return a.Minor < b.Minor
+	}
+	return a.Patch < b.Patch
+}
+
+// compiledModel returns the authorization model of a resolved instance in json, and false when the
+// instance has no model or it doesn't compile.
+func compiledModel(instance extensionsv1.AuthorizationModelRequestInstance) (string, bool) {
+	switch {
+	case instance.ModularAuthorizationModel != nil:
+		compiled, err := openfga.CompileModularModel(instance.ModularAuthorizationModel.Manifest, moduleFiles(instance.ModularAuthorizationModel))
+		return compiled, err == nil
+	case instance.ExistingAuthorizationModelId != "" || instance.AuthorizationModel == "":
+		return "", false
+	case instance.Format == extensionsv1.FormatJSON:
+		return instance.AuthorizationModel, true
+	default:
+		compiled, err := transformer.TransformDSLToJSON(instance.AuthorizationModel)
+		return compiled, err == nil
+	}
+}
+
+// compiledStoredModel returns the authorization model of an instance of the AuthorizationModel in json, and
+// false when the instance has no model, i.e. it was created from an existing ID.
+func compiledStoredModel(instance extensionsv1.AuthorizationModelInstance) (string, bool) {
+	switch {
+	case instance.ModularAuthorizationModel != nil:
+		compiled, err := openfga.CompileModularModel(instance.ModularAuthorizationModel.Manifest, moduleFiles(instance.ModularAuthorizationModel))
+		return compiled, err == nil
+	case instance.AuthorizationModel == "":
+		return "", false
+	default:
+		compiled, err := transformer.TransformDSLToJSON(instance.AuthorizationModel)
+		return compiled, err == nil
+	}
+}
@@ -0,0 +1,114 @@
+package authorizationmodelrequest
+
+import (
+	extensionsv1 "fga-operator/api/v1"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+const modelWithoutWriter = `
+model
+  schema 1.1
+
+type user
+
+type document
+  relations
+    define foo: [user]
+    define reader: [user]
+    define owner: [user]
+`
+
+func instance(authorizationModel string, major, minor int) extensionsv1.AuthorizationModelRequestInstance {
+	return extensionsv1.AuthorizationModelRequestInstance{
+		AuthorizationModel: authorizationModel,
+		Version:            extensionsv1.ModelVersion{Major: major, Minor: minor},
+	}
+}
+
+func storedInstance(authorizationModel string, major, minor int) extensionsv1.AuthorizationModelInstance {
+	return extensionsv1.AuthorizationModelInstance{
+		AuthorizationModel: authorizationModel,
+		Version:            extensionsv1.ModelVersion{Major: major, Minor: minor},
+	}
+}
+
+func TestFindBreakingChanges(t *testing.T) {
+	testCases := []struct {
+		description string
+		instances   []extensionsv1.AuthorizationModelRequestInstance
+		stored      []extensionsv1.AuthorizationModelInstance
+		expected    map[string]string
+	}{
+		{
+			description: "minor version removing a relation",
+			instances:   []extensionsv1.AuthorizationModelRequestInstance{instance(model, 1, 0), instance(modelWithoutWriter, 1, 1)},
+			stored:      []extensionsv1.AuthorizationModelInstance{storedInstance(model, 1, 0)},
+			expected:    map[string]string{"1.1.0": "1.0.0: relation document#writer removed"},
+		},
+		{
+			description: "compared with the highest lower version of the major version",
+			instances: []extensionsv1.AuthorizationModelRequestInstance{
+				instance(modelWithoutWriter, 1, 2), instance(modelWithoutWriter, 1, 0), instance(model, 1, 1),
+			},
+			stored:   []extensionsv1.AuthorizationModelInstance{storedInstance(modelWithoutWriter, 1, 0), storedInstance(model, 1, 1)},
+			expected: map[string]string{"1.2.0": "1.1.0: relation document#writer removed"},
+		},
+		{
+			description: "existing version isn't checked",
+			instances:   []extensionsv1.AuthorizationModelRequestInstance{instance(model, 1, 0), instance(modelWithoutWriter, 1, 1)},
+			stored:      []extensionsv1.AuthorizationModelInstance{storedInstance(model, 1, 0), storedInstance(modelWithoutWriter, 1, 1)},
+		},
+		{
+			description: "major version may break",
+			instances:   []extensionsv1.AuthorizationModelRequestInstance{instance(model, 1, 0), instance(modelWithoutWriter, 2, 0)},
+		},
+		{
+			description: "previous version without model isn't compared",
+			instances: []extensionsv1.AuthorizationModelRequestInstance{
+				{ExistingAuthorizationModelId: "id", Version: extensionsv1.ModelVersion{Major: 1}},
+				instance(modelWithoutWriter, 1, 1),
+			},
+			stored: []extensionsv1.AuthorizationModelInstance{{Id: "id", Version: extensionsv1.ModelVersion{Major: 1}}},
+		},
+		{
+			description: "previous version with existing id is compared with the stored model",
+			instances: []extensionsv1.AuthorizationModelRequestInstance{
+				{ExistingAuthorizationModelId: "id", Version: extensionsv1.ModelVersion{Major: 1}},
+				instance(modelWithoutWriter, 1, 1),
+			},
+			stored:   []extensionsv1.AuthorizationModelInstance{storedInstance(model, 1, 0)},
+			expected: map[string]string{"1.1.0": "1.0.0: relation document#writer removed"},
+		},
+		{
+			description: "previous version removed from the request is compared with the stored model",
+			instances:   []extensionsv1.AuthorizationModelRequestInstance{instance(modelWithoutWriter, 1, 1)},
+			stored:      []extensionsv1.AuthorizationModelInstance{storedInstance(model, 1, 0)},
+			expected:    map[string]string{"1.1.0": "1.0.0: relation document#writer removed"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			request := &extensionsv1.AuthorizationModelRequest{
+				Spec: extensionsv1.AuthorizationModelRequestSpec{Instances: testCase.instances},
+			}
+
+			// Act
+			found := findBreakingChanges(request, testCase.stored)
+
+			// Assert
+			var actual map[string]string
+			for _, changes := range found {
+				if actual == nil {
+					actual = map[string]string{}
+				}
+				actual[changes.version.String()] = changes.err.previous.String() + ": " + changes.err.changes[0]
+			}
+			if diff := cmp.Diff(testCase.expected, actual); diff != "" {
+				t.Errorf("unexpected breaking changes (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
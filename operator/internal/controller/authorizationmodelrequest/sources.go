@@ -0,0 +1,292 @@
+package authorizationmodelrequest
+
+import (
+	"context"
+	extensionsv1 "fga-operator/api/v1"
+	"fga-operator/internal/openfga"
+	"fmt"
+	"github.com/openfga/language/pkg/go/transformer"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// resolveAuthorizationModels sets the authorization model of every instance with an authorizationModelFrom
+// source to the content of the referenced key, and inlines the manifest and module files of modular
+// authorization models and the assertions of test files. Only the request in memory is changed, the
+// reconciler never updates the spec of a request.
+func resolveAuthorizationModels(
+	ctx context.Context,
+	reader client.Reader,
+	request *extensionsv1.AuthorizationModelRequest) error {
+	for i := range request.Spec.Instances {
+		instance := &request.Spec.Instances[i]
+		switch {
+		case instance.ModularAuthorizationModel != nil:
+			modular, err := resolveModularAuthorizationModel(ctx, reader, request.Namespace, instance)
+			if err != nil {
+				return &versionError{version: instance.Version, err: err}
+			}
+			instance.ModularAuthorizationModel = modular
+		case instance.AuthorizationModelFrom != nil:
+			authorizationModel, err := resolveAuthorizationModel(ctx, reader, request.Namespace, instance)
+			if err != nil {
+				return &versionError{version: instance.Version, err: err}
+			}
+			instance.AuthorizationModel = authorizationModel
+		}
+		if instance.AssertionsFrom != nil {
+			assertions, err := resolveAssertions(ctx, reader, request.Namespace, instance)
+			if err != nil {
+				return &versionError{version: instance.Version, err: err}
+			}
+			instance.Assertions = assertions
+		}
+	}
+	return nil
+}
+
+// resolveAssertions returns the assertions of the OpenFGA test file referenced by the instance.
+func resolveAssertions(
+	ctx context.Context,
+	reader client.Reader,
+	namespace string,
+	instance *extensionsv1.AuthorizationModelRequestInstance) (*extensionsv1.AuthorizationModelAssertions, error) {
+	if instance.Assertions != nil {
+		return nil, fmt.Errorf("assertionsFrom may not be set together with assertions")
+	}
+	content, err := readConfigMapKey(ctx, reader, namespace, instance.AssertionsFrom)
+	if err != nil {
+		return nil, fmt.Errorf("assertions: %w", err)
+	}
+	return parseTestFile(content)
+}
+
+func resolveAuthorizationModel(
+	ctx context.Context,
+	reader client.Reader,
+	namespace string,
+	instance *extensionsv1.AuthorizationModelRequestInstance) (string, error) {
+	source := instance.AuthorizationModelFrom
+	if instance.AuthorizationModel != "" || instance.ExistingAuthorizationModelId != "" {
+		return "", fmt.Errorf("authorizationModelFrom may not be set together with authorizationModel or existingAuthorizationModelId")
+	}
+
+	var authorizationModel string
+	var err error
+	switch {
+	case source.ConfigMapKeyRef != nil && source.SecretKeyRef != nil:
+		return "", fmt.Errorf("authorizationModelFrom may only set one of configMapKeyRef and secretKeyRef")
+	case source.ConfigMapKeyRef != nil:
+		authorizationModel, err = readConfigMapKey(ctx, reader, namespace, source.ConfigMapKeyRef)
+	case source.SecretKeyRef != nil:
+		authorizationModel, err = readSecretKey(ctx, reader, namespace, source.SecretKeyRef)
+	default:
+		return "", fmt.Errorf("authorizationModelFrom must set one of configMapKeyRef and secretKeyRef")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := validateAuthorizationModel(instance.Format, authorizationModel); err != nil {
+		return "", err
+	}
+	return authorizationModel, nil
+}
+
+func validateAuthorizationModel(format extensionsv1.AuthorizationModelFormat, authorizationModel string) error {
+	if format == extensionsv1.FormatJSON {
+		return openfga.ValidateJSONModel(authorizationModel)
+	}
+	if _, err := transformer.TransformDSLToJSON(authorizationModel); err != nil {
+		return fmt.Errorf("invalid authorization model: %w", err)
+	}
+	return nil
+}
+
+// resolveModularAuthorizationModel returns a copy of the modular authorization model of the instance with
+// the manifest and the contents of the module files inlined, after validating that it compiles.
+func resolveModularAuthorizationModel(
+	ctx context.Context,
+	reader client.Reader,
+	namespace string,
+	instance *extensionsv1.AuthorizationModelRequestInstance) (*extensionsv1.ModularAuthorizationModel, error) {
+	if instance.AuthorizationModel != "" || instance.AuthorizationModelFrom != nil || instance.ExistingAuthorizationModelId != "" {
+		return nil, fmt.Errorf("modularAuthorizationModel may not be set together with authorizationModel, authorizationModelFrom or existingAuthorizationModelId")
+	}
+
+	source := instance.ModularAuthorizationModel
+	resolved := &extensionsv1.ModularAuthorizationModel{
+		Manifest: source.Manifest,
+		Modules:  make([]extensionsv1.ModuleFile, len(source.Modules)),
+	}
+	if source.ManifestFrom != nil {
+		manifest, err := readConfigMapKey(ctx, reader, namespace, source.ManifestFrom)
+		if err != nil {
+			return nil, fmt.Errorf("fga.mod: %w", err)
+		}
+		resolved.Manifest = manifest
+	}
+	for i, module := range source.Modules {
+		resolved.Modules[i] = extensionsv1.ModuleFile{Name: module.Name, Contents: module.Contents}
+		if module.ContentsFrom == nil {
+			continue
+		}
+		contents, err := readConfigMapKey(ctx, reader, namespace, module.ContentsFrom)
+		if err != nil {
+			return nil, fmt.Errorf("module file %s: %w", module.Name, err)
+		}
+		resolved.Modules[i].Contents = contents
+	}
+
+	if _, err := openfga.CompileModularModel(resolved.Manifest, moduleFiles(resolved)); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+func moduleFiles(modular *extensionsv1.ModularAuthorizationModel) []transformer.ModuleFile {
+	files := make([]transformer.ModuleFile, len(modular.Modules))
+	for i, module := range modular.Modules {
+		files[i] = transformer.ModuleFile{Name: module.Name, Contents: module.Contents}
+	}
+	return files
+}
+
+func readConfigMapKey(
+	ctx context.Context,
+	reader client.Reader,
+	namespace string,
+	selector *corev1.ConfigMapKeySelector) (string, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: selector.Name, Namespace: namespace}, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return "", fmt.Errorf("config map %s not found", selector.Name)
+		}
+		return "", err
+	}
+	value, ok := configMap.Data[selector.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in config map %s", selector.Key, selector.Name)
+	}
+	return value, nil
+}
+
+func readSecretKey(
+	ctx context.Context,
+	reader client.Reader,
+	namespace string,
+	selector *corev1.SecretKeySelector) (string, error) {
+	secret := &corev1.Secret{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: selector.Name, Namespace: namespace}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return "", fmt.Errorf("secret %s not found", selector.Name)
+		}
+		return "", err
+	}
+	value, ok := secret.Data[selector.Key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in secret %s", selector.Key, selector.Name)
+	}
+	return string(value), nil
+}
+
+// checkUnchangedVersions returns an error when the authorization model of a version which already exists
+// has changed, which happens when the content of a referenced config map or secret is edited. Versions
+// are immutable, such that workloads on a version always get the same model.
+func checkUnchangedVersions(
+	request *extensionsv1.AuthorizationModelRequest,
+	authorizationModel *extensionsv1.AuthorizationModel) error {
+	existing := make(map[extensionsv1.ModelVersion]extensionsv1.AuthorizationModelInstance, len(authorizationModel.Spec.Instances))
+	for _, instance := range authorizationModel.Spec.Instances {
+		existing[instance.Version] = instance
+	}
+	for _, instance := range request.Spec.Instances {
+		existingInstance, exists := existing[instance.Version]
+		if !exists {
+			continue
+		}
+		switch {
+		case instance.ModularAuthorizationModel != nil:
+			if existingInstance.ModularAuthorizationModel == nil ||
+				openfga.IsSameModularModel(
+					existingInstance.ModularAuthorizationModel.Manifest, moduleFiles(existingInstance.ModularAuthorizationModel),
+					instance.ModularAuthorizationModel.Manifest, moduleFiles(instance.ModularAuthorizationModel)) {
+				continue
+			}
+		case instance.AuthorizationModelFrom != nil:
+			if existingInstance.AuthorizationModel == "" ||
+				openfga.IsSameModel(existingInstance.AuthorizationModel, renderedDsl(instance.Format, instance.AuthorizationModel)) {
+				continue
+			}
+		default:
+			continue
+		}
+		return &versionError{
+			version: instance.Version,
+			err: fmt.Errorf("authorization model already exists with id %s and can't be changed, add a new version instead",
+				existingInstance.Id),
+		}
+	}
+	return nil
+}
+
+// renderedDsl returns the authorization model in DSL, rendering json models. It returns an empty string
+// when a json model can't be rendered.
+func renderedDsl(format extensionsv1.AuthorizationModelFormat, authorizationModel string) string {
+	if format != extensionsv1.FormatJSON {
+		return authorizationModel
+	}
+	dsl, err := openfga.RenderDSL(authorizationModel)
+	if err != nil {
+		return ""
+	}
+	return dsl
+}
+
+// requestsForConfigMap maps a config map to the requests in its namespace referencing it.
+func requestsForConfigMap(ctx context.Context, reader client.Reader, configMap client.Object) []reconcile.Request {
+	requests := &extensionsv1.AuthorizationModelRequestList{}
+	if err := reader.List(ctx, requests, client.InNamespace(configMap.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "unable to list authorization model requests",
+			"configMapNamespace", configMap.GetNamespace(), "configMapName", configMap.GetName())
+		return nil
+	}
+	var result []reconcile.Request
+	for _, request := range requests.Items {
+		if referencesConfigMap(&request, configMap.GetName()) {
+			result = append(result, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: request.Name, Namespace: request.Namespace},
+			})
+		}
+	}
+	return result
+}
+
+func referencesConfigMap(request *extensionsv1.AuthorizationModelRequest, configMapName string) bool {
+	for _, instance := range request.Spec.Instances {
+		if instance.AssertionsFrom != nil && instance.AssertionsFrom.Name == configMapName {
+			return true
+		}
+		source := instance.AuthorizationModelFrom
+		if source != nil && source.ConfigMapKeyRef != nil && source.ConfigMapKeyRef.Name == configMapName {
+			return true
+		}
+		modular := instance.ModularAuthorizationModel
+		if modular == nil {
+			continue
+		}
+		if modular.ManifestFrom != nil && modular.ManifestFrom.Name == configMapName {
+			return true
+		}
+		for _, module := range modular.Modules {
+			if module.ContentsFrom != nil && module.ContentsFrom.Name == configMapName {
+				return true
+			}
+		}
+	}
+	return false
+}
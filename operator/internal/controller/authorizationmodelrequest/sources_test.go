@@ -0,0 +1,303 @@
+package authorizationmodelrequest
+
+import (
+	"context"
+	"errors"
+	extensionsv1 "fga-operator/api/v1"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"testing"
+	"time"
+)
+
+func newSourcesTestClient(t *testing.T, objects ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := extensionsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
+
+func newSourcesTestRequest(name string, sources ...*extensionsv1.AuthorizationModelSource) *extensionsv1.AuthorizationModelRequest {
+	instances := make([]extensionsv1.AuthorizationModelRequestInstance, len(sources))
+	for i, source := range sources {
+		instances[i] = extensionsv1.AuthorizationModelRequestInstance{
+			AuthorizationModelFrom: source,
+			Version:                extensionsv1.ModelVersion{Major: 1, Minor: i},
+		}
+	}
+	return &extensionsv1.AuthorizationModelRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       extensionsv1.AuthorizationModelRequestSpec{Instances: instances},
+	}
+}
+
+func configMapKeyRef(name, key string) *extensionsv1.AuthorizationModelSource {
+	return &extensionsv1.AuthorizationModelSource{
+		ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key},
+	}
+}
+
+func secretKeyRef(name, key string) *extensionsv1.AuthorizationModelSource {
+	return &extensionsv1.AuthorizationModelSource{
+		SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key},
+	}
+}
+
+func TestResolveAuthorizationModels(t *testing.T) {
+	// Arrange
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "models", Namespace: "default"},
+		Data:       map[string]string{"model.fga": model},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "models", Namespace: "default"},
+		Data:       map[string][]byte{"model.fga": []byte(modelUpdated)},
+	}
+	reader := newSourcesTestClient(t, configMap, secret)
+	request := newSourcesTestRequest("documents", configMapKeyRef("models", "model.fga"), secretKeyRef("models", "model.fga"))
+
+	// Act
+	err := resolveAuthorizationModels(context.Background(), reader, request)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(model, request.Spec.Instances[0].AuthorizationModel); diff != "" {
+		t.Errorf("config map model mismatch (-expected +actual):\n%s", diff)
+	}
+	if diff := cmp.Diff(modelUpdated, request.Spec.Instances[1].AuthorizationModel); diff != "" {
+		t.Errorf("secret model mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestResolveAuthorizationModelsErrors(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "models", Namespace: "default"},
+		Data:       map[string]string{"model.fga": model, "invalid.fga": "model\n  schema 1.1\ntype user\n  relations\n    define reader [user"},
+	}
+
+	testCases := []struct {
+		description string
+		source      *extensionsv1.AuthorizationModelSource
+		expected    string
+	}{
+		{
+			description: "missing config map",
+			source:      configMapKeyRef("missing", "model.fga"),
+			expected:    "version 1.0.0: config map missing not found",
+		},
+		{
+			description: "missing key",
+			source:      configMapKeyRef("models", "missing.fga"),
+			expected:    "version 1.0.0: key missing.fga not found in config map models",
+		},
+		{
+			description: "missing secret",
+			source:      secretKeyRef("missing", "model.fga"),
+			expected:    "version 1.0.0: secret missing not found",
+		},
+		{
+			description: "no reference",
+			source:      &extensionsv1.AuthorizationModelSource{},
+			expected:    "version 1.0.0: authorizationModelFrom must set one of configMapKeyRef and secretKeyRef",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			reader := newSourcesTestClient(t, configMap.DeepCopy())
+			request := newSourcesTestRequest("documents", testCase.source)
+
+			// Act
+			err := resolveAuthorizationModels(context.Background(), reader, request)
+
+			// Assert
+			var versionErr *versionError
+			if !errors.As(err, &versionErr) {
+				t.Fatalf("expected a version error, got %v", err)
+			}
+			if diff := cmp.Diff(testCase.expected, err.Error()); diff != "" {
+				t.Errorf("error mismatch (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("invalid model", func(t *testing.T) {
+		// Arrange
+		reader := newSourcesTestClient(t, configMap.DeepCopy())
+		request := newSourcesTestRequest("documents", configMapKeyRef("models", "invalid.fga"))
+
+		// Act
+		err := resolveAuthorizationModels(context.Background(), reader, request)
+
+		// Assert
+		var versionErr *versionError
+		if !errors.As(err, &versionErr) {
+			t.Fatalf("expected a version error, got %v", err)
+		}
+	})
+}
+
+func TestResolveJSONAuthorizationModel(t *testing.T) {
+	jsonModel := `{"schema_version": "1.1", "type_definitions": [{"type": "user"}]}`
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "models", Namespace: "default"},
+		Data: map[string]string{
+			"model.json":   jsonModel,
+			"unknown.json": `{"schema_version": "1.1", "type_definitions": [{"type": "user"}], "types": []}`,
+		},
+	}
+
+	testCases := []struct {
+		description   string
+		key           string
+		expectedError bool
+	}{
+		{description: "valid model", key: "model.json"},
+		{description: "unknown field", key: "unknown.json", expectedError: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			reader := newSourcesTestClient(t, configMap.DeepCopy())
+			request := newSourcesTestRequest("documents", configMapKeyRef("models", testCase.key))
+			request.Spec.Instances[0].Format = extensionsv1.FormatJSON
+
+			// Act
+			err := resolveAuthorizationModels(context.Background(), reader, request)
+
+			// Assert
+			if (err != nil) != testCase.expectedError {
+				t.Fatalf("expected error %v, got %v", testCase.expectedError, err)
+			}
+			if err == nil && request.Spec.Instances[0].AuthorizationModel != jsonModel {
+				t.Errorf("expected model %s, got %s", jsonModel, request.Spec.Instances[0].AuthorizationModel)
+			}
+		})
+	}
+}
+
+func TestCheckUnchangedVersions(t *testing.T) {
+	authorizationModel := extensionsv1.NewAuthorizationModel("documents", "default", []extensionsv1.AuthorizationModelDefinition{
+		extensionsv1.NewAuthorizationModelDefinition("id", model, extensionsv1.ModelVersion{Major: 1}),
+	}, time.Now())
+
+	testCases := []struct {
+		description   string
+		model         string
+		version       extensionsv1.ModelVersion
+		expectedError bool
+	}{
+		{description: "same model", model: model, version: extensionsv1.ModelVersion{Major: 1}},
+		{description: "changed model of existing version", model: modelUpdated, version: extensionsv1.ModelVersion{Major: 1}, expectedError: true},
+		{description: "changed model of new version", model: modelUpdated, version: extensionsv1.ModelVersion{Major: 2}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			request := newSourcesTestRequest("documents", configMapKeyRef("models", "model.fga"))
+			request.Spec.Instances[0].AuthorizationModel = testCase.model
+			request.Spec.Instances[0].Version = testCase.version
+
+			// Act
+			err := checkUnchangedVersions(request, &authorizationModel)
+
+			// Assert
+			if (err != nil) != testCase.expectedError {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestRequestsForConfigMap(t *testing.T) {
+	// Arrange
+	referencing := newSourcesTestRequest("referencing", configMapKeyRef("models", "model.fga"))
+	other := newSourcesTestRequest("other", configMapKeyRef("other", "model.fga"))
+	reader := newSourcesTestClient(t, referencing, other)
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "models", Namespace: "default"}}
+
+	// Act
+	requests := requestsForConfigMap(context.Background(), reader, configMap)
+
+	// Assert
+	expected := []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "referencing", Namespace: "default"}}}
+	if diff := cmp.Diff(expected, requests); diff != "" {
+		t.Errorf("requests mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestResolveModularAuthorizationModel(t *testing.T) {
+	// Arrange
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "modules", Namespace: "default"},
+		Data: map[string]string{
+			"fga.mod":  "schema: '1.2'\ncontents:\n  - core.fga\n  - issues.fga\n",
+			"core.fga": "module core\n\ntype user\n\ntype organization\n  relations\n    define member: [user]\n",
+		},
+	}
+	reader := newSourcesTestClient(t, configMap)
+	request := newSourcesTestRequest("documents", nil)
+	request.Spec.Instances[0].ModularAuthorizationModel = &extensionsv1.ModularAuthorizationModel{
+		ManifestFrom: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "modules"}, Key: "fga.mod"},
+		Modules: []extensionsv1.ModuleFile{
+			{Name: "core.fga", ContentsFrom: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "modules"}, Key: "core.fga"}},
+			{Name: "issues.fga", Contents: "module issues\n\ntype issue\n  relations\n    define owner: [organization#member]\n"},
+		},
+	}
+
+	// Act
+	references := referencesConfigMap(request, "modules")
+	err := resolveAuthorizationModels(context.Background(), reader, request)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &extensionsv1.ModularAuthorizationModel{
+		Manifest: configMap.Data["fga.mod"],
+		Modules: []extensionsv1.ModuleFile{
+			{Name: "core.fga", Contents: configMap.Data["core.fga"]},
+			{Name: "issues.fga", Contents: "module issues\n\ntype issue\n  relations\n    define owner: [organization#member]\n"},
+		},
+	}
+	if diff := cmp.Diff(expected, request.Spec.Instances[0].ModularAuthorizationModel); diff != "" {
+		t.Errorf("modular model mismatch (-expected +actual):\n%s", diff)
+	}
+	if !references {
+		t.Errorf("expected request to reference config map modules")
+	}
+}
+
+func TestResolveModularAuthorizationModelWithMissingModule(t *testing.T) {
+	// Arrange
+	reader := newSourcesTestClient(t)
+	request := newSourcesTestRequest("documents", nil)
+	request.Spec.Instances[0].ModularAuthorizationModel = &extensionsv1.ModularAuthorizationModel{
+		Manifest: "schema: '1.2'\ncontents:\n  - core.fga\n  - issues.fga\n",
+		Modules:  []extensionsv1.ModuleFile{{Name: "core.fga", Contents: "module core\n\ntype user\n"}},
+	}
+
+	// Act
+	err := resolveAuthorizationModels(context.Background(), reader, request)
+
+	// Assert
+	expected := "version 1.0.0: fga.mod: module file issues.fga is listed in contents but not provided"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
@@ -0,0 +1,158 @@
+package authorizationmodelrequest
+
+import (
+	"errors"
+	extensionsv1 "fga-operator/api/v1"
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+	"time"
+)
+
+const (
+	conditionReasonSynchronized  = "Synchronized"
+	conditionReasonStoreNotReady = "StoreNotReady"
+)
+
+// versionError is returned when synchronizing a single version of the request fails, such that the
+// error can be recorded on the status of that version.
+type versionError struct {
+	version extensionsv1.ModelVersion
+	err     error
+}
+
+func (e *versionError) Error() string {
+	return fmt.Sprintf("version %s: %v", e.version.String(), e.err)
+}
+
+func (e *versionError) Unwrap() error {
+	return e.err
+}
+
+// eventReason returns the reason of an event for the error, which is the given reason unless assertions
+// failed or breaking changes were rejected.
+func eventReason(err error, reason EventReason) EventReason {
+	var failedAssertions *assertionsError
+	var breakingChanges *breakingChangesError
+	switch {
+	case errors.As(err, &failedAssertions):
+		return EventReasonAuthorizationModelAssertionsFailed
+	case errors.As(err, &breakingChanges):
+		return EventReasonBreakingChangesDetected
+	}
+	return reason
+}
+
+// setSynchronizedStatus sets the status of a request which has been fully synchronized.
+func setSynchronizedStatus(
+	request *extensionsv1.AuthorizationModelRequest,
+	authorizationModel *extensionsv1.AuthorizationModel,
+	now time.Time) {
+	status := &request.Status
+	status.State = extensionsv1.Synchronized
+	status.ObservedGeneration = request.Generation
+
+	existing := make(map[extensionsv1.ModelVersion]extensionsv1.AuthorizationModelInstance, len(authorizationModel.Spec.Instances))
+	for _, instance := range authorizationModel.Spec.Instances {
+		existing[instance.Version] = instance
+	}
+	versions := make([]extensionsv1.AuthorizationModelVersionStatus, 0, len(request.Spec.Instances))
+	for _, requested := range request.Spec.Instances {
+		instance := existing[requested.Version]
+		versions = append(versions, extensionsv1.AuthorizationModelVersionStatus{
+			Version:   requested.Version.String(),
+			Id:        instance.Id,
+			Origin:    instance.Origin,
+			CreatedAt: instance.CreatedAt,
+		})
+	}
+	sortVersionStatuses(versions)
+	status.Versions = versions
+
+	for _, conditionType := range []string{extensionsv1.ConditionStoreReady, extensionsv1.ConditionModelsSynced, extensionsv1.ConditionReady} {
+		setCondition(status, conditionType, metav1.ConditionTrue, conditionReasonSynchronized, "", now)
+	}
+}
+
+// setFailedStatus sets the status of a request which failed to synchronize. The condition which is
+// set to false is derived from the event reason.
+func setFailedStatus(
+	request *extensionsv1.AuthorizationModelRequest,
+	eventReason EventReason,
+	err error,
+	now time.Time) {
+	status := &request.Status
+	status.State = extensionsv1.SynchronizationFailed
+	status.ObservedGeneration = request.Generation
+
+	switch eventReason {
+	case EventReasonClientInitializationFailed, EventReasonStoreFailed:
+		setCondition(status, extensionsv1.ConditionStoreReady, metav1.ConditionFalse, string(eventReason), err.Error(), now)
+		setCondition(status, extensionsv1.ConditionModelsSynced, metav1.ConditionUnknown, conditionReasonStoreNotReady, "", now)
+	default:
+		setCondition(status, extensionsv1.ConditionModelsSynced, metav1.ConditionFalse, string(eventReason), err.Error(), now)
+	}
+	setCondition(status, extensionsv1.ConditionReady, metav1.ConditionFalse, string(eventReason), err.Error(), now)
+
+	var failedVersion *versionError
+	if errors.As(err, &failedVersion) {
+		setVersionError(status, failedVersion)
+	}
+}
+
+func setVersionError(status *extensionsv1.AuthorizationModelRequestStatus, failedVersion *versionError) {
+	version := failedVersion.version.String()
+	var failedAssertions []string
+	var assertionsFailure *assertionsError
+	if errors.As(failedVersion.err, &assertionsFailure) {
+		failedAssertions = assertionsFailure.failed[:min(len(assertionsFailure.failed), maxFailedAssertions)]
+	}
+	for i := range status.Versions {
+		if status.Versions[i].Version == version {
+			status.Versions[i].LastError = failedVersion.err.Error()
+			status.Versions[i].FailedAssertions = failedAssertions
+			return
+		}
+	}
+	status.Versions = append(status.Versions, extensionsv1.AuthorizationModelVersionStatus{
+		Version:          version,
+		LastError:        failedVersion.err.Error(),
+		FailedAssertions: failedAssertions,
+	})
+	sortVersionStatuses(status.Versions)
+}
+
+func setCondition(
+	status *extensionsv1.AuthorizationModelRequestStatus,
+	conditionType string,
+	conditionStatus metav1.ConditionStatus,
+	reason, message string,
+	now time.Time) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: status.ObservedGeneration,
+		LastTransitionTime: metav1.NewTime(now),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// sortVersionStatuses sorts with the latest version first, such that printer columns can show it.
+func sortVersionStatuses(versions []extensionsv1.AuthorizationModelVersionStatus) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, errA := extensionsv1.ModelVersionFromString(versions[i].Version)
+		b, errB := extensionsv1.ModelVersionFromString(versions[j].Version)
+		if errA != nil || errB != nil {
+			return versions[i].Version > versions[j].Version
+		}
+		if a.Major != b.Major {
+			return a.Major > b.Major
+		}
+		if a.Minor != b.Minor {
+			return a.Minor > b.Minor
+		}
+		return a.Patch > b.Patch
+	})
+}
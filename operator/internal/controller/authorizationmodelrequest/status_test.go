@@ -0,0 +1,145 @@
+package authorizationmodelrequest
+
+import (
+	extensionsv1 "fga-operator/api/v1"
+	"fga-operator/internal/openfga"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func newStatusTestRequest(versions ...extensionsv1.ModelVersion) *extensionsv1.AuthorizationModelRequest {
+	instances := make([]extensionsv1.AuthorizationModelRequestInstance, len(versions))
+	for i, version := range versions {
+		instances[i] = extensionsv1.AuthorizationModelRequestInstance{Version: version}
+	}
+	return &extensionsv1.AuthorizationModelRequest{
+		ObjectMeta: metav1.ObjectMeta{Generation: 3},
+		Spec:       extensionsv1.AuthorizationModelRequestSpec{Instances: instances},
+	}
+}
+
+func conditionStatuses(status extensionsv1.AuthorizationModelRequestStatus) map[string]metav1.ConditionStatus {
+	statuses := make(map[string]metav1.ConditionStatus)
+	for _, condition := range status.Conditions {
+		statuses[condition.Type] = condition.Status
+	}
+	return statuses
+}
+
+func TestSetSynchronizedStatus(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	first := extensionsv1.ModelVersion{Major: 1}
+	second := extensionsv1.ModelVersion{Major: 1, Minor: 2}
+	request := newStatusTestRequest(first, second)
+	adopted := extensionsv1.NewAuthorizationModelDefinition("id-2", "", second)
+	adopted.Origin = extensionsv1.ModelOriginAdopted
+	authorizationModel := extensionsv1.NewAuthorizationModel("foo", "default", []extensionsv1.AuthorizationModelDefinition{
+		extensionsv1.NewAuthorizationModelDefinition("id-1", "", first),
+		adopted,
+	}, now)
+	setFailedStatus(request, EventReasonAuthorizationModelCreationFailed, &versionError{version: second, err: fmt.Errorf("error")}, now)
+
+	// Act
+	setSynchronizedStatus(request, &authorizationModel, now)
+
+	// Assert
+	expectedVersions := []extensionsv1.AuthorizationModelVersionStatus{
+		{Version: "1.2.0", Id: "id-2", Origin: extensionsv1.ModelOriginAdopted, CreatedAt: &metav1.Time{Time: now}},
+		{Version: "1.0.0", Id: "id-1", CreatedAt: &metav1.Time{Time: now}},
+	}
+	if diff := cmp.Diff(expectedVersions, request.Status.Versions); diff != "" {
+		t.Errorf("versions mismatch (-expected +actual):\n%s", diff)
+	}
+	expectedConditions := map[string]metav1.ConditionStatus{
+		extensionsv1.ConditionStoreReady:   metav1.ConditionTrue,
+		extensionsv1.ConditionModelsSynced: metav1.ConditionTrue,
+		extensionsv1.ConditionReady:        metav1.ConditionTrue,
+	}
+	if diff := cmp.Diff(expectedConditions, conditionStatuses(request.Status)); diff != "" {
+		t.Errorf("conditions mismatch (-expected +actual):\n%s", diff)
+	}
+	if request.Status.State != extensionsv1.Synchronized {
+		t.Errorf("expected state %s, got %s", extensionsv1.Synchronized, request.Status.State)
+	}
+	if request.Status.ObservedGeneration != 3 {
+		t.Errorf("expected observed generation 3, got %d", request.Status.ObservedGeneration)
+	}
+}
+
+func TestSetFailedStatus(t *testing.T) {
+	testCases := []struct {
+		description        string
+		eventReason        EventReason
+		err                error
+		expectedConditions map[string]metav1.ConditionStatus
+		expectedVersions   []extensionsv1.AuthorizationModelVersionStatus
+	}{
+		{
+			description: "store failure",
+			eventReason: EventReasonStoreFailed,
+			err:         fmt.Errorf("store error"),
+			expectedConditions: map[string]metav1.ConditionStatus{
+				extensionsv1.ConditionStoreReady:   metav1.ConditionFalse,
+				extensionsv1.ConditionModelsSynced: metav1.ConditionUnknown,
+				extensionsv1.ConditionReady:        metav1.ConditionFalse,
+			},
+		},
+		{
+			description: "version failure",
+			eventReason: EventReasonAuthorizationModelCreationFailed,
+			err:         &versionError{version: extensionsv1.ModelVersion{Major: 2}, err: fmt.Errorf("model error")},
+			expectedConditions: map[string]metav1.ConditionStatus{
+				extensionsv1.ConditionModelsSynced: metav1.ConditionFalse,
+				extensionsv1.ConditionReady:        metav1.ConditionFalse,
+			},
+			expectedVersions: []extensionsv1.AuthorizationModelVersionStatus{
+				{Version: "2.0.0", LastError: "model error"},
+			},
+		},
+		{
+			description: "assertions failure",
+			eventReason: EventReasonAuthorizationModelAssertionsFailed,
+			err: &versionError{
+				version: extensionsv1.ModelVersion{Major: 2},
+				err:     openfga.Permanent(&assertionsError{failed: []string{"check failed"}, total: 2}),
+			},
+			expectedConditions: map[string]metav1.ConditionStatus{
+				extensionsv1.ConditionModelsSynced: metav1.ConditionFalse,
+				extensionsv1.ConditionReady:        metav1.ConditionFalse,
+			},
+			expectedVersions: []extensionsv1.AuthorizationModelVersionStatus{
+				{Version: "2.0.0", LastError: "1 of 2 assertions failed, first: check failed", FailedAssertions: []string{"check failed"}},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			request := newStatusTestRequest(extensionsv1.ModelVersion{Major: 2})
+
+			// Act
+			setFailedStatus(request, testCase.eventReason, testCase.err, time.Now())
+
+			// Assert
+			if diff := cmp.Diff(testCase.expectedConditions, conditionStatuses(request.Status)); diff != "" {
+				t.Errorf("conditions mismatch (-expected +actual):\n%s", diff)
+			}
+			if diff := cmp.Diff(testCase.expectedVersions, request.Status.Versions); diff != "" {
+				t.Errorf("versions mismatch (-expected +actual):\n%s", diff)
+			}
+			ready := meta.FindStatusCondition(request.Status.Conditions, extensionsv1.ConditionReady)
+			if ready.Reason != string(testCase.eventReason) || ready.Message != testCase.err.Error() {
+				t.Errorf("unexpected ready condition %v", ready)
+			}
+			if request.Status.State != extensionsv1.SynchronizationFailed {
+				t.Errorf("expected state %s, got %s", extensionsv1.SynchronizationFailed, request.Status.State)
+			}
+		})
+	}
+}
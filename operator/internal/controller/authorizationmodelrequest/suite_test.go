@@ -118,6 +118,7 @@ var _ = AfterSuite(func() {
 func setupMockFactory() fgainternal.PermissionServiceFactory {
 	mockFactory := fgainternal.NewMockPermissionServiceFactory(goMockController)
 	mockService := fgainternal.NewMockPermissionService(goMockController)
+	mockStoreService := fgainternal.NewMockStoreService(goMockController)
 	store := fgainternal.Store{
 		Id:        "foo",
 		Name:      resourceName,
@@ -128,10 +129,10 @@ func setupMockFactory() fgainternal.PermissionServiceFactory {
 	mockFactory.EXPECT().GetService(gomock.Any()).Return(mockService, nil).AnyTimes()
 	mockService.EXPECT().CheckExistingStoresByName(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
 	mockService.EXPECT().CreateStore(gomock.Any(), gomock.Any(), gomock.Any()).Return(&store, nil).AnyTimes()
-	mockService.EXPECT().SetStoreId(gomock.Any()).AnyTimes()
-	mockService.EXPECT().
+	mockService.EXPECT().ForStore(gomock.Any()).Return(mockStoreService, nil).AnyTimes()
+	mockStoreService.EXPECT().
 		CreateAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(authModelId, nil).
+		Return(&fgainternal.AuthorizationModel{Id: authModelId}, nil).
 		AnyTimes()
 	return mockFactory
 }
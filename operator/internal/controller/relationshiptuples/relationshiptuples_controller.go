@@ -0,0 +1,354 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package relationshiptuples
+
+import (
+	"context"
+	"fga-operator/internal/connections"
+	"fga-operator/internal/openfga"
+	"fmt"
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	extensionsv1 "fga-operator/api/v1"
+)
+
+const (
+	EventRecorderLabel = "RelationshipTuplesReconciler"
+)
+
+type EventReason string
+
+const (
+	EventReasonClientInitializationFailed EventReason = "ClientInitializationFailed"
+	EventReasonStoreNotFound              EventReason = "StoreNotFound"
+	EventReasonTuplesApplyFailed          EventReason = "TuplesApplyFailed"
+	EventReasonTuplesFailed               EventReason = "TuplesFailed"
+	EventReasonTuplesDeletionFailed       EventReason = "TuplesDeletionFailed"
+	EventReasonStatusUpdateFailed         EventReason = "StatusUpdateFailed"
+	EventReasonFinalizerUpdateFailed      EventReason = "FinalizerUpdateFailed"
+)
+
+const (
+	conditionReasonApplied       = "Applied"
+	conditionReasonTuplesFailed  = "TuplesFailed"
+	conditionReasonApplyFailed   = "ApplyFailed"
+	conditionReasonStoreNotFound = "StoreNotFound"
+)
+
+// RelationshipTuplesReconciler writes relationship tuples to the stores in OpenFGA
+type RelationshipTuplesReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	openfga.PermissionServiceFactory
+	openfga.Config
+	Clock
+	// ResyncInterval is the interval at which the tuples are applied again, such that tuples deleted in
+	// OpenFGA by others are restored.
+	ResyncInterval *time.Duration
+	// APIReader reads connections and their secrets directly from the API server, such that secrets
+	// aren't cached. Defaults to the client.
+	APIReader client.Reader
+}
+
+type Clock interface {
+	Now() time.Time
+}
+
+//+kubebuilder:rbac:groups=extensions.fga-operator,resources=relationshiptuples,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=extensions.fga-operator,resources=relationshiptuples/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=extensions.fga-operator,resources=relationshiptuples/finalizers,verbs=update
+//+kubebuilder:rbac:groups=extensions.fga-operator,resources=stores,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get
+//+kubebuilder:rbac:groups=extensions.fga-operator,resources=fgaconnections;clusterfgaconnections,verbs=get
+
+// Reconcile writes the tuples to the referenced store, and deletes the tuples written by the operator which
+// were removed from the spec. When the resource is deleted, the tuples written by the operator are deleted
+// if the deletion policy is "Delete".
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.17.3/pkg/reconcile
+func (r *RelationshipTuplesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.V(1).Info("Reconciliation triggered for relationship tuples")
+	reconcileTimestamp := r.Now()
+
+	tuples := &extensionsv1.RelationshipTuples{}
+	if err := r.Get(ctx, req.NamespacedName, tuples); err != nil {
+		logger.Error(err, "unable to fetch relationship tuples", "relationshipTuplesName", req.Name)
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !tuples.DeletionTimestamp.IsZero() {
+		return openfga.ReconcileResult(r.finalizeTuples(ctx, tuples, &logger))
+	}
+
+	if err := r.ensureFinalizer(ctx, tuples, &logger); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	store, err := r.getStore(ctx, tuples)
+	if errors.IsNotFound(err) {
+		message := fmt.Sprintf("store %s does not exist", tuples.Spec.StoreRef.Name)
+		r.Recorder.Event(tuples, v1.EventTypeWarning, string(EventReasonStoreNotFound), message)
+		logger.V(0).Info(message, "relationshipTuplesName", tuples.Name)
+		setReady(tuples, metav1.ConditionFalse, conditionReasonStoreNotFound, message, reconcileTimestamp)
+		return ctrl.Result{}, r.updateStatus(ctx, tuples, &logger)
+	}
+	if err != nil {
+		logger.Error(err, "unable to fetch store", "storeName", tuples.Spec.StoreRef.Name)
+		return ctrl.Result{}, err
+	}
+
+	storeService, err := r.getStoreService(ctx, store)
+	if err != nil {
+		r.createEvent(tuples, EventReasonClientInitializationFailed, err)
+		logger.Error(err, "unable to get permission service")
+		return r.fail(ctx, tuples, err, reconcileTimestamp, &logger)
+	}
+
+	// The tuples written to a previous store, e.g. before the store was recreated, don't exist in this one.
+	managed := tuples.Status.ManagedTuples
+	if tuples.Status.StoreId != store.Spec.Id {
+		managed = nil
+	}
+	result, err := applyTuples(ctx, storeService, unique(tuples.Spec.Tuples), managed, &logger)
+	setApplied(tuples, store.Spec.Id, result, reconcileTimestamp)
+	if err != nil {
+		r.createEvent(tuples, EventReasonTuplesApplyFailed, err)
+		logger.Error(err, "unable to apply relationship tuples", "storeId", store.Spec.Id)
+		return r.fail(ctx, tuples, err, reconcileTimestamp, &logger)
+	}
+
+	if len(result.errors) > 0 {
+		message := fmt.Sprintf("%d tuples failed, e.g. %s: %s", len(result.errors), result.errors[0].RelationshipTuple, result.errors[0].Message)
+		r.Recorder.Event(tuples, v1.EventTypeWarning, string(EventReasonTuplesFailed), message)
+		setReady(tuples, metav1.ConditionFalse, conditionReasonTuplesFailed, message, reconcileTimestamp)
+	} else {
+		setReady(tuples, metav1.ConditionTrue, conditionReasonApplied, "", reconcileTimestamp)
+	}
+	logger.V(0).Info("Applied relationship tuples", "storeId", store.Spec.Id,
+		"applied", result.applied, "written", result.written, "pruned", result.pruned, "failed", len(result.errors))
+
+	if err := r.updateStatus(ctx, tuples, &logger); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: *r.ResyncInterval}, nil
+}
+
+// fail records the failure in the status, and returns the result of the failed reconciliation.
+func (r *RelationshipTuplesReconciler) fail(
+	ctx context.Context,
+	tuples *extensionsv1.RelationshipTuples,
+	err error,
+	now time.Time,
+	log *logr.Logger) (ctrl.Result, error) {
+	setReady(tuples, metav1.ConditionFalse, conditionReasonApplyFailed, err.Error(), now)
+	if statusError := r.updateStatus(ctx, tuples, log); statusError != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update status: %w with prior error %v", statusError, err)
+	}
+	return openfga.ReconcileResult(err)
+}
+
+// ensureFinalizer adds the finalizer to tuples with the deletion policy "Delete", and removes it from
+// tuples which no longer have that policy.
+func (r *RelationshipTuplesReconciler) ensureFinalizer(ctx context.Context, tuples *extensionsv1.RelationshipTuples, log *logr.Logger) error {
+	var changed bool
+	if tuples.Spec.DeletionPolicy != extensionsv1.DeletionPolicyRetain {
+		changed = controllerutil.AddFinalizer(tuples, extensionsv1.RelationshipTuplesFinalizer)
+	} else {
+		changed = controllerutil.RemoveFinalizer(tuples, extensionsv1.RelationshipTuplesFinalizer)
+	}
+	if !changed {
+		return nil
+	}
+	if err := r.Update(ctx, tuples); err != nil {
+		r.createEvent(tuples, EventReasonFinalizerUpdateFailed, err)
+		log.Error(err, "unable to update finalizer of relationship tuples", "relationshipTuplesName", tuples.Name)
+		return err
+	}
+	return nil
+}
+
+// finalizeTuples deletes the tuples written by the operator when the deletion policy is "Delete", and then
+// removes the finalizer such that Kubernetes can delete the resource. Tuples of a store which no longer
+// exists, or which fail to be deleted permanently, are left behind.
+func (r *RelationshipTuplesReconciler) finalizeTuples(ctx context.Context, tuples *extensionsv1.RelationshipTuples, log *logr.Logger) error {
+	if !controllerutil.ContainsFinalizer(tuples, extensionsv1.RelationshipTuplesFinalizer) {
+		return nil
+	}
+
+	if tuples.Spec.DeletionPolicy != extensionsv1.DeletionPolicyRetain && len(tuples.Status.ManagedTuples) > 0 {
+		store, err := r.getStore(ctx, tuples)
+		if client.IgnoreNotFound(err) != nil {
+			log.Error(err, "unable to fetch store", "storeName", tuples.Spec.StoreRef.Name)
+			return err
+		}
+		if err == nil && store.DeletionTimestamp.IsZero() && store.Spec.Id == tuples.Status.StoreId {
+			if err := r.deleteManagedTuples(ctx, tuples, store, log); err != nil {
+				return err
+			}
+		}
+	}
+
+	controllerutil.RemoveFinalizer(tuples, extensionsv1.RelationshipTuplesFinalizer)
+	if err := r.Update(ctx, tuples); err != nil {
+		r.createEvent(tuples, EventReasonFinalizerUpdateFailed, err)
+		log.Error(err, "unable to remove finalizer of relationship tuples", "relationshipTuplesName", tuples.Name)
+		return err
+	}
+	return nil
+}
+
+func (r *RelationshipTuplesReconciler) deleteManagedTuples(
+	ctx context.Context,
+	tuples *extensionsv1.RelationshipTuples,
+	store *extensionsv1.Store,
+	log *logr.Logger) error {
+	storeService, err := r.getStoreService(ctx, store)
+	if err != nil {
+		r.createEvent(tuples, EventReasonClientInitializationFailed, err)
+		log.Error(err, "unable to get permission service")
+		return err
+	}
+	result, err := applyTuples(ctx, storeService, nil, tuples.Status.ManagedTuples, log)
+	if err != nil {
+		r.createEvent(tuples, EventReasonTuplesDeletionFailed, err)
+		log.Error(err, "unable to delete relationship tuples", "storeId", store.Spec.Id)
+		return err
+	}
+	for _, failure := range result.errors {
+		r.Recorder.Event(tuples, v1.EventTypeWarning, string(EventReasonTuplesDeletionFailed),
+			fmt.Sprintf("tuple %s could not be deleted: %s", failure.RelationshipTuple, failure.Message))
+	}
+	log.V(0).Info("Deleted relationship tuples", "storeId", store.Spec.Id, "pruned", result.pruned, "failed", len(result.errors))
+	return nil
+}
+
+func (r *RelationshipTuplesReconciler) getStore(ctx context.Context, tuples *extensionsv1.RelationshipTuples) (*extensionsv1.Store, error) {
+	store := &extensionsv1.Store{}
+	err := r.Get(ctx, types.NamespacedName{Name: tuples.Spec.StoreRef.Name, Namespace: tuples.Namespace}, store)
+	return store, err
+}
+
+// getStoreService returns the store service of the store on the connection it lives on.
+func (r *RelationshipTuplesReconciler) getStoreService(ctx context.Context, store *extensionsv1.Store) (openfga.StoreService, error) {
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	config, err := connections.Resolve(ctx, reader, r.Config, store.Namespace, store.Spec.ConnectionRef)
+	if err != nil {
+		return nil, err
+	}
+	service, err := r.PermissionServiceFactory.GetService(config)
+	if err != nil {
+		return nil, err
+	}
+	return service.ForStore(store.Spec.Id)
+}
+
+func (r *RelationshipTuplesReconciler) updateStatus(ctx context.Context, tuples *extensionsv1.RelationshipTuples, log *logr.Logger) error {
+	if err := r.Status().Update(ctx, tuples); err != nil {
+		r.createEvent(tuples, EventReasonStatusUpdateFailed, err)
+		log.Error(err, "unable to update relationship tuples status", "relationshipTuplesName", tuples.Name)
+		return err
+	}
+	return nil
+}
+
+func (r *RelationshipTuplesReconciler) createEvent(tuples *extensionsv1.RelationshipTuples, eventReason EventReason, err error) {
+	r.Recorder.Event(
+		tuples,
+		v1.EventTypeWarning,
+		string(eventReason),
+		err.Error(),
+	)
+}
+
+func setApplied(tuples *extensionsv1.RelationshipTuples, storeId string, result applyResult, now time.Time) {
+	tuples.Status.StoreId = storeId
+	tuples.Status.ObservedGeneration = tuples.Generation
+	tuples.Status.Applied = int32(result.applied)
+	tuples.Status.Written = int32(result.written)
+	tuples.Status.Pruned = int32(result.pruned)
+	tuples.Status.Failed = int32(len(result.errors))
+	tuples.Status.LastApplied = &metav1.Time{Time: now}
+	tuples.Status.ManagedTuples = result.managed
+	tuples.Status.Errors = result.errors[:min(len(result.errors), maxStatusErrors)]
+}
+
+func setReady(tuples *extensionsv1.RelationshipTuples, conditionStatus metav1.ConditionStatus, reason, message string, now time.Time) {
+	meta.SetStatusCondition(&tuples.Status.Conditions, metav1.Condition{
+		Type:               extensionsv1.RelationshipTuplesConditionReady,
+		Status:             conditionStatus,
+		ObservedGeneration: tuples.Generation,
+		LastTransitionTime: metav1.NewTime(now),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// requestsForStore returns the relationship tuples referencing the store.
+func requestsForStore(ctx context.Context, c client.Client, store client.Object) []reconcile.Request {
+	list := &extensionsv1.RelationshipTuplesList{}
+	if err := c.List(ctx, list, client.InNamespace(store.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "unable to list relationship tuples", "storeName", store.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, tuples := range list.Items {
+		if tuples.Spec.StoreRef.Name == store.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&tuples)})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RelationshipTuplesReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Clock == nil {
+		r.Clock = clock.RealClock{}
+	}
+
+	// The ID of a store changes when it's recreated, which changes its generation.
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&extensionsv1.RelationshipTuples{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&extensionsv1.Store{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, store client.Object) []reconcile.Request {
+			return requestsForStore(ctx, r.Client, store)
+		}), builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package relationshiptuples
+
+import (
+	"context"
+	extensionsv1 "fga-operator/api/v1"
+	fgainternal "fga-operator/internal/openfga"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"time"
+)
+
+const (
+	storeId  = "01HVMMBCMGZNT3SED4Z17ECXCA"
+	duration = time.Millisecond * 500
+	interval = time.Millisecond * 100
+)
+
+var _ = Describe("RelationshipTuples Controller", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: namespaceName,
+		}
+		request := reconcile.Request{NamespacedName: typeNamespacedName}
+		admin := extensionsv1.RelationshipTuple{User: "user:anne", Relation: "admin", Object: "organization:acme"}
+		member := extensionsv1.RelationshipTuple{User: "user:bob", Relation: "member", Object: "organization:acme"}
+		resyncInterval := 5 * time.Minute
+
+		createTuples := func(policy extensionsv1.DeletionPolicy, tuples ...extensionsv1.RelationshipTuple) {
+			resource := &extensionsv1.RelationshipTuples{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: namespaceName},
+				Spec: extensionsv1.RelationshipTuplesSpec{
+					StoreRef:       extensionsv1.StoreReference{Name: storeName},
+					Tuples:         tuples,
+					DeletionPolicy: policy,
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		}
+
+		getTuples := func() *extensionsv1.RelationshipTuples {
+			tuples := &extensionsv1.RelationshipTuples{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, tuples)).To(Succeed())
+			return tuples
+		}
+
+		newReconciler := func(storeService fgainternal.StoreService, recorder record.EventRecorder) *RelationshipTuplesReconciler {
+			mockService := fgainternal.NewMockPermissionService(goMockController)
+			mockService.EXPECT().ForStore(storeId).Return(storeService, nil).AnyTimes()
+			mockFactory := fgainternal.NewMockPermissionServiceFactory(goMockController)
+			mockFactory.EXPECT().GetService(gomock.Any()).Return(mockService, nil).AnyTimes()
+			return &RelationshipTuplesReconciler{
+				Client:                   k8sClient,
+				Scheme:                   k8sClient.Scheme(),
+				Recorder:                 recorder,
+				Clock:                    MockClock{},
+				PermissionServiceFactory: mockFactory,
+				ResyncInterval:           &resyncInterval,
+			}
+		}
+
+		BeforeEach(func() {
+			Expect(k8sClient.Create(ctx, extensionsv1.NewStore(storeName, namespaceName, storeId, time.Now()))).To(Succeed())
+		})
+
+		AfterEach(func() {
+			tuples := &extensionsv1.RelationshipTuples{}
+			if err := k8sClient.Get(ctx, typeNamespacedName, tuples); err == nil {
+				tuples.Finalizers = nil
+				Expect(k8sClient.Update(ctx, tuples)).To(Succeed())
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, tuples))).To(Succeed())
+			}
+			store := &extensionsv1.Store{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: storeName, Namespace: namespaceName}, store); err == nil {
+				Expect(k8sClient.Delete(ctx, store)).To(Succeed())
+			}
+		})
+
+		It("given missing tuples then write them and set ready", func() {
+			// Arrange
+			createTuples(extensionsv1.DeletionPolicyDelete, admin, member)
+			mockStoreService := fgainternal.NewMockStoreService(goMockController)
+			mockStoreService.EXPECT().Read(gomock.Any(), fgainternal.Tuple{Object: "organization:acme"}).Return(nil, nil)
+			mockStoreService.EXPECT().WriteTuples(gomock.Any(), []fgainternal.Tuple{
+				{User: "user:anne", Relation: "admin", Object: "organization:acme"},
+				{User: "user:bob", Relation: "member", Object: "organization:acme"},
+			}, gomock.Any()).Return(nil)
+			fakeRecorder := record.NewFakeRecorder(5)
+
+			// Act
+			result, err := newReconciler(mockStoreService, fakeRecorder).Reconcile(ctx, request)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(resyncInterval))
+			tuples := getTuples()
+			Expect(tuples.Finalizers).To(ContainElement(extensionsv1.RelationshipTuplesFinalizer))
+			Expect(tuples.Status.StoreId).To(Equal(storeId))
+			Expect(tuples.Status.Applied).To(Equal(int32(2)))
+			Expect(tuples.Status.Written).To(Equal(int32(2)))
+			Expect(tuples.Status.ManagedTuples).To(Equal([]extensionsv1.RelationshipTuple{admin, member}))
+			Expect(meta.IsStatusConditionTrue(tuples.Status.Conditions, extensionsv1.RelationshipTuplesConditionReady)).To(BeTrue())
+			validateNoEvents(fakeRecorder.Events)
+		})
+
+		It("given failing tuple then report it in status", func() {
+			// Arrange
+			createTuples(extensionsv1.DeletionPolicyRetain, admin)
+			mockStoreService := fgainternal.NewMockStoreService(goMockController)
+			mockStoreService.EXPECT().Read(gomock.Any(), gomock.Any()).Return(nil, nil)
+			mockStoreService.EXPECT().WriteTuples(gomock.Any(), gomock.Any(), gomock.Any()).Return(fgainternal.Permanent(fmt.Errorf("type 'organization' not found")))
+			fakeRecorder := record.NewFakeRecorder(5)
+
+			// Act
+			_, err := newReconciler(mockStoreService, fakeRecorder).Reconcile(ctx, request)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			tuples := getTuples()
+			Expect(tuples.Finalizers).To(BeEmpty())
+			Expect(tuples.Status.Failed).To(Equal(int32(1)))
+			Expect(tuples.Status.Errors).To(HaveLen(1))
+			Expect(tuples.Status.Errors[0].RelationshipTuple).To(Equal(admin))
+			Expect(tuples.Status.ManagedTuples).To(BeEmpty())
+			Expect(meta.IsStatusConditionFalse(tuples.Status.Conditions, extensionsv1.RelationshipTuplesConditionReady)).To(BeTrue())
+			validateEvent(fakeRecorder.Events, EventReasonTuplesFailed)
+		})
+
+		It("given missing store then set not ready", func() {
+			// Arrange
+			Expect(k8sClient.Delete(ctx, &extensionsv1.Store{ObjectMeta: metav1.ObjectMeta{Name: storeName, Namespace: namespaceName}})).To(Succeed())
+			createTuples(extensionsv1.DeletionPolicyRetain, admin)
+			mockStoreService := fgainternal.NewMockStoreService(goMockController)
+			fakeRecorder := record.NewFakeRecorder(5)
+
+			// Act
+			_, err := newReconciler(mockStoreService, fakeRecorder).Reconcile(ctx, request)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			ready := meta.FindStatusCondition(getTuples().Status.Conditions, extensionsv1.RelationshipTuplesConditionReady)
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal(conditionReasonStoreNotFound))
+			validateEvent(fakeRecorder.Events, EventReasonStoreNotFound)
+		})
+
+		It("given deletion policy delete when tuples are deleted then delete managed tuples", func() {
+			// Arrange
+			createTuples(extensionsv1.DeletionPolicyDelete, admin)
+			mockStoreService := fgainternal.NewMockStoreService(goMockController)
+			mockStoreService.EXPECT().Read(gomock.Any(), gomock.Any()).Return(nil, nil)
+			mockStoreService.EXPECT().WriteTuples(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			_, err := newReconciler(mockStoreService, record.NewFakeRecorder(5)).Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, getTuples())).To(Succeed())
+
+			adminTuple := fgainternal.Tuple{User: "user:anne", Relation: "admin", Object: "organization:acme"}
+			mockStoreService.EXPECT().Read(gomock.Any(), fgainternal.Tuple{Object: adminTuple.Object}).Return([]fgainternal.Tuple{adminTuple}, nil)
+			mockStoreService.EXPECT().DeleteTuples(gomock.Any(), []fgainternal.Tuple{adminTuple}, gomock.Any()).Return(nil)
+
+			// Act
+			_, err = newReconciler(mockStoreService, record.NewFakeRecorder(5)).Reconcile(ctx, request)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, typeNamespacedName, &extensionsv1.RelationshipTuples{})
+				return errors.IsNotFound(err)
+			}, duration, interval).Should(BeTrue())
+		})
+	})
+})
+
+func validateEvent(events <-chan string, eventReason EventReason) {
+	select {
+	case event := <-events:
+		Expect(event).To(ContainSubstring(string(eventReason)))
+	default:
+		Fail("Expected an event, but no events were recorded")
+	}
+	validateNoEvents(events)
+}
+
+func validateNoEvents(events <-chan string) {
+	Consistently(func() string {
+		select {
+		case event := <-events:
+			return event
+		default:
+			return ""
+		}
+	}, duration, interval).Should(BeEmpty())
+}
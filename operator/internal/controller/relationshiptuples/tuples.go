@@ -0,0 +1,251 @@
+package relationshiptuples
+
+import (
+	"context"
+	extensionsv1 "fga-operator/api/v1"
+	"fga-operator/internal/openfga"
+	"github.com/go-logr/logr"
+)
+
+// maxStatusErrors is the number of tuple errors kept in the status, such that it doesn't grow with the
+// number of tuples.
+const maxStatusErrors = 20
+
+// applyResult is the outcome of applying tuples to a store.
+type applyResult struct {
+	applied int
+	written int
+	pruned  int
+
+	// managed are the tuples written by the operator after applying them.
+	managed []extensionsv1.RelationshipTuple
+
+	// errors of the tuples which failed permanently.
+	errors []extensionsv1.RelationshipTupleError
+}
+
+// applyTuples writes the desired tuples which don't exist in the store, and deletes the managed tuples which
+// are no longer desired. Tuples which already exist aren't managed, such that they are never deleted.
+//
+// Tuples failing permanently, e.g. because their type isn't in the authorization model, are reported in the
+// errors of the result. A transient failure stops applying the tuples, and is returned with the result so
+// far. The tuples of the chunks which were written are managed, and so are the tuples of the chunk which
+// failed transiently, since they didn't exist before and may have been written, such that they are deleted
+// when they are no longer desired. Tuples which weren't sent to OpenFGA aren't managed.
+func applyTuples(
+	ctx context.Context,
+	service openfga.StoreService,
+	desired []extensionsv1.RelationshipTuple,
+	managed []extensionsv1.RelationshipTuple,
+	log *logr.Logger) (applyResult, error) {
+
+	result := applyResult{}
+	desiredSet := toSet(desired)
+	existing := newStoreTuples(service)
+
+	var prune []extensionsv1.RelationshipTuple
+	for _, tuple := range managed {
+		if desiredSet[tuple] {
+			continue
+		}
+		exists, err := existing.contains(ctx, tuple)
+		if err != nil && !openfga.IsPermanent(err) {
+			return applyResult{managed: managed}, err
+		}
+		if err != nil {
+			result.addError(tuple, err)
+			result.managed = append(result.managed, tuple)
+			continue
+		}
+		if exists {
+			prune = append(prune, tuple)
+		}
+	}
+	failed, _, err := each(ctx, prune, func(ctx context.Context, tuples []openfga.Tuple) error {
+		return service.DeleteTuples(ctx, tuples, log)
+	})
+	for _, tuple := range prune {
+		if failure, ok := failed[tuple]; ok {
+			result.addError(tuple, failure)
+			result.managed = append(result.managed, tuple)
+		} else if err != nil {
+			result.managed = append(result.managed, tuple)
+		} else {
+			result.pruned++
+		}
+	}
+	if err != nil {
+		return result.keepManaged(managed, desiredSet), err
+	}
+
+	managedSet := toSet(managed)
+	var write []extensionsv1.RelationshipTuple
+	for i, tuple := range desired {
+		exists, err := existing.contains(ctx, tuple)
+		if err != nil && !openfga.IsPermanent(err) {
+			for _, remaining := range desired[i:] {
+				if managedSet[remaining] {
+					result.managed = append(result.managed, remaining)
+				}
+			}
+			return result, err
+		}
+		switch {
+		case err != nil:
+			result.addError(tuple, err)
+			if managedSet[tuple] {
+				result.managed = append(result.managed, tuple)
+			}
+		case exists:
+			result.applied++
+			if managedSet[tuple] {
+				result.managed = append(result.managed, tuple)
+			}
+		default:
+			write = append(write, tuple)
+		}
+	}
+	failed, attempted, err := each(ctx, write, func(ctx context.Context, tuples []openfga.Tuple) error {
+		return service.WriteTuples(ctx, tuples, log)
+	})
+	for _, tuple := range write[:attempted] {
+		if failure, ok := failed[tuple]; ok {
+			result.addError(tuple, failure)
+			continue
+		}
+		result.managed = append(result.managed, tuple)
+		if err == nil {
+			result.applied++
+			result.written++
+		}
+	}
+	return result, err
+}
+
+// keepManaged adds the managed tuples which are desired to the result, after applying was stopped while
+// deleting the tuples which are no longer desired.
+func (r applyResult) keepManaged(managed []extensionsv1.RelationshipTuple, desired map[extensionsv1.RelationshipTuple]bool) applyResult {
+	kept := toSet(r.managed)
+	for _, tuple := range managed {
+		if !kept[tuple] && desired[tuple] {
+			r.managed = append(r.managed, tuple)
+		}
+	}
+	return r
+}
+
+func (r *applyResult) addError(tuple extensionsv1.RelationshipTuple, err error) {
+	r.errors = append(r.errors, extensionsv1.RelationshipTupleError{RelationshipTuple: tuple, Message: err.Error()})
+}
+
+// each applies the tuples in chunks of at most openfga.MaxTuplesPerWrite tuples, and when a chunk fails
+// permanently, the tuples of that chunk one by one such that the failing tuples are known. The tuples of the
+// other chunks aren't applied again, since they are committed when their chunk succeeded. It returns the
+// permanent failure of every failing tuple, the number of leading tuples which were sent to OpenFGA, and a
+// transient failure which stopped applying the tuples. The outcome of the last tuples sent is unknown when
+// applying was stopped, and the tuples after them weren't sent.
+func each(
+	ctx context.Context,
+	tuples []extensionsv1.RelationshipTuple,
+	apply func(ctx context.Context, tuples []openfga.Tuple) error) (map[extensionsv1.RelationshipTuple]error, int, error) {
+
+	failed := map[extensionsv1.RelationshipTuple]error{}
+	for start := 0; start < len(tuples); start += openfga.MaxTuplesPerWrite {
+		end := min(start+openfga.MaxTuplesPerWrite, len(tuples))
+		chunk := tuples[start:end]
+		err := apply(ctx, toOpenFgaTuples(chunk))
+		if err == nil {
+			continue
+		}
+		if !openfga.IsPermanent(err) {
+			return failed, end, err
+		}
+		if len(chunk) == 1 {
+			failed[chunk[0]] = err
+			continue
+		}
+		for i, tuple := range chunk {
+			err := apply(ctx, toOpenFgaTuples([]extensionsv1.RelationshipTuple{tuple}))
+			if err != nil && !openfga.IsPermanent(err) {
+				return failed, start + i + 1, err
+			}
+			if err != nil {
+				failed[tuple] = err
+			}
+		}
+	}
+	return failed, len(tuples), nil
+}
+
+// storeTuples reads the tuples of the store once per object, such that the tuples of an object are checked
+// with a single read instead of a read per tuple.
+type storeTuples struct {
+	service openfga.StoreService
+	objects map[string]map[extensionsv1.RelationshipTuple]bool
+	errors  map[string]error
+}
+
+func newStoreTuples(service openfga.StoreService) *storeTuples {
+	return &storeTuples{
+		service: service,
+		objects: map[string]map[extensionsv1.RelationshipTuple]bool{},
+		errors:  map[string]error{},
+	}
+}
+
+// contains returns true when the tuple exists in the store, reading the tuples of its object on first use.
+// Permanent failures are kept for the object, and transient failures are read again.
+func (s *storeTuples) contains(ctx context.Context, tuple extensionsv1.RelationshipTuple) (bool, error) {
+	if err, ok := s.errors[tuple.Object]; ok {
+		return false, err
+	}
+	objectTuples, ok := s.objects[tuple.Object]
+	if !ok {
+		tuples, err := s.service.Read(ctx, openfga.Tuple{Object: tuple.Object})
+		if err != nil {
+			if openfga.IsPermanent(err) {
+				s.errors[tuple.Object] = err
+			}
+			return false, err
+		}
+		objectTuples = make(map[extensionsv1.RelationshipTuple]bool, len(tuples))
+		for _, read := range tuples {
+			objectTuples[extensionsv1.RelationshipTuple{User: read.User, Relation: read.Relation, Object: read.Object}] = true
+		}
+		s.objects[tuple.Object] = objectTuples
+	}
+	return objectTuples[tuple], nil
+}
+
+// unique returns the tuples without duplicates, in their order.
+func unique(tuples []extensionsv1.RelationshipTuple) []extensionsv1.RelationshipTuple {
+	seen := map[extensionsv1.RelationshipTuple]bool{}
+	var result []extensionsv1.RelationshipTuple
+	for _, tuple := range tuples {
+		if !seen[tuple] {
+			seen[tuple] = true
+			result = append(result, tuple)
+		}
+	}
+	return result
+}
+
+func toSet(tuples []extensionsv1.RelationshipTuple) map[extensionsv1.RelationshipTuple]bool {
+	set := make(map[extensionsv1.RelationshipTuple]bool, len(tuples))
+	for _, tuple := range tuples {
+		set[tuple] = true
+	}
+	return set
+}
+
+func toOpenFgaTuple(tuple extensionsv1.RelationshipTuple) openfga.Tuple {
+	return openfga.Tuple{User: tuple.User, Relation: tuple.Relation, Object: tuple.Object}
+}
+
+func toOpenFgaTuples(tuples []extensionsv1.RelationshipTuple) []openfga.Tuple {
+	result := make([]openfga.Tuple, 0, len(tuples))
+	for _, tuple := range tuples {
+		result = append(result, toOpenFgaTuple(tuple))
+	}
+	return result
+}
@@ -0,0 +1,281 @@
+package relationshiptuples
+
+import (
+	"context"
+	"errors"
+	extensionsv1 "fga-operator/api/v1"
+	"fga-operator/internal/openfga"
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+// fakeStoreService keeps the tuples of a store in memory. Writing a tuple of an unknown type or an existing
+// tuple, and deleting a missing tuple, fails the whole write permanently like OpenFGA. Writes are committed
+// in transactions of openfga.MaxTuplesPerWrite tuples like the service. With unavailableWrite, that write fails
+// transiently without committing its tuples.
+type fakeStoreService struct {
+	openfga.StoreService
+	tuples           map[openfga.Tuple]bool
+	invalidType      string
+	unavailable      bool
+	unavailableWrite int
+	writes           int
+	reads            int
+}
+
+func (f *fakeStoreService) WriteTuples(_ context.Context, tuples []openfga.Tuple, _ *logr.Logger) error {
+	f.writes++
+	if f.unavailable || f.writes == f.unavailableWrite {
+		return errors.New("unavailable")
+	}
+	for start := 0; start < len(tuples); start += openfga.MaxTuplesPerWrite {
+		chunk := tuples[start:min(start+openfga.MaxTuplesPerWrite, len(tuples))]
+		for _, tuple := range chunk {
+			if f.tuples[tuple] || f.invalidType != "" && strings.HasPrefix(tuple.Object, f.invalidType) {
+				return openfga.Permanent(errors.New("invalid tuple " + tuple.Object))
+			}
+		}
+		for _, tuple := range chunk {
+			f.tuples[tuple] = true
+		}
+	}
+	return nil
+}
+
+func (f *fakeStoreService) DeleteTuples(_ context.Context, tuples []openfga.Tuple, _ *logr.Logger) error {
+	f.writes++
+	for _, tuple := range tuples {
+		if !f.tuples[tuple] {
+			return openfga.Permanent(errors.New("missing tuple " + tuple.Object))
+		}
+	}
+	for _, tuple := range tuples {
+		delete(f.tuples, tuple)
+	}
+	return nil
+}
+
+func (f *fakeStoreService) Read(_ context.Context, filter openfga.Tuple) ([]openfga.Tuple, error) {
+	f.reads++
+	var result []openfga.Tuple
+	for tuple := range f.tuples {
+		if (filter.User == "" || filter.User == tuple.User) &&
+			(filter.Relation == "" || filter.Relation == tuple.Relation) &&
+			(filter.Object == "" || filter.Object == tuple.Object) {
+			result = append(result, tuple)
+		}
+	}
+	return result, nil
+}
+
+func tuple(object string) extensionsv1.RelationshipTuple {
+	return extensionsv1.RelationshipTuple{User: "user:anne", Relation: "admin", Object: object}
+}
+
+func TestApplyTuples(t *testing.T) {
+	testCases := []struct {
+		description     string
+		existing        []extensionsv1.RelationshipTuple
+		desired         []extensionsv1.RelationshipTuple
+		managed         []extensionsv1.RelationshipTuple
+		invalidType     string
+		expectedTuples  []extensionsv1.RelationshipTuple
+		expectedManaged []extensionsv1.RelationshipTuple
+		expectedApplied int
+		expectedWritten int
+		expectedPruned  int
+		expectedErrors  int
+	}{
+		{
+			description:     "writes missing tuples",
+			desired:         []extensionsv1.RelationshipTuple{tuple("org:a"), tuple("org:b")},
+			expectedTuples:  []extensionsv1.RelationshipTuple{tuple("org:a"), tuple("org:b")},
+			expectedManaged: []extensionsv1.RelationshipTuple{tuple("org:a"), tuple("org:b")},
+			expectedApplied: 2,
+			expectedWritten: 2,
+		},
+		{
+			description:     "doesn't manage existing tuples",
+			existing:        []extensionsv1.RelationshipTuple{tuple("org:a")},
+			desired:         []extensionsv1.RelationshipTuple{tuple("org:a"), tuple("org:b")},
+			expectedTuples:  []extensionsv1.RelationshipTuple{tuple("org:a"), tuple("org:b")},
+			expectedManaged: []extensionsv1.RelationshipTuple{tuple("org:b")},
+			expectedApplied: 2,
+			expectedWritten: 1,
+		},
+		{
+			description:     "prunes managed tuples which are no longer desired",
+			existing:        []extensionsv1.RelationshipTuple{tuple("org:a"), tuple("org:b"), tuple("org:c")},
+			desired:         []extensionsv1.RelationshipTuple{tuple("org:a")},
+			managed:         []extensionsv1.RelationshipTuple{tuple("org:a"), tuple("org:b")},
+			expectedTuples:  []extensionsv1.RelationshipTuple{tuple("org:a"), tuple("org:c")},
+			expectedManaged: []extensionsv1.RelationshipTuple{tuple("org:a")},
+			expectedApplied: 1,
+			expectedPruned:  1,
+		},
+		{
+			description:     "forgets managed tuples which were deleted",
+			desired:         []extensionsv1.RelationshipTuple{},
+			managed:         []extensionsv1.RelationshipTuple{tuple("org:a")},
+			expectedTuples:  []extensionsv1.RelationshipTuple{},
+			expectedManaged: nil,
+		},
+		{
+			description:     "rewrites managed tuples which were deleted",
+			desired:         []extensionsv1.RelationshipTuple{tuple("org:a")},
+			managed:         []extensionsv1.RelationshipTuple{tuple("org:a")},
+			expectedTuples:  []extensionsv1.RelationshipTuple{tuple("org:a")},
+			expectedManaged: []extensionsv1.RelationshipTuple{tuple("org:a")},
+			expectedApplied: 1,
+			expectedWritten: 1,
+		},
+		{
+			description:     "reports failing tuples and writes the others",
+			desired:         []extensionsv1.RelationshipTuple{tuple("org:a"), tuple("team:b"), tuple("org:c")},
+			invalidType:     "team:",
+			expectedTuples:  []extensionsv1.RelationshipTuple{tuple("org:a"), tuple("org:c")},
+			expectedManaged: []extensionsv1.RelationshipTuple{tuple("org:a"), tuple("org:c")},
+			expectedApplied: 2,
+			expectedWritten: 2,
+			expectedErrors:  1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			service := &fakeStoreService{tuples: map[openfga.Tuple]bool{}, invalidType: testCase.invalidType}
+			for _, existing := range testCase.existing {
+				service.tuples[toOpenFgaTuple(existing)] = true
+			}
+
+			// Act
+			result, err := applyTuples(context.Background(), service, testCase.desired, testCase.managed, &logr.Logger{})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectedTuples := map[openfga.Tuple]bool{}
+			for _, expected := range testCase.expectedTuples {
+				expectedTuples[toOpenFgaTuple(expected)] = true
+			}
+			if diff := cmp.Diff(expectedTuples, service.tuples); diff != "" {
+				t.Errorf("unexpected tuples in store (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(testCase.expectedManaged, result.managed); diff != "" {
+				t.Errorf("unexpected managed tuples (-want +got):\n%s", diff)
+			}
+			counts := []int{result.applied, result.written, result.pruned, len(result.errors)}
+			expectedCounts := []int{testCase.expectedApplied, testCase.expectedWritten, testCase.expectedPruned, testCase.expectedErrors}
+			if diff := cmp.Diff(expectedCounts, counts); diff != "" {
+				t.Errorf("unexpected applied, written, pruned and failed counts (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApplyTuplesTransientFailure(t *testing.T) {
+	// Arrange
+	service := &fakeStoreService{tuples: map[openfga.Tuple]bool{toOpenFgaTuple(tuple("org:a")): true}, unavailable: true}
+	desired := []extensionsv1.RelationshipTuple{tuple("org:a"), tuple("org:b")}
+	managed := []extensionsv1.RelationshipTuple{tuple("org:a")}
+
+	// Act
+	result, err := applyTuples(context.Background(), service, desired, managed, &logr.Logger{})
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected the transient failure")
+	}
+	if diff := cmp.Diff([]extensionsv1.RelationshipTuple{tuple("org:a"), tuple("org:b")}, result.managed); diff != "" {
+		t.Errorf("expected the tuple being written to be managed (-want +got):\n%s", diff)
+	}
+	if service.writes != 1 {
+		t.Errorf("expected a single write, got %d", service.writes)
+	}
+}
+
+func TestApplyTuplesTransientFailureInLaterChunk(t *testing.T) {
+	// Arrange
+	service := &fakeStoreService{tuples: map[openfga.Tuple]bool{}, unavailableWrite: 2}
+	var desired []extensionsv1.RelationshipTuple
+	for i := 0; i < 2*openfga.MaxTuplesPerWrite+10; i++ {
+		desired = append(desired, tuple(fmt.Sprintf("org:%d", i)))
+	}
+
+	// Act
+	result, err := applyTuples(context.Background(), service, desired, nil, &logr.Logger{})
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected the transient failure")
+	}
+	if diff := cmp.Diff(desired[:2*openfga.MaxTuplesPerWrite], result.managed); diff != "" {
+		t.Errorf("expected the tuples of the written and the failing chunk to be managed (-want +got):\n%s", diff)
+	}
+	if service.writes != 2 {
+		t.Errorf("expected two writes, got %d", service.writes)
+	}
+}
+
+func TestApplyTuplesFailingInLaterChunk(t *testing.T) {
+	// Arrange
+	service := &fakeStoreService{tuples: map[openfga.Tuple]bool{}, invalidType: "team:"}
+	var desired []extensionsv1.RelationshipTuple
+	for i := 0; i < openfga.MaxTuplesPerWrite+50; i++ {
+		desired = append(desired, tuple(fmt.Sprintf("org:%d", i)))
+	}
+	desired[openfga.MaxTuplesPerWrite+20] = tuple("team:invalid")
+
+	// Act
+	result, err := applyTuples(context.Background(), service, desired, nil, &logr.Logger{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.errors) != 1 || result.errors[0].RelationshipTuple != tuple("team:invalid") {
+		t.Errorf("expected only the invalid tuple to fail, got %v", result.errors)
+	}
+	expected := len(desired) - 1
+	counts := []int{len(service.tuples), len(result.managed), result.applied, result.written}
+	if diff := cmp.Diff([]int{expected, expected, expected, expected}, counts); diff != "" {
+		t.Errorf("unexpected stored, managed, applied and written counts (-want +got):\n%s", diff)
+	}
+}
+
+func TestApplyTuplesReadsOncePerObject(t *testing.T) {
+	// Arrange
+	service := &fakeStoreService{tuples: map[openfga.Tuple]bool{toOpenFgaTuple(tuple("org:a")): true}}
+	member := extensionsv1.RelationshipTuple{User: "user:bob", Relation: "member", Object: "org:a"}
+	desired := []extensionsv1.RelationshipTuple{tuple("org:a"), member, tuple("org:b")}
+	managed := []extensionsv1.RelationshipTuple{{User: "user:carl", Relation: "member", Object: "org:a"}}
+
+	// Act
+	result, err := applyTuples(context.Background(), service, desired, managed, &logr.Logger{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if service.reads != 2 {
+		t.Errorf("expected a read per object, got %d", service.reads)
+	}
+	if diff := cmp.Diff([]extensionsv1.RelationshipTuple{member, tuple("org:b")}, result.managed); diff != "" {
+		t.Errorf("unexpected managed tuples (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnique(t *testing.T) {
+	// Act
+	result := unique([]extensionsv1.RelationshipTuple{tuple("org:b"), tuple("org:a"), tuple("org:b")})
+
+	// Assert
+	if diff := cmp.Diff([]extensionsv1.RelationshipTuple{tuple("org:b"), tuple("org:a")}, result); diff != "" {
+		t.Errorf("unexpected tuples (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,378 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"fga-operator/internal/connections"
+	"fga-operator/internal/observability"
+	"fga-operator/internal/openfga"
+	"fmt"
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	extensionsv1 "fga-operator/api/v1"
+)
+
+const (
+	EventRecorderLabel = "StoreReconciler"
+)
+
+type EventReason string
+
+const (
+	EventReasonClientInitializationFailed EventReason = "ClientInitializationFailed"
+	EventReasonStoreVerificationFailed    EventReason = "StoreVerificationFailed"
+	EventReasonStoreNotFound              EventReason = "StoreNotFound"
+	EventReasonStoreRecreated             EventReason = "StoreRecreated"
+	EventReasonStoreRecreationFailed      EventReason = "StoreRecreationFailed"
+	EventReasonStatusUpdateFailed         EventReason = "StatusUpdateFailed"
+	EventReasonStoreDeleted               EventReason = "StoreDeleted"
+	EventReasonStoreDeletionFailed        EventReason = "StoreDeletionFailed"
+	EventReasonStoreDeletionSkipped       EventReason = "StoreDeletionSkipped"
+	EventReasonFinalizerUpdateFailed      EventReason = "FinalizerUpdateFailed"
+)
+
+const (
+	conditionReasonFound              = "Found"
+	conditionReasonNotFound           = "NotFound"
+	conditionReasonRecreated          = "Recreated"
+	conditionReasonVerificationFailed = "VerificationFailed"
+	conditionReasonClientFailed       = "ClientInitializationFailed"
+)
+
+// StoreReconciler verifies that stores still exist in OpenFGA
+type StoreReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	openfga.PermissionServiceFactory
+	openfga.Config
+	Clock
+	VerificationInterval *time.Duration
+	// APIReader reads connections and their secrets directly from the API server, such that secrets
+	// aren't cached. Defaults to the client.
+	APIReader client.Reader
+}
+
+type Clock interface {
+	Now() time.Time
+}
+
+//+kubebuilder:rbac:groups=extensions.fga-operator,resources=stores,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=extensions.fga-operator,resources=stores/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=extensions.fga-operator,resources=stores/finalizers,verbs=update
+//+kubebuilder:rbac:groups=extensions.fga-operator,resources=authorizationmodels,verbs=delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get
+//+kubebuilder:rbac:groups=extensions.fga-operator,resources=fgaconnections;clusterfgaconnections,verbs=get
+
+// Reconcile verifies that the store exists in OpenFGA, and recreates it when it doesn't and the
+// recreate policy of the store allows it. When the store resource is deleted, the store is deleted
+// from OpenFGA if the deletion policy of the store is "Delete" and the operator created it.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.17.3/pkg/reconcile
+func (r *StoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.V(1).Info("Reconciliation triggered for store")
+	reconcileTimestamp := r.Now()
+
+	store := &extensionsv1.Store{}
+	if err := r.Get(ctx, req.NamespacedName, store); err != nil {
+		logger.Error(err, "unable to fetch store", "storeName", req.Name)
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !store.DeletionTimestamp.IsZero() {
+		return r.failureResult(r.finalizeStore(ctx, store, &logger))
+	}
+
+	if err := r.ensureFinalizer(ctx, store, &logger); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	config, err := r.resolveConfig(ctx, store)
+	if err != nil {
+		logger.Error(err, "unable to resolve connection of store")
+		return r.failVerification(ctx, store, EventReasonClientInitializationFailed, conditionReasonClientFailed, err, reconcileTimestamp, &logger)
+	}
+	store.Status.ApiUrl = config.ApiUrl
+
+	openFgaService, err := r.PermissionServiceFactory.GetService(config)
+	if err != nil {
+		logger.Error(err, "unable to get permission service")
+		return r.failVerification(ctx, store, EventReasonClientInitializationFailed, conditionReasonClientFailed, err, reconcileTimestamp, &logger)
+	}
+
+	existing, err := openFgaService.CheckExistingStoresById(ctx, store.Spec.Id)
+	if err != nil {
+		logger.Error(err, "unable to verify store", "storeId", store.Spec.Id)
+		return r.failVerification(ctx, store, EventReasonStoreVerificationFailed, conditionReasonVerificationFailed, err, reconcileTimestamp, &logger)
+	}
+
+	switch {
+	case existing != nil:
+		setVerified(store, conditionReasonFound, reconcileTimestamp)
+	case store.Spec.RecreatePolicy == extensionsv1.RecreateIfNotFound:
+		if err := r.recreateStore(ctx, openFgaService, store, reconcileTimestamp, &logger); err != nil {
+			return r.failureResult(err)
+		}
+	default:
+		r.setNotFound(store, reconcileTimestamp, &logger)
+	}
+
+	if err := r.updateStatus(ctx, store, &logger); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: *r.VerificationInterval}, nil
+}
+
+// failVerification records the failure to verify the store, and sets the Ready condition to unknown, since
+// the store may still exist in OpenFGA.
+func (r *StoreReconciler) failVerification(
+	ctx context.Context,
+	store *extensionsv1.Store,
+	eventReason EventReason,
+	conditionReason string,
+	err error,
+	now time.Time,
+	log *logr.Logger) (ctrl.Result, error) {
+	r.createStoreEvent(store, eventReason, err)
+	setCondition(store, extensionsv1.StoreConditionReady, metav1.ConditionUnknown, conditionReason, err.Error(), now)
+	if statusError := r.updateStatus(ctx, store, log); statusError != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update status: %w with prior error %v", statusError, err)
+	}
+	return r.failureResult(err)
+}
+
+// failureResult returns the result of a reconciliation which failed with the error. Permanent failures
+// are retried with the verification interval, as stores are verified periodically.
+func (r *StoreReconciler) failureResult(err error) (ctrl.Result, error) {
+	if openfga.IsPermanent(err) {
+		return ctrl.Result{RequeueAfter: *r.VerificationInterval}, nil
+	}
+	return openfga.ReconcileResult(err)
+}
+
+// ensureFinalizer adds the finalizer to stores with the deletion policy "Delete", and removes it from
+// stores which no longer have that policy.
+func (r *StoreReconciler) ensureFinalizer(ctx context.Context, store *extensionsv1.Store, log *logr.Logger) error {
+	var changed bool
+	if store.Spec.DeletionPolicy == extensionsv1.DeletionPolicyDelete {
+		changed = controllerutil.AddFinalizer(store, extensionsv1.StoreFinalizer)
+	} else {
+		changed = controllerutil.RemoveFinalizer(store, extensionsv1.StoreFinalizer)
+	}
+	if !changed {
+		return nil
+	}
+	if err := r.Update(ctx, store); err != nil {
+		r.createStoreEvent(store, EventReasonFinalizerUpdateFailed, err)
+		log.Error(err, "unable to update finalizer of store", "storeName", store.Name)
+		return err
+	}
+	return nil
+}
+
+// finalizeStore deletes the store from OpenFGA when needed, and then removes the finalizer such that Kubernetes can
+// delete the store resource.
+func (r *StoreReconciler) finalizeStore(ctx context.Context, store *extensionsv1.Store, log *logr.Logger) error {
+	if !controllerutil.ContainsFinalizer(store, extensionsv1.StoreFinalizer) {
+		return nil
+	}
+
+	if err := r.deleteStore(ctx, store, log); err != nil {
+		return err
+	}
+
+	controllerutil.RemoveFinalizer(store, extensionsv1.StoreFinalizer)
+	if err := r.Update(ctx, store); err != nil {
+		r.createStoreEvent(store, EventReasonFinalizerUpdateFailed, err)
+		log.Error(err, "unable to remove finalizer of store", "storeName", store.Name)
+		return err
+	}
+	return nil
+}
+
+// deleteStore deletes the store from OpenFGA when its deletion policy is "Delete" and the operator created it.
+// The deletion is skipped with a warning when the connection of the store no longer resolves, such that the
+// resource can still be deleted.
+func (r *StoreReconciler) deleteStore(ctx context.Context, store *extensionsv1.Store, log *logr.Logger) error {
+	if store.Spec.DeletionPolicy != extensionsv1.DeletionPolicyDelete {
+		return nil
+	}
+	if store.Status.Origin != extensionsv1.StoreOriginCreated {
+		message := fmt.Sprintf("store with id %s is retained in OpenFGA, as it wasn't created by the operator", store.Spec.Id)
+		r.Recorder.Event(store, v1.EventTypeWarning, string(EventReasonStoreDeletionSkipped), message)
+		log.V(0).Info(message, "storeName", store.Name)
+		return nil
+	}
+	openFgaService, err := r.getService(ctx, store)
+	if err != nil {
+		r.createStoreEvent(store, EventReasonStoreDeletionSkipped,
+			fmt.Errorf("store with id %s is retained in OpenFGA, as its connection can't be resolved: %w", store.Spec.Id, err))
+		log.Error(err, "unable to get permission service, skipping the deletion of the store", "storeName", store.Name)
+		return nil
+	}
+	if err := openFgaService.DeleteStore(ctx, store.Spec.Id, log); err != nil {
+		r.createStoreEvent(store, EventReasonStoreDeletionFailed, err)
+		log.Error(err, "unable to delete store from OpenFGA", "storeName", store.Name, "storeId", store.Spec.Id)
+		return err
+	}
+	observability.RecordOpenFgaStoreDeleted(store.Name)
+	r.Recorder.Event(store, v1.EventTypeNormal, string(EventReasonStoreDeleted),
+		fmt.Sprintf("store with id %s has been deleted from OpenFGA", store.Spec.Id))
+	return nil
+}
+
+// getService returns the permission service for the connection the store lives on.
+func (r *StoreReconciler) getService(ctx context.Context, store *extensionsv1.Store) (openfga.PermissionService, error) {
+	config, err := r.resolveConfig(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	return r.PermissionServiceFactory.GetService(config)
+}
+
+// resolveConfig returns the configuration of the connection the store lives on.
+func (r *StoreReconciler) resolveConfig(ctx context.Context, store *extensionsv1.Store) (openfga.Config, error) {
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	return connections.Resolve(ctx, reader, r.Config, store.Namespace, store.Spec.ConnectionRef)
+}
+
+func (r *StoreReconciler) setNotFound(store *extensionsv1.Store, now time.Time, log *logr.Logger) {
+	message := fmt.Sprintf("store with id %s does not exist in OpenFGA", store.Spec.Id)
+	if !meta.IsStatusConditionTrue(store.Status.Conditions, extensionsv1.StoreConditionNotFound) {
+		r.Recorder.Event(store, v1.EventTypeWarning, string(EventReasonStoreNotFound), message)
+	}
+	log.V(0).Info(message, "storeName", store.Name)
+	setCondition(store, extensionsv1.StoreConditionReady, metav1.ConditionFalse, conditionReasonNotFound, message, now)
+	setCondition(store, extensionsv1.StoreConditionNotFound, metav1.ConditionTrue, conditionReasonNotFound, message, now)
+}
+
+// recreateStore creates a new store in OpenFGA and deletes the authorization model resource, since the
+// authorization models of the old store don't exist in the new store. The authorization model request
+// reconciler then creates the authorization models in the new store.
+func (r *StoreReconciler) recreateStore(
+	ctx context.Context,
+	openFgaService openfga.PermissionService,
+	store *extensionsv1.Store,
+	now time.Time,
+	log *logr.Logger) error {
+	previousId := store.Spec.Id
+	created, err := openFgaService.CreateStore(ctx, store.Name, log)
+	if err != nil {
+		r.createStoreEvent(store, EventReasonStoreRecreationFailed, err)
+		log.Error(err, "unable to recreate store", "storeName", store.Name)
+		return err
+	}
+	observability.RecordOpenFgaStoreEvent(store.Name)
+
+	store.Spec.Id = created.Id
+	status := store.Status
+	if err := r.Update(ctx, store); err != nil {
+		r.createStoreEvent(store, EventReasonStoreRecreationFailed, err)
+		log.Error(err, "unable to update store with recreated id", "storeName", store.Name, "storeId", created.Id)
+		return err
+	}
+	store.Status = status
+	store.Status.Origin = extensionsv1.StoreOriginCreated
+
+	authorizationModel := &extensionsv1.AuthorizationModel{
+		ObjectMeta: metav1.ObjectMeta{Name: store.Name, Namespace: store.Namespace},
+	}
+	if err := r.Delete(ctx, authorizationModel); client.IgnoreNotFound(err) != nil {
+		r.createStoreEvent(store, EventReasonStoreRecreationFailed, err)
+		log.Error(err, "unable to delete authorization model of recreated store", "storeName", store.Name)
+		return err
+	}
+
+	message := fmt.Sprintf("store with id %s did not exist in OpenFGA and has been recreated with id %s", previousId, created.Id)
+	r.Recorder.Event(store, v1.EventTypeNormal, string(EventReasonStoreRecreated), message)
+	log.V(0).Info(message, "storeName", store.Name)
+	setVerified(store, conditionReasonRecreated, now)
+	return nil
+}
+
+func (r *StoreReconciler) updateStatus(ctx context.Context, store *extensionsv1.Store, log *logr.Logger) error {
+	if err := r.Status().Update(ctx, store); err != nil {
+		r.createStoreEvent(store, EventReasonStatusUpdateFailed, err)
+		log.Error(err, "unable to update store status", "storeName", store.Name)
+		return err
+	}
+	return nil
+}
+
+func (r *StoreReconciler) createStoreEvent(store *extensionsv1.Store, eventReason EventReason, err error) {
+	r.Recorder.Event(
+		store,
+		v1.EventTypeWarning,
+		string(eventReason),
+		err.Error(),
+	)
+}
+
+func setVerified(store *extensionsv1.Store, reason string, now time.Time) {
+	store.Status.LastVerified = &metav1.Time{Time: now}
+	setCondition(store, extensionsv1.StoreConditionReady, metav1.ConditionTrue, reason, "", now)
+	setCondition(store, extensionsv1.StoreConditionNotFound, metav1.ConditionFalse, reason, "", now)
+}
+
+func setCondition(
+	store *extensionsv1.Store,
+	conditionType string,
+	conditionStatus metav1.ConditionStatus,
+	reason, message string,
+	now time.Time) {
+	meta.SetStatusCondition(&store.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: store.Generation,
+		LastTransitionTime: metav1.NewTime(now),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *StoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Clock == nil {
+		r.Clock = clock.RealClock{}
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&extensionsv1.Store{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r)
+}
@@ -0,0 +1,356 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	extensionsv1 "fga-operator/api/v1"
+	fgainternal "fga-operator/internal/openfga"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"time"
+)
+
+const (
+	storeId   = "store-id"
+	duration  = time.Millisecond * 500
+	interval  = time.Millisecond * 100
+	requeueIn = time.Minute
+)
+
+var _ = Describe("Store Controller", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: namespaceName,
+		}
+		request := reconcile.Request{NamespacedName: typeNamespacedName}
+
+		createStore := func(policy extensionsv1.StoreRecreatePolicy) {
+			store := extensionsv1.NewStore(resourceName, namespaceName, storeId, time.Now())
+			store.Spec.RecreatePolicy = policy
+			Expect(k8sClient.Create(ctx, store)).To(Succeed())
+		}
+
+		createDeletedStore := func(origin extensionsv1.StoreOrigin, connectionRef *extensionsv1.ConnectionReference) {
+			store := extensionsv1.NewStore(resourceName, namespaceName, storeId, time.Now())
+			store.Spec.DeletionPolicy = extensionsv1.DeletionPolicyDelete
+			store.Spec.ConnectionRef = connectionRef
+			store.Finalizers = []string{extensionsv1.StoreFinalizer}
+			Expect(k8sClient.Create(ctx, store)).To(Succeed())
+			store.Status.Origin = origin
+			Expect(k8sClient.Status().Update(ctx, store)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, store)).To(Succeed())
+		}
+
+		getStore := func() *extensionsv1.Store {
+			store := &extensionsv1.Store{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, store)).To(Succeed())
+			return store
+		}
+
+		newReconciler := func(service fgainternal.PermissionService, recorder record.EventRecorder) *StoreReconciler {
+			mockFactory := fgainternal.NewMockPermissionServiceFactory(goMockController)
+			mockFactory.EXPECT().GetService(gomock.Any()).Return(service, nil)
+			verificationInterval := requeueIn
+			return &StoreReconciler{
+				Client:                   k8sClient,
+				Scheme:                   k8sClient.Scheme(),
+				Recorder:                 recorder,
+				Clock:                    MockClock{},
+				PermissionServiceFactory: mockFactory,
+				VerificationInterval:     &verificationInterval,
+			}
+		}
+
+		AfterEach(func() {
+			for _, resource := range []client.Object{&extensionsv1.Store{}, &extensionsv1.AuthorizationModel{}} {
+				err := k8sClient.Get(ctx, typeNamespacedName, resource)
+				if errors.IsNotFound(err) {
+					continue
+				}
+				Expect(err).NotTo(HaveOccurred())
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			}
+		})
+
+		It("given existing store in OpenFGA then set ready", func() {
+			// Arrange
+			createStore(extensionsv1.RecreateNever)
+			mockService := fgainternal.NewMockPermissionService(goMockController)
+			mockService.EXPECT().CheckExistingStoresById(gomock.Any(), storeId).Return(&fgainternal.Store{Id: storeId, Name: resourceName}, nil)
+			fakeRecorder := record.NewFakeRecorder(5)
+
+			// Act
+			result, err := newReconciler(mockService, fakeRecorder).Reconcile(ctx, request)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(requeueIn))
+			store := getStore()
+			Expect(meta.IsStatusConditionTrue(store.Status.Conditions, extensionsv1.StoreConditionReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(store.Status.Conditions, extensionsv1.StoreConditionNotFound)).To(BeTrue())
+			Expect(store.Status.LastVerified.Time.UTC()).To(Equal(mockTime))
+			validateNoEvents(fakeRecorder.Events)
+		})
+
+		It("given missing store in OpenFGA and policy never then set not found", func() {
+			// Arrange
+			createStore(extensionsv1.RecreateNever)
+			mockService := fgainternal.NewMockPermissionService(goMockController)
+			mockService.EXPECT().CheckExistingStoresById(gomock.Any(), storeId).Return(nil, nil)
+			mockService.EXPECT().CreateStore(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			fakeRecorder := record.NewFakeRecorder(5)
+
+			// Act
+			_, err := newReconciler(mockService, fakeRecorder).Reconcile(ctx, request)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			store := getStore()
+			Expect(store.Spec.Id).To(Equal(storeId))
+			Expect(meta.IsStatusConditionFalse(store.Status.Conditions, extensionsv1.StoreConditionReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(store.Status.Conditions, extensionsv1.StoreConditionNotFound)).To(BeTrue())
+			validateEvent(fakeRecorder.Events, EventReasonStoreNotFound)
+		})
+
+		It("given missing store in OpenFGA and policy if not found then recreate store", func() {
+			// Arrange
+			createStore(extensionsv1.RecreateIfNotFound)
+			authorizationModel := extensionsv1.NewAuthorizationModel(resourceName, namespaceName, []extensionsv1.AuthorizationModelDefinition{
+				extensionsv1.NewAuthorizationModelDefinition("model-id", "", extensionsv1.ModelVersion{Major: 1}),
+			}, time.Now())
+			Expect(k8sClient.Create(ctx, &authorizationModel)).To(Succeed())
+
+			mockService := fgainternal.NewMockPermissionService(goMockController)
+			mockService.EXPECT().CheckExistingStoresById(gomock.Any(), storeId).Return(nil, nil)
+			mockService.EXPECT().CreateStore(gomock.Any(), resourceName, gomock.Any()).Return(&fgainternal.Store{Id: "new-store-id", Name: resourceName}, nil)
+			fakeRecorder := record.NewFakeRecorder(5)
+
+			// Act
+			_, err := newReconciler(mockService, fakeRecorder).Reconcile(ctx, request)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			store := getStore()
+			Expect(store.Spec.Id).To(Equal("new-store-id"))
+			Expect(meta.IsStatusConditionTrue(store.Status.Conditions, extensionsv1.StoreConditionReady)).To(BeTrue())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, typeNamespacedName, &extensionsv1.AuthorizationModel{})
+				return errors.IsNotFound(err)
+			}, duration, interval).Should(BeTrue())
+			validateEvent(fakeRecorder.Events, EventReasonStoreRecreated)
+		})
+
+		It("given deletion policy delete when store is deleted then delete store from OpenFGA", func() {
+			// Arrange
+			store := extensionsv1.NewStore(resourceName, namespaceName, storeId, time.Now())
+			store.Spec.DeletionPolicy = extensionsv1.DeletionPolicyDelete
+			Expect(k8sClient.Create(ctx, store)).To(Succeed())
+			store.Status.Origin = extensionsv1.StoreOriginCreated
+			Expect(k8sClient.Status().Update(ctx, store)).To(Succeed())
+
+			mockService := fgainternal.NewMockPermissionService(goMockController)
+			mockService.EXPECT().CheckExistingStoresById(gomock.Any(), storeId).Return(&fgainternal.Store{Id: storeId, Name: resourceName}, nil)
+			_, err := newReconciler(mockService, record.NewFakeRecorder(5)).Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStore().Finalizers).To(ContainElement(extensionsv1.StoreFinalizer))
+			Expect(k8sClient.Delete(ctx, getStore())).To(Succeed())
+
+			mockService.EXPECT().DeleteStore(gomock.Any(), storeId, gomock.Any()).Return(nil)
+			fakeRecorder := record.NewFakeRecorder(5)
+
+			// Act
+			_, err = newReconciler(mockService, fakeRecorder).Reconcile(ctx, request)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, typeNamespacedName, &extensionsv1.Store{})
+				return errors.IsNotFound(err)
+			}, duration, interval).Should(BeTrue())
+			validateEvent(fakeRecorder.Events, EventReasonStoreDeleted)
+		})
+
+		It("given deletion policy delete when deleting store from OpenFGA fails then keep finalizer", func() {
+			// Arrange
+			createDeletedStore(extensionsv1.StoreOriginCreated, nil)
+
+			mockService := fgainternal.NewMockPermissionService(goMockController)
+			mockService.EXPECT().DeleteStore(gomock.Any(), storeId, gomock.Any()).Return(fmt.Errorf("error"))
+			fakeRecorder := record.NewFakeRecorder(5)
+
+			// Act
+			_, err := newReconciler(mockService, fakeRecorder).Reconcile(ctx, request)
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+			Expect(getStore().Finalizers).To(ContainElement(extensionsv1.StoreFinalizer))
+			validateEvent(fakeRecorder.Events, EventReasonStoreDeletionFailed)
+
+			// Cleanup
+			deleted := getStore()
+			deleted.Finalizers = nil
+			Expect(k8sClient.Update(ctx, deleted)).To(Succeed())
+		})
+
+		It("given deletion policy delete when store wasn't created by the operator then retain store in OpenFGA", func() {
+			// Arrange
+			createDeletedStore(extensionsv1.StoreOriginAdopted, nil)
+			mockService := fgainternal.NewMockPermissionService(goMockController)
+			mockService.EXPECT().DeleteStore(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			fakeRecorder := record.NewFakeRecorder(5)
+			reconciler := &StoreReconciler{
+				Client:                   k8sClient,
+				Scheme:                   k8sClient.Scheme(),
+				Recorder:                 fakeRecorder,
+				Clock:                    MockClock{},
+				PermissionServiceFactory: fgainternal.NewMockPermissionServiceFactory(goMockController),
+			}
+
+			// Act
+			_, err := reconciler.Reconcile(ctx, request)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, typeNamespacedName, &extensionsv1.Store{})
+				return errors.IsNotFound(err)
+			}, duration, interval).Should(BeTrue())
+			validateEvent(fakeRecorder.Events, EventReasonStoreDeletionSkipped)
+		})
+
+		It("given deletion policy delete when connection no longer resolves then retain store and remove finalizer", func() {
+			// Arrange
+			createDeletedStore(extensionsv1.StoreOriginCreated, &extensionsv1.ConnectionReference{Name: "deleted-connection"})
+			fakeRecorder := record.NewFakeRecorder(5)
+			reconciler := &StoreReconciler{
+				Client:                   k8sClient,
+				Scheme:                   k8sClient.Scheme(),
+				Recorder:                 fakeRecorder,
+				Clock:                    MockClock{},
+				PermissionServiceFactory: fgainternal.NewMockPermissionServiceFactory(goMockController),
+			}
+
+			// Act
+			_, err := reconciler.Reconcile(ctx, request)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, typeNamespacedName, &extensionsv1.Store{})
+				return errors.IsNotFound(err)
+			}, duration, interval).Should(BeTrue())
+			validateEvent(fakeRecorder.Events, EventReasonStoreDeletionSkipped)
+		})
+
+		It("given deletion policy retain then don't add finalizer", func() {
+			// Arrange
+			createStore(extensionsv1.RecreateNever)
+			mockService := fgainternal.NewMockPermissionService(goMockController)
+			mockService.EXPECT().CheckExistingStoresById(gomock.Any(), storeId).Return(&fgainternal.Store{Id: storeId, Name: resourceName}, nil)
+
+			// Act
+			_, err := newReconciler(mockService, record.NewFakeRecorder(5)).Reconcile(ctx, request)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getStore().Finalizers).To(BeEmpty())
+		})
+
+		It("given failing verification then return error", func() {
+			// Arrange
+			createStore(extensionsv1.RecreateIfNotFound)
+			mockService := fgainternal.NewMockPermissionService(goMockController)
+			mockService.EXPECT().CheckExistingStoresById(gomock.Any(), storeId).Return(nil, fmt.Errorf("error"))
+			mockService.EXPECT().CreateStore(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			fakeRecorder := record.NewFakeRecorder(5)
+
+			// Act
+			_, err := newReconciler(mockService, fakeRecorder).Reconcile(ctx, request)
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+			store := getStore()
+			Expect(store.Spec.Id).To(Equal(storeId))
+			Expect(meta.FindStatusCondition(store.Status.Conditions, extensionsv1.StoreConditionReady).Status).To(Equal(metav1.ConditionUnknown))
+			validateEvent(fakeRecorder.Events, EventReasonStoreVerificationFailed)
+		})
+
+		It("given failing client initialization then set ready unknown", func() {
+			// Arrange
+			createStore(extensionsv1.RecreateNever)
+			store := getStore()
+			setVerified(store, conditionReasonFound, mockTime)
+			Expect(k8sClient.Status().Update(ctx, store)).To(Succeed())
+			mockFactory := fgainternal.NewMockPermissionServiceFactory(goMockController)
+			mockFactory.EXPECT().GetService(gomock.Any()).Return(nil, fmt.Errorf("error"))
+			fakeRecorder := record.NewFakeRecorder(5)
+			verificationInterval := requeueIn
+			reconciler := &StoreReconciler{
+				Client:                   k8sClient,
+				Scheme:                   k8sClient.Scheme(),
+				Recorder:                 fakeRecorder,
+				Clock:                    MockClock{},
+				PermissionServiceFactory: mockFactory,
+				VerificationInterval:     &verificationInterval,
+			}
+
+			// Act
+			_, err := reconciler.Reconcile(ctx, request)
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+			condition := meta.FindStatusCondition(getStore().Status.Conditions, extensionsv1.StoreConditionReady)
+			Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+			Expect(condition.Reason).To(Equal(conditionReasonClientFailed))
+			validateEvent(fakeRecorder.Events, EventReasonClientInitializationFailed)
+		})
+	})
+})
+
+func validateEvent(events <-chan string, eventReason EventReason) {
+	select {
+	case event := <-events:
+		Expect(event).To(ContainSubstring(string(eventReason)))
+	default:
+		Fail("Expected an event, but no events were recorded")
+	}
+	validateNoEvents(events)
+}
+
+func validateNoEvents(events <-chan string) {
+	Consistently(func() string {
+		select {
+		case event := <-events:
+			return event
+		default:
+			return ""
+		}
+	}, duration, interval).Should(BeEmpty())
+}
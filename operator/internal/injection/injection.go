@@ -0,0 +1,271 @@
+package injection
+
+import (
+	"encoding/json"
+	extensionsv1 "fga-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"slices"
+	"strings"
+)
+
+// Injection describes how the values, e.g. OPENFGA_AUTH_MODEL_ID, are injected as environment variables into the
+// containers of a workload or pod. The zero value injects the store ID and authorization model ID with their
+// default names into every container.
+type Injection struct {
+	// envNames maps the names of the values to the names of their environment variables.
+	envNames map[string]string
+
+	// containers holds the names of the containers and init containers to inject into, every container when nil.
+	containers map[string]struct{}
+
+	// configMapName is set when the environment variables reference the keys of the delivery ConfigMap.
+	configMapName string
+}
+
+// values are the names of the values which can be injected.
+var values = []string{
+	extensionsv1.OpenFgaStoreIdEnv,
+	extensionsv1.OpenFgaAuthModelIdEnv,
+	extensionsv1.OpenFgaAuthModelVersionEnv,
+	extensionsv1.OpenFgaApiUrlEnv,
+}
+
+var envNameAnnotations = map[string]string{
+	extensionsv1.OpenFgaStoreIdEnv:          extensionsv1.OpenFgaStoreIdEnvAnnotation,
+	extensionsv1.OpenFgaAuthModelIdEnv:      extensionsv1.OpenFgaAuthModelIdEnvAnnotation,
+	extensionsv1.OpenFgaAuthModelVersionEnv: extensionsv1.OpenFgaAuthModelVersionEnvAnnotation,
+	extensionsv1.OpenFgaApiUrlEnv:           extensionsv1.OpenFgaApiUrlEnvAnnotation,
+}
+
+// FromObject returns the injection configured by the annotations of the workload or pod.
+func FromObject(object metav1.Object) Injection {
+	annotations := object.GetAnnotations()
+	injection := Injection{envNames: map[string]string{}}
+	for name, annotation := range envNameAnnotations {
+		if envName := strings.TrimSpace(annotations[annotation]); envName != "" {
+			injection.envNames[name] = envName
+		}
+	}
+	if containers, ok := annotations[extensionsv1.OpenFgaContainersAnnotation]; ok {
+		injection.containers = map[string]struct{}{}
+		for _, container := range strings.Split(containers, ",") {
+			if container = strings.TrimSpace(container); container != "" {
+				injection.containers[container] = struct{}{}
+			}
+		}
+	}
+	return injection
+}
+
+// WithConfigMap returns the injection with environment variables referencing the keys of the ConfigMap.
+func (i Injection) WithConfigMap(configMapName string) Injection {
+	i.configMapName = configMapName
+	return i
+}
+
+// ConfigMapName returns the name of the ConfigMap the environment variables reference, which is empty when they
+// hold the values.
+func (i Injection) ConfigMapName() string {
+	return i.configMapName
+}
+
+// EnvName returns the name of the environment variable of the value, which is empty when the value isn't injected.
+func (i Injection) EnvName(name string) string {
+	if envName, ok := i.envNames[name]; ok {
+		return envName
+	}
+	if name == extensionsv1.OpenFgaStoreIdEnv || name == extensionsv1.OpenFgaAuthModelIdEnv {
+		return name
+	}
+	return ""
+}
+
+// Targets returns the containers and init containers of the pod spec the values are injected into.
+func (i Injection) Targets(spec *corev1.PodSpec) []*corev1.Container {
+	var targets []*corev1.Container
+	for j := range spec.InitContainers {
+		if _, ok := i.containers[spec.InitContainers[j].Name]; ok {
+			targets = append(targets, &spec.InitContainers[j])
+		}
+	}
+	for j := range spec.Containers {
+		if _, ok := i.containers[spec.Containers[j].Name]; ok || i.containers == nil {
+			targets = append(targets, &spec.Containers[j])
+		}
+	}
+	return targets
+}
+
+// SetEnvVar sets the environment variable of the value on the targeted containers of the pod spec, and returns
+// true when any container changed. The environment variable references the key of the delivery ConfigMap instead
+// of holding the value when the injection uses the ConfigMap.
+func (i Injection) SetEnvVar(spec *corev1.PodSpec, name, value string) bool {
+	envName := i.EnvName(name)
+	if envName == "" {
+		return false
+	}
+	expected := corev1.EnvVar{Name: envName, Value: value}
+	if i.configMapName != "" {
+		expected = corev1.EnvVar{Name: envName, ValueFrom: &corev1.EnvVarSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: i.configMapName},
+				Key:                  name,
+			},
+		}}
+	}
+	return i.Set(spec, expected)
+}
+
+// Set sets the environment variable on the targeted containers of the pod spec, and returns true when any
+// container changed.
+func (i Injection) Set(spec *corev1.PodSpec, expected corev1.EnvVar) bool {
+	updated := false
+	for _, container := range i.Targets(spec) {
+		hasEnv := false
+		for j := range container.Env {
+			env := &container.Env[j]
+			if env.Name != expected.Name {
+				continue
+			}
+			hasEnv = true
+			if env.Value != expected.Value || !equality.Semantic.DeepEqual(env.ValueFrom, expected.ValueFrom) {
+				*env = expected
+				updated = true
+			}
+			break
+		}
+		if hasEnv {
+			continue
+		}
+		container.Env = append(container.Env, expected)
+		updated = true
+	}
+	return updated
+}
+
+// RemoveEnvVar removes the environment variable of the value from the targeted containers of the pod spec, and
+// returns true when any container changed.
+func (i Injection) RemoveEnvVar(spec *corev1.PodSpec, name string) bool {
+	envName := i.EnvName(name)
+	if envName == "" {
+		return false
+	}
+	removed := false
+	for _, container := range i.Targets(spec) {
+		for j, env := range container.Env {
+			if env.Name == envName {
+				container.Env = append(container.Env[:j], container.Env[j+1:]...)
+				removed = true
+				break
+			}
+		}
+	}
+	return removed
+}
+
+// HasEnvVar returns true when any targeted container of the pod spec has the environment variable of the value.
+func (i Injection) HasEnvVar(spec *corev1.PodSpec, name string) bool {
+	envName := i.EnvName(name)
+	if envName == "" {
+		return false
+	}
+	for _, container := range i.Targets(spec) {
+		for _, env := range container.Env {
+			if env.Name == envName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EnvVar returns the value of the environment variable of the value in the first targeted container having it.
+func (i Injection) EnvVar(spec *corev1.PodSpec, name string) string {
+	envName := i.EnvName(name)
+	if envName == "" {
+		return ""
+	}
+	for _, container := range i.Targets(spec) {
+		for _, env := range container.Env {
+			if env.Name == envName {
+				return env.Value
+			}
+		}
+	}
+	return ""
+}
+
+// Injected returns the names of the environment variables the injection sets, by targeted container.
+func (i Injection) Injected(spec *corev1.PodSpec) map[string][]string {
+	var envNames []string
+	for _, name := range values {
+		if envName := i.EnvName(name); envName != "" {
+			envNames = append(envNames, envName)
+		}
+	}
+	injected := map[string][]string{}
+	for _, container := range i.Targets(spec) {
+		injected[container.Name] = envNames
+	}
+	return injected
+}
+
+// RemoveStale removes the environment variables which were injected before, but aren't injected anymore since
+// their container isn't targeted or their name changed, and returns true when any container changed.
+func RemoveStale(spec *corev1.PodSpec, previous, injected map[string][]string) bool {
+	removed := false
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for j := range containers {
+			container := &containers[j]
+			for _, envName := range previous[container.Name] {
+				if slices.Contains(injected[container.Name], envName) {
+					continue
+				}
+				for k, env := range container.Env {
+					if env.Name == envName {
+						container.Env = append(container.Env[:k], container.Env[k+1:]...)
+						removed = true
+						break
+					}
+				}
+			}
+		}
+	}
+	return removed
+}
+
+// Recorded returns the names of the environment variables recorded on the workload by container. Workloads
+// without the record got the store ID and authorization model ID with their default names in every container.
+func Recorded(object metav1.Object, spec *corev1.PodSpec) map[string][]string {
+	recorded := map[string][]string{}
+	if value, ok := object.GetAnnotations()[extensionsv1.OpenFgaInjectedEnvAnnotation]; ok {
+		if err := json.Unmarshal([]byte(value), &recorded); err == nil {
+			return recorded
+		}
+	}
+	for _, container := range spec.Containers {
+		recorded[container.Name] = []string{extensionsv1.OpenFgaStoreIdEnv, extensionsv1.OpenFgaAuthModelIdEnv}
+	}
+	return recorded
+}
+
+// Record records the names of the injected environment variables on the workload, and returns true when the
+// record changed.
+func Record(object metav1.Object, injected map[string][]string) bool {
+	value, err := json.Marshal(injected)
+	if err != nil {
+		return false
+	}
+	annotations := object.GetAnnotations()
+	if current, ok := annotations[extensionsv1.OpenFgaInjectedEnvAnnotation]; ok && current == string(value) {
+		return false
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[extensionsv1.OpenFgaInjectedEnvAnnotation] = string(value)
+	object.SetAnnotations(annotations)
+	return true
+}
@@ -0,0 +1,109 @@
+package injection
+
+import (
+	extensionsv1 "fga-operator/api/v1"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func newPodSpec() *corev1.PodSpec {
+	return &corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "migrate"}},
+		Containers:     []corev1.Container{{Name: "app"}, {Name: "istio-proxy"}},
+	}
+}
+
+func envOf(spec *corev1.PodSpec) map[string][]corev1.EnvVar {
+	env := map[string][]corev1.EnvVar{}
+	for _, container := range append(spec.InitContainers, spec.Containers...) {
+		env[container.Name] = container.Env
+	}
+	return env
+}
+
+func TestInjectionSetEnvVar(t *testing.T) {
+	storeId := []corev1.EnvVar{{Name: extensionsv1.OpenFgaStoreIdEnv, Value: "store-id"}}
+
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		name        string
+		expected    map[string][]corev1.EnvVar
+	}{
+		{
+			description: "every container gets the store id by default",
+			name:        extensionsv1.OpenFgaStoreIdEnv,
+			expected:    map[string][]corev1.EnvVar{"migrate": nil, "app": storeId, "istio-proxy": storeId},
+		},
+		{
+			description: "named containers and init containers get the store id",
+			annotations: map[string]string{extensionsv1.OpenFgaContainersAnnotation: "app, migrate"},
+			name:        extensionsv1.OpenFgaStoreIdEnv,
+			expected:    map[string][]corev1.EnvVar{"migrate": storeId, "app": storeId, "istio-proxy": nil},
+		},
+		{
+			description: "store id is injected with the name of the annotation",
+			annotations: map[string]string{
+				extensionsv1.OpenFgaStoreIdEnvAnnotation: "FGA_STORE",
+				extensionsv1.OpenFgaContainersAnnotation: "app",
+			},
+			name: extensionsv1.OpenFgaStoreIdEnv,
+			expected: map[string][]corev1.EnvVar{
+				"migrate":     nil,
+				"app":         {{Name: "FGA_STORE", Value: "store-id"}},
+				"istio-proxy": nil,
+			},
+		},
+		{
+			description: "api url isn't injected without annotation",
+			name:        extensionsv1.OpenFgaApiUrlEnv,
+			expected:    map[string][]corev1.EnvVar{"migrate": nil, "app": nil, "istio-proxy": nil},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			spec := newPodSpec()
+			injection := FromObject(&metav1.ObjectMeta{Annotations: testCase.annotations})
+
+			// Act
+			injection.SetEnvVar(spec, testCase.name, "store-id")
+
+			// Assert
+			if diff := cmp.Diff(testCase.expected, envOf(spec)); diff != "" {
+				t.Errorf("env mismatch (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRemoveStale(t *testing.T) {
+	// Arrange
+	spec := newPodSpec()
+	spec.Containers[0].Env = []corev1.EnvVar{{Name: extensionsv1.OpenFgaStoreIdEnv}, {Name: "FOO"}}
+	spec.Containers[1].Env = []corev1.EnvVar{{Name: extensionsv1.OpenFgaStoreIdEnv}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		extensionsv1.OpenFgaStoreIdEnvAnnotation: "FGA_STORE",
+		extensionsv1.OpenFgaContainersAnnotation: "app",
+	}}}
+	injection := FromObject(pod)
+	injected := injection.Injected(spec)
+
+	// Act
+	removed := RemoveStale(spec, Recorded(pod, spec), injected)
+
+	// Assert
+	if !removed {
+		t.Errorf("expected the renamed and untargeted variables to be removed")
+	}
+	expected := map[string][]corev1.EnvVar{"migrate": nil, "app": {{Name: "FOO"}}, "istio-proxy": {}}
+	if diff := cmp.Diff(expected, envOf(spec)); diff != "" {
+		t.Errorf("env mismatch (-expected +actual):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string][]string{"app": {"FGA_STORE", extensionsv1.OpenFgaAuthModelIdEnv}}, injected); diff != "" {
+		t.Errorf("injected mismatch (-expected +actual):\n%s", diff)
+	}
+}
@@ -16,6 +16,15 @@ const (
 	// LabelDeployment represents the name of a deployment.
 	LabelDeployment = "deployment"
 
+	// LabelKind represents the kind of a workload, e.g. Deployment or CronJob.
+	LabelKind = "kind"
+
+	// LabelWorkload represents the name of a workload.
+	LabelWorkload = "workload"
+
+	// LabelResult represents the outcome of an operation, e.g. injected or skipped.
+	LabelResult = "result"
+
 	// LabelLocation represent if the entity has been saved as a CRD in Kubernetes or in OpenFGA.
 	LabelLocation = "location"
 )
@@ -24,7 +33,7 @@ var (
 	authorizationModelsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "authorization_model_events_total",
-			Help: "Total number of authorization models created, updated or delete",
+			Help: "Total number of authorization models created, updated, deleted or adopted",
 		},
 		[]string{LabelLocation, LabelEvent, LabelModel},
 	)
@@ -37,6 +46,14 @@ var (
 		[]string{LabelLocation, LabelModel},
 	)
 
+	storesDeletedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "stores_deleted_total",
+			Help: "Total number of stores deleted from OpenFGA.",
+		},
+		[]string{LabelModel},
+	)
+
 	deploymentUpdatedTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "deployment_updated_total",
@@ -44,6 +61,22 @@ var (
 		},
 		[]string{LabelDeployment, LabelModel},
 	)
+
+	workloadUpdatedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "workload_updated_total",
+			Help: "Total number of workloads updated, partitioned by kind.",
+		},
+		[]string{LabelKind, LabelWorkload, LabelModel},
+	)
+
+	podInjectionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pod_injections_total",
+			Help: "Total number of pods handled by the mutating webhook, partitioned by result.",
+		},
+		[]string{LabelModel, LabelResult},
+	)
 )
 
 type storeEvent string
@@ -59,12 +92,21 @@ const (
 	Created AuthorizationEvent = "created"
 	Updated AuthorizationEvent = "updated"
 	Deleted AuthorizationEvent = "deleted"
+	Adopted AuthorizationEvent = "adopted"
 )
 
 func RecordDeploymentUpdated(deploymentName, modelName string) {
 	deploymentUpdatedTotal.With(prometheus.Labels{LabelDeployment: deploymentName, LabelModel: modelName}).Inc()
 }
 
+func RecordWorkloadUpdated(kind, workloadName, modelName string) {
+	workloadUpdatedTotal.With(prometheus.Labels{LabelKind: kind, LabelWorkload: workloadName, LabelModel: modelName}).Inc()
+}
+
+func RecordPodInjection(modelName, result string) {
+	podInjectionsTotal.With(prometheus.Labels{LabelModel: modelName, LabelResult: result}).Inc()
+}
+
 func RecordK8StoreEvent(modelName string) {
 	storesTotal.With(prometheus.Labels{LabelLocation: string(kubernetes), LabelModel: modelName}).Inc()
 }
@@ -73,14 +115,18 @@ func RecordOpenFgaStoreEvent(modelName string) {
 	storesTotal.With(prometheus.Labels{LabelLocation: string(openFGA), LabelModel: modelName}).Inc()
 }
 
+func RecordOpenFgaStoreDeleted(modelName string) {
+	storesDeletedTotal.With(prometheus.Labels{LabelModel: modelName}).Inc()
+}
+
 func RecordK8AuthorizationModelEvent(event AuthorizationEvent, modelName string) {
 	authorizationModelsTotal.With(prometheus.Labels{LabelLocation: string(kubernetes), LabelEvent: string(event), LabelModel: modelName}).Inc()
 }
 
-func RecordOpenFgaAuthorizationModels(modelName string) {
-	authorizationModelsTotal.With(prometheus.Labels{LabelLocation: string(openFGA), LabelEvent: string(Created), LabelModel: modelName}).Inc()
+func RecordOpenFgaAuthorizationModels(event AuthorizationEvent, modelName string) {
+	authorizationModelsTotal.With(prometheus.Labels{LabelLocation: string(openFGA), LabelEvent: string(event), LabelModel: modelName}).Inc()
 }
 
 func InitializeCustomMetrics() {
-	metrics.Registry.MustRegister(authorizationModelsTotal, storesTotal, deploymentUpdatedTotal)
+	metrics.Registry.MustRegister(authorizationModelsTotal, storesTotal, storesDeletedTotal, deploymentUpdatedTotal, workloadUpdatedTotal, podInjectionsTotal)
 }
@@ -0,0 +1,123 @@
+package openfga
+
+import (
+	"fmt"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"sort"
+)
+
+// BreakingChanges returns the changes from the previous to the next authorization model, both in json,
+// which break clients of the previous model. A change is breaking when
+//   - a type is removed;
+//   - a relation of a type is removed;
+//   - a directly related user type of a relation is removed, i.e. the relation is narrowed;
+//   - a condition is removed, or its expression or parameters changed.
+//
+// Added types, relations, directly related user types and conditions aren't breaking.
+func BreakingChanges(previous, next string) ([]string, error) {
+	previousModel, err := parseModel(previous)
+	if err != nil {
+		return nil, err
+	}
+	nextModel, err := parseModel(next)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+	nextTypes := make(map[string]*openfgav1.TypeDefinition, len(nextModel.GetTypeDefinitions()))
+	for _, typeDefinition := range nextModel.GetTypeDefinitions() {
+		nextTypes[typeDefinition.GetType()] = typeDefinition
+	}
+	for _, typeDefinition := range sortedTypes(previousModel) {
+		nextType, exists := nextTypes[typeDefinition.GetType()]
+		if !exists {
+			changes = append(changes, fmt.Sprintf("type %s removed", typeDefinition.GetType()))
+			continue
+		}
+		for _, relation := range sortedKeys(typeDefinition.GetRelations()) {
+			name := typeDefinition.GetType() + "#" + relation
+			if _, exists := nextType.GetRelations()[relation]; !exists {
+				changes = append(changes, fmt.Sprintf("relation %s removed", name))
+				continue
+			}
+			nextUserTypes := directlyRelatedUserTypes(nextType, relation)
+			for _, userType := range sortedKeys(directlyRelatedUserTypes(typeDefinition, relation)) {
+				if !nextUserTypes[userType] {
+					changes = append(changes, fmt.Sprintf("relation %s no longer allows %s", name, userType))
+				}
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(previousModel.GetConditions()) {
+		nextCondition, exists := nextModel.GetConditions()[name]
+		switch {
+		case !exists:
+			changes = append(changes, fmt.Sprintf("condition %s removed", name))
+		case !sameCondition(previousModel.GetConditions()[name], nextCondition):
+			changes = append(changes, fmt.Sprintf("condition %s changed", name))
+		}
+	}
+	return changes, nil
+}
+
+func parseModel(model string) (*openfgav1.AuthorizationModel, error) {
+	authorizationModel := &openfgav1.AuthorizationModel{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal([]byte(model), authorizationModel); err != nil {
+		return nil, fmt.Errorf("invalid json authorization model: %w", err)
+	}
+	return authorizationModel, nil
+}
+
+func sortedTypes(authorizationModel *openfgav1.AuthorizationModel) []*openfgav1.TypeDefinition {
+	types := append([]*openfgav1.TypeDefinition(nil), authorizationModel.GetTypeDefinitions()...)
+	sort.SliceStable(types, func(i, j int) bool {
+		return types[i].GetType() < types[j].GetType()
+	})
+	return types
+}
+
+// directlyRelatedUserTypes returns the user types of the relation in DSL notation, e.g. "user",
+// "user:*", "group#member" or "user with non_expired".
+func directlyRelatedUserTypes(typeDefinition *openfgav1.TypeDefinition, relation string) map[string]bool {
+	userTypes := map[string]bool{}
+	for _, reference := range typeDefinition.GetMetadata().GetRelations()[relation].GetDirectlyRelatedUserTypes() {
+		userType := reference.GetType()
+		switch {
+		case reference.GetWildcard() != nil:
+			userType += ":*"
+		case reference.GetRelation() != "":
+			userType += "#" + reference.GetRelation()
+		}
+		if reference.GetCondition() != "" {
+			userType += " with " + reference.GetCondition()
+		}
+		userTypes[userType] = true
+	}
+	return userTypes
+}
+
+// sameCondition compares the expression and parameters of the conditions, ignoring their metadata.
+func sameCondition(previous, next *openfgav1.Condition) bool {
+	if previous.GetExpression() != next.GetExpression() || len(previous.GetParameters()) != len(next.GetParameters()) {
+		return false
+	}
+	for name, parameter := range previous.GetParameters() {
+		if !proto.Equal(parameter, next.GetParameters()[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,136 @@
+package openfga
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"github.com/openfga/language/pkg/go/transformer"
+	"testing"
+)
+
+const compatibilityModel = `
+model
+  schema 1.1
+
+type user
+
+type group
+  relations
+    define member: [user]
+
+type document
+  relations
+    define owner: [user]
+    define viewer: [user, user:*, group#member, user with non_expired] or owner
+
+condition non_expired(current_time: timestamp, expires_at: timestamp) {
+  current_time < expires_at
+}
+`
+
+func TestBreakingChanges(t *testing.T) {
+	testCases := []struct {
+		description string
+		next        string
+		expected    []string
+	}{
+		{
+			description: "unchanged model",
+			next:        compatibilityModel,
+		},
+		{
+			description: "added type, relation and user type",
+			next: `
+model
+  schema 1.1
+
+type user
+
+type team
+  relations
+    define member: [user]
+
+type group
+  relations
+    define member: [user, team#member]
+
+type document
+  relations
+    define owner: [user]
+    define editor: [user]
+    define viewer: [user, user:*, group#member, team#member, user with non_expired] or owner or editor
+
+condition non_expired(current_time: timestamp, expires_at: timestamp) {
+  current_time < expires_at
+}
+`,
+		},
+		{
+			description: "removed type, relation, user types and condition",
+			next: `
+model
+  schema 1.1
+
+type user
+
+type document
+  relations
+    define viewer: [user]
+`,
+			expected: []string{
+				"relation document#owner removed",
+				"relation document#viewer no longer allows group#member",
+				"relation document#viewer no longer allows user with non_expired",
+				"relation document#viewer no longer allows user:*",
+				"type group removed",
+				"condition non_expired removed",
+			},
+		},
+		{
+			description: "changed condition",
+			next: `
+model
+  schema 1.1
+
+type user
+
+type group
+  relations
+    define member: [user]
+
+type document
+  relations
+    define owner: [user]
+    define viewer: [user, user:*, group#member, user with non_expired] or owner
+
+condition non_expired(current_time: timestamp, expires_at: timestamp) {
+  current_time <= expires_at
+}
+`,
+			expected: []string{"condition non_expired changed"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			previous, err := transformer.TransformDSLToJSON(compatibilityModel)
+			if err != nil {
+				t.Fatal(err)
+			}
+			next, err := transformer.TransformDSLToJSON(testCase.next)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			changes, err := BreakingChanges(previous, next)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(testCase.expected, changes); diff != "" {
+				t.Errorf("unexpected breaking changes (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
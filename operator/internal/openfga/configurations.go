@@ -1,38 +1,397 @@
 package openfga
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
 	"fmt"
+	"github.com/openfga/go-sdk/credentials"
+	"github.com/openfga/go-sdk/oauth2"
+	"github.com/openfga/go-sdk/oauth2/clientcredentials"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 const OpenFgaApiUrl = "OPENFGA_API_URL"
 const OpenFgaApiToken = "OPENFGA_API_TOKEN"
 
+// Environment variables configuring the OAuth2 client credentials flow, in which the operator gets an
+// access token from the token issuer.
+const (
+	OpenFgaClientId       = "OPENFGA_CLIENT_ID"
+	OpenFgaClientSecret   = "OPENFGA_CLIENT_SECRET"
+	OpenFgaApiTokenIssuer = "OPENFGA_API_TOKEN_ISSUER"
+	OpenFgaApiAudience    = "OPENFGA_API_AUDIENCE"
+	OpenFgaApiScopes      = "OPENFGA_API_SCOPES"
+)
+
+// OpenFgaAuthMode selects how the operator authenticates to OpenFGA.
+const OpenFgaAuthMode = "OPENFGA_AUTH_MODE"
+
+// Environment variables configuring TLS connections to OpenFGA.
+const (
+	OpenFgaCaFile             = "OPENFGA_CA_FILE"
+	OpenFgaClientCertFile     = "OPENFGA_CLIENT_CERT_FILE"
+	OpenFgaClientKeyFile      = "OPENFGA_CLIENT_KEY_FILE"
+	OpenFgaInsecureSkipVerify = "OPENFGA_INSECURE_SKIP_VERIFY"
+)
+
+// AuthMode is the way the operator authenticates to OpenFGA.
+type AuthMode string
+
+const (
+	// AuthModeNone doesn't authenticate, e.g. for OpenFGA in development clusters.
+	AuthModeNone AuthMode = "none"
+
+	// AuthModeApiToken authenticates with a preshared key.
+	AuthModeApiToken AuthMode = "apiToken"
+
+	// AuthModeClientCredentials authenticates with an access token of the OAuth2 client credentials flow.
+	AuthModeClientCredentials AuthMode = "clientCredentials"
+)
+
+// OpenFgaCredentialsDir is a directory, usually a mounted secret, containing a file per setting named
+// like its environment variable, e.g. OPENFGA_CLIENT_SECRET.
+const OpenFgaCredentialsDir = "OPENFGA_CREDENTIALS_DIR"
+
 type Config struct {
-	ApiUrl   string
+	// Connection identifies the FGAConnection or ClusterFGAConnection the configuration was read from, and
+	// is empty for the OpenFGA server configured for the operator.
+	Connection string
+
+	ApiUrl string
+
+	// AuthMode is inferred from the credentials when it isn't set.
+	AuthMode AuthMode
 	ApiToken string
+
+	ClientId       string
+	ClientSecret   string
+	ApiTokenIssuer string
+	ApiAudience    string
+	// ApiScopes are separated by spaces.
+	ApiScopes string
+
+	// CaFile is a PEM bundle of certificate authorities trusted in addition to the system ones.
+	CaFile string
+	// ClientCertFile and ClientKeyFile are a PEM client certificate and key for mutual TLS.
+	ClientCertFile     string
+	ClientKeyFile      string
+	InsecureSkipVerify bool
+
+	// Ca, ClientCert and ClientKey are PEM contents, which are used instead of the files of a connection
+	// read from secrets.
+	Ca         string
+	ClientCert string
+	ClientKey  string
+
+	Requests RequestConfig
 }
 
-func NewConfig() (Config, error) {
-	apiUrl, err := getEnv(OpenFgaApiUrl)
+// Flags are the command line flags configuring OpenFGA, which take precedence over the environment
+// variables. Secrets can only be set using environment variables or the credentials directory.
+type Flags struct {
+	ApiUrl             string
+	AuthMode           string
+	ClientId           string
+	ApiTokenIssuer     string
+	ApiAudience        string
+	ApiScopes          string
+	CredentialsDir     string
+	CaFile             string
+	ClientCertFile     string
+	ClientKeyFile      string
+	InsecureSkipVerify bool
+	Requests           RequestFlags
+}
+
+// BindFlags binds the flags to the flag set.
+func (f *Flags) BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.ApiUrl, "openfga-api-url", "", "URL of the OpenFGA API. Overrides "+OpenFgaApiUrl+".")
+	fs.StringVar(&f.AuthMode, "openfga-auth-mode", "",
+		"Authentication to OpenFGA, one of none, apiToken and clientCredentials. Inferred from the credentials when not set. Overrides "+OpenFgaAuthMode+".")
+	fs.StringVar(&f.ClientId, "openfga-client-id", "", "Client ID of the OAuth2 client credentials flow. Overrides "+OpenFgaClientId+".")
+	fs.StringVar(&f.ApiTokenIssuer, "openfga-api-token-issuer", "",
+		"Token issuer of the OAuth2 client credentials flow. Overrides "+OpenFgaApiTokenIssuer+".")
+	fs.StringVar(&f.ApiAudience, "openfga-api-audience", "", "Audience of the OAuth2 client credentials flow. Overrides "+OpenFgaApiAudience+".")
+	fs.StringVar(&f.ApiScopes, "openfga-api-scopes", "",
+		"Space separated scopes of the OAuth2 client credentials flow. Overrides "+OpenFgaApiScopes+".")
+	fs.StringVar(&f.CredentialsDir, "openfga-credentials-dir", "",
+		"Directory with a file per setting named like its environment variable, e.g. a mounted secret. Overrides "+OpenFgaCredentialsDir+".")
+	fs.StringVar(&f.CaFile, "openfga-ca-file", "", "PEM bundle of additionally trusted certificate authorities. Overrides "+OpenFgaCaFile+".")
+	fs.StringVar(&f.ClientCertFile, "openfga-client-cert-file", "", "PEM client certificate for mutual TLS. Overrides "+OpenFgaClientCertFile+".")
+	fs.StringVar(&f.ClientKeyFile, "openfga-client-key-file", "", "PEM client key for mutual TLS. Overrides "+OpenFgaClientKeyFile+".")
+	fs.BoolVar(&f.InsecureSkipVerify, "openfga-insecure-skip-verify", false,
+		"If set, the certificate of OpenFGA isn't verified. Only use this for development. Overrides "+OpenFgaInsecureSkipVerify+".")
+	f.Requests.bindFlags(fs)
+}
+
+// NewConfig reads the configuration from the flags, the environment variables and the credentials
+// directory, in that order of precedence, and validates it including the certificate files.
+func NewConfig(flags Flags) (Config, error) {
+	credentialsDir := flags.CredentialsDir
+	if credentialsDir == "" {
+		credentialsDir = os.Getenv(OpenFgaCredentialsDir)
+	}
+	config := Config{}
+	var authMode, insecureSkipVerify string
+	settings := []struct {
+		flag  string
+		name  string
+		value *string
+	}{
+		{flags.ApiUrl, OpenFgaApiUrl, &config.ApiUrl},
+		{"", OpenFgaApiToken, &config.ApiToken},
+		{flags.ClientId, OpenFgaClientId, &config.ClientId},
+		{"", OpenFgaClientSecret, &config.ClientSecret},
+		{flags.ApiTokenIssuer, OpenFgaApiTokenIssuer, &config.ApiTokenIssuer},
+		{flags.ApiAudience, OpenFgaApiAudience, &config.ApiAudience},
+		{flags.ApiScopes, OpenFgaApiScopes, &config.ApiScopes},
+		{flags.AuthMode, OpenFgaAuthMode, &authMode},
+		{flags.CaFile, OpenFgaCaFile, &config.CaFile},
+		{flags.ClientCertFile, OpenFgaClientCertFile, &config.ClientCertFile},
+		{flags.ClientKeyFile, OpenFgaClientKeyFile, &config.ClientKeyFile},
+		{boolFlag(flags.InsecureSkipVerify), OpenFgaInsecureSkipVerify, &insecureSkipVerify},
+	}
+	for _, setting := range settings {
+		value, err := getSetting(setting.flag, setting.name, credentialsDir)
+		if err != nil {
+			return Config{}, err
+		}
+		*setting.value = value
+	}
+	config.AuthMode = AuthMode(authMode)
+	if insecureSkipVerify != "" {
+		skip, err := strconv.ParseBool(insecureSkipVerify)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s: %w", OpenFgaInsecureSkipVerify, err)
+		}
+		config.InsecureSkipVerify = skip
+	}
+	requests, err := newRequestConfig(flags.Requests, credentialsDir)
 	if err != nil {
 		return Config{}, err
 	}
-	apiToken, err := getEnv(OpenFgaApiToken)
-	if err != nil {
+	config.Requests = requests
+
+	if err := config.Validate(); err != nil {
 		return Config{}, err
 	}
+	return config, nil
+}
 
-	return Config{
-		ApiUrl:   apiUrl,
-		ApiToken: apiToken,
-	}, nil
+// Validate validates the configuration, including the certificates.
+func (c Config) Validate() error {
+	if c.ApiUrl == "" {
+		return fmt.Errorf("environment variable %s not found", OpenFgaApiUrl)
+	}
+	if err := c.validateCredentials(); err != nil {
+		return err
+	}
+	if err := c.Requests.validate(); err != nil {
+		return err
+	}
+	if _, err := c.tlsConfig(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c Config) validateCredentials() error {
+	hasClientCredentials := c.ClientId != "" || c.ClientSecret != "" || c.ApiTokenIssuer != ""
+	switch c.authMode() {
+	case AuthModeNone:
+		if c.ApiToken != "" || hasClientCredentials {
+			return fmt.Errorf("%s and client credentials may not be set with auth mode %s", OpenFgaApiToken, AuthModeNone)
+		}
+		return nil
+	case AuthModeApiToken:
+		if c.ApiToken == "" {
+			return fmt.Errorf("environment variable %s not found", OpenFgaApiToken)
+		}
+		if hasClientCredentials {
+			return fmt.Errorf("%s may not be set together with the client credentials %s, %s and %s",
+				OpenFgaApiToken, OpenFgaClientId, OpenFgaClientSecret, OpenFgaApiTokenIssuer)
+		}
+		return nil
+	case AuthModeClientCredentials:
+		if c.ApiToken != "" {
+			return fmt.Errorf("%s may not be set together with the client credentials %s, %s and %s",
+				OpenFgaApiToken, OpenFgaClientId, OpenFgaClientSecret, OpenFgaApiTokenIssuer)
+		}
+		required := []struct{ name, value string }{
+			{OpenFgaClientId, c.ClientId},
+			{OpenFgaClientSecret, c.ClientSecret},
+			{OpenFgaApiTokenIssuer, c.ApiTokenIssuer},
+		}
+		for _, setting := range required {
+			if setting.value == "" {
+				return fmt.Errorf("%s is required for the client credentials flow", setting.name)
+			}
+		}
+		if err := c.credentials().ValidateCredentialsConfig(); err != nil {
+			return fmt.Errorf("invalid client credentials: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid %s %s, must be one of %s, %s and %s",
+			OpenFgaAuthMode, c.AuthMode, AuthModeNone, AuthModeApiToken, AuthModeClientCredentials)
+	}
+}
+
+// authMode returns the auth mode, which is inferred from the credentials when it isn't set, such that
+// configurations with only an API token keep working.
+func (c Config) authMode() AuthMode {
+	switch {
+	case c.AuthMode != "":
+		return c.AuthMode
+	case c.ClientId != "" || c.ClientSecret != "" || c.ApiTokenIssuer != "":
+		return AuthModeClientCredentials
+	default:
+		return AuthModeApiToken
+	}
+}
+
+// credentials returns the credentials of the SDK client for the configuration.
+func (c Config) credentials() *credentials.Credentials {
+	switch c.authMode() {
+	case AuthModeClientCredentials:
+		return &credentials.Credentials{
+			Method: credentials.CredentialsMethodClientCredentials,
+			Config: &credentials.Config{
+				ClientCredentialsClientId:       c.ClientId,
+				ClientCredentialsClientSecret:   c.ClientSecret,
+				ClientCredentialsApiTokenIssuer: c.ApiTokenIssuer,
+				ClientCredentialsApiAudience:    c.ApiAudience,
+				ClientCredentialsScopes:         c.ApiScopes,
+			},
+		}
+	case AuthModeApiToken:
+		return &credentials.Credentials{
+			Method: credentials.CredentialsMethodApiToken,
+			Config: &credentials.Config{
+				ApiToken: c.ApiToken,
+			},
+		}
+	default:
+		return &credentials.Credentials{Method: credentials.CredentialsMethodNone}
+	}
+}
+
+// tlsConfig returns the TLS configuration of connections to OpenFGA and the token issuer, or nil when
+// the defaults are used.
+func (c Config) tlsConfig() (*tls.Config, error) {
+	hasClientCert := c.ClientCertFile != "" || c.ClientCert != ""
+	hasClientKey := c.ClientKeyFile != "" || c.ClientKey != ""
+	if c.CaFile == "" && c.Ca == "" && !hasClientCert && !hasClientKey && !c.InsecureSkipVerify {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CaFile != "" || c.Ca != "" {
+		ca := []byte(c.Ca)
+		if c.CaFile != "" {
+			var err error
+			ca, err = os.ReadFile(c.CaFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read %s: %w", OpenFgaCaFile, err)
+			}
+		}
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(ca) {
+			if c.CaFile == "" {
+				return nil, fmt.Errorf("no certificates found in certificate authorities")
+			}
+			return nil, fmt.Errorf("no certificates found in %s %s", OpenFgaCaFile, c.CaFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	if hasClientCert != hasClientKey {
+		return nil, fmt.Errorf("%s and %s must be set together", OpenFgaClientCertFile, OpenFgaClientKeyFile)
+	}
+	if hasClientCert {
+		var certificate tls.Certificate
+		var err error
+		if c.ClientCertFile != "" {
+			certificate, err = tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		} else {
+			certificate, err = tls.X509KeyPair([]byte(c.ClientCert), []byte(c.ClientKey))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
+}
+
+// httpClient returns the HTTP client of the SDK, which uses the TLS configuration for both the requests
+// to OpenFGA and the token requests of the client credentials flow. The credentials must have been
+// validated by the SDK, which normalizes the token issuer.
+func (c Config) httpClient(sdkCredentials *credentials.Credentials) (*http.Client, error) {
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	client := &http.Client{Transport: transport}
+	if sdkCredentials.Method != credentials.CredentialsMethodClientCredentials {
+		return client, nil
+	}
+
+	clientCredentials := clientcredentials.Config{
+		ClientID:     sdkCredentials.Config.ClientCredentialsClientId,
+		ClientSecret: sdkCredentials.Config.ClientCredentialsClientSecret,
+		TokenURL:     sdkCredentials.Config.ClientCredentialsApiTokenIssuer,
+	}
+	if sdkCredentials.Config.ClientCredentialsApiAudience != "" {
+		clientCredentials.EndpointParams = map[string][]string{
+			"audience": {sdkCredentials.Config.ClientCredentialsApiAudience},
+		}
+	}
+	if scopes := strings.TrimSpace(sdkCredentials.Config.ClientCredentialsScopes); scopes != "" {
+		clientCredentials.Scopes = strings.Fields(scopes)
+	}
+	return clientCredentials.Client(context.WithValue(context.Background(), oauth2.HTTPClient, client)), nil
+}
+
+// getSetting returns the value of the flag, or else of the environment variable, or else of the file
+// named like the environment variable in the credentials directory. A missing setting is empty.
+func getSetting(flagValue, name, credentialsDir string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	if value := os.Getenv(name); value != "" {
+		return value, nil
+	}
+	if credentialsDir == "" {
+		return "", nil
+	}
+	value, err := os.ReadFile(filepath.Join(credentialsDir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s from credentials directory: %w", name, err)
+	}
+	return strings.TrimSpace(string(value)), nil
 }
 
-func getEnv(value string) (string, error) {
-	variable := os.Getenv(value)
-	if variable == "" {
-		return "", fmt.Errorf("environment variable %s not found", value)
+// boolFlag returns an empty string for a false flag, such that it doesn't override the environment variable.
+func boolFlag(value bool) string {
+	if !value {
+		return ""
 	}
-	return variable, nil
+	return strconv.FormatBool(value)
 }
@@ -0,0 +1,547 @@
+package openfga
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/google/go-cmp/cmp"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearConfigEnv clears the environment variables of the configuration for the duration of the test.
+func clearConfigEnv(t *testing.T) {
+	for _, name := range []string{
+		OpenFgaApiUrl, OpenFgaApiToken, OpenFgaClientId, OpenFgaClientSecret,
+		OpenFgaApiTokenIssuer, OpenFgaApiAudience, OpenFgaApiScopes, OpenFgaCredentialsDir,
+		OpenFgaAuthMode, OpenFgaCaFile, OpenFgaClientCertFile, OpenFgaClientKeyFile, OpenFgaInsecureSkipVerify,
+		OpenFgaPageSize, OpenFgaStoreIndexTTL, OpenFgaMaxRetries, OpenFgaRetryBaseDelay, OpenFgaRetryMaxDelay,
+		OpenFgaCallTimeout, OpenFgaRateLimit, OpenFgaRateBurst,
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+// defaultRequests is the request configuration when none of its settings are set.
+var defaultRequests = RequestConfig{
+	StoreIndexTTL:  DefaultStoreIndexTTL,
+	MaxRetries:     DefaultMaxRetries,
+	RetryBaseDelay: DefaultRetryBaseDelay,
+	RetryMaxDelay:  DefaultRetryMaxDelay,
+	CallTimeout:    DefaultCallTimeout,
+	RateLimit:      DefaultRateLimit,
+}
+
+func TestNewConfig(t *testing.T) {
+	testCases := []struct {
+		description string
+		env         map[string]string
+		flags       Flags
+		expected    Config
+	}{
+		{
+			description: "api token",
+			env:         map[string]string{OpenFgaApiUrl: "http://openfga:8080", OpenFgaApiToken: "token"},
+			expected:    Config{ApiUrl: "http://openfga:8080", ApiToken: "token", Requests: defaultRequests},
+		},
+		{
+			description: "client credentials",
+			env: map[string]string{
+				OpenFgaApiUrl:         "http://openfga:8080",
+				OpenFgaClientId:       "operator",
+				OpenFgaClientSecret:   "secret",
+				OpenFgaApiTokenIssuer: "https://issuer.example.com/oauth2/token",
+				OpenFgaApiAudience:    "https://openfga.example.com",
+				OpenFgaApiScopes:      "read write",
+			},
+			expected: Config{
+				ApiUrl:         "http://openfga:8080",
+				ClientId:       "operator",
+				ClientSecret:   "secret",
+				ApiTokenIssuer: "https://issuer.example.com/oauth2/token",
+				ApiAudience:    "https://openfga.example.com",
+				ApiScopes:      "read write",
+				Requests:       defaultRequests,
+			},
+		},
+		{
+			description: "flags take precedence over environment variables",
+			env: map[string]string{
+				OpenFgaApiUrl:         "http://openfga:8080",
+				OpenFgaClientId:       "operator",
+				OpenFgaClientSecret:   "secret",
+				OpenFgaApiTokenIssuer: "issuer.example.com",
+			},
+			flags: Flags{ApiUrl: "http://other:8080", ClientId: "other", ApiScopes: "read"},
+			expected: Config{
+				ApiUrl:         "http://other:8080",
+				ClientId:       "other",
+				ClientSecret:   "secret",
+				ApiTokenIssuer: "issuer.example.com",
+				ApiScopes:      "read",
+				Requests:       defaultRequests,
+			},
+		},
+		{
+			description: "requests",
+			env: map[string]string{
+				OpenFgaApiUrl:         "http://openfga:8080",
+				OpenFgaApiToken:       "token",
+				OpenFgaPageSize:       "20",
+				OpenFgaStoreIndexTTL:  "0",
+				OpenFgaMaxRetries:     "0",
+				OpenFgaRetryBaseDelay: "1s",
+				OpenFgaRetryMaxDelay:  "1m",
+				OpenFgaCallTimeout:    "5s",
+				OpenFgaRateLimit:      "10",
+			},
+			flags: Flags{Requests: RequestFlags{PageSize: "100", RateBurst: "20"}},
+			expected: Config{
+				ApiUrl:   "http://openfga:8080",
+				ApiToken: "token",
+				Requests: RequestConfig{
+					PageSize:       100,
+					RetryBaseDelay: time.Second,
+					RetryMaxDelay:  time.Minute,
+					CallTimeout:    5 * time.Second,
+					RateLimit:      10,
+					RateBurst:      20,
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			clearConfigEnv(t)
+			for name, value := range testCase.env {
+				t.Setenv(name, value)
+			}
+
+			// Act
+			config, err := NewConfig(testCase.flags)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(testCase.expected, config); diff != "" {
+				t.Errorf("config mismatch (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewConfigFromCredentialsDir(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	for name, value := range map[string]string{
+		OpenFgaClientId:       "operator\n",
+		OpenFgaClientSecret:   "secret\n",
+		OpenFgaApiTokenIssuer: "https://issuer.example.com",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clearConfigEnv(t)
+	t.Setenv(OpenFgaApiUrl, "http://openfga:8080")
+	t.Setenv(OpenFgaClientId, "from-env")
+	t.Setenv(OpenFgaCredentialsDir, dir)
+
+	// Act
+	config, err := NewConfig(Flags{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Config{
+		ApiUrl:         "http://openfga:8080",
+		ClientId:       "from-env",
+		ClientSecret:   "secret",
+		ApiTokenIssuer: "https://issuer.example.com",
+		Requests:       defaultRequests,
+	}
+	if diff := cmp.Diff(expected, config); diff != "" {
+		t.Errorf("config mismatch (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestNewConfigErrors(t *testing.T) {
+	testCases := []struct {
+		description string
+		env         map[string]string
+		expected    string
+	}{
+		{
+			description: "missing url",
+			env:         map[string]string{OpenFgaApiToken: "token"},
+			expected:    "environment variable OPENFGA_API_URL not found",
+		},
+		{
+			description: "missing credentials",
+			env:         map[string]string{OpenFgaApiUrl: "http://openfga:8080"},
+			expected:    "environment variable OPENFGA_API_TOKEN not found",
+		},
+		{
+			description: "missing client secret",
+			env: map[string]string{
+				OpenFgaApiUrl:         "http://openfga:8080",
+				OpenFgaClientId:       "operator",
+				OpenFgaApiTokenIssuer: "https://issuer.example.com",
+			},
+			expected: "OPENFGA_CLIENT_SECRET is required for the client credentials flow",
+		},
+		{
+			description: "api token and client credentials",
+			env: map[string]string{
+				OpenFgaApiUrl:         "http://openfga:8080",
+				OpenFgaApiToken:       "token",
+				OpenFgaClientId:       "operator",
+				OpenFgaClientSecret:   "secret",
+				OpenFgaApiTokenIssuer: "https://issuer.example.com",
+			},
+			expected: "OPENFGA_API_TOKEN may not be set together with the client credentials",
+		},
+		{
+			description: "invalid token issuer",
+			env: map[string]string{
+				OpenFgaApiUrl:         "http://openfga:8080",
+				OpenFgaClientId:       "operator",
+				OpenFgaClientSecret:   "secret",
+				OpenFgaApiTokenIssuer: "ftp://issuer.example.com",
+			},
+			expected: "invalid client credentials: invalid issuer scheme 'ftp'",
+		},
+		{
+			description: "page size too large",
+			env:         map[string]string{OpenFgaApiUrl: "http://openfga:8080", OpenFgaApiToken: "token", OpenFgaPageSize: "500"},
+			expected:    "invalid OPENFGA_PAGE_SIZE 500, must be between 1 and 100",
+		},
+		{
+			description: "invalid store index ttl",
+			env:         map[string]string{OpenFgaApiUrl: "http://openfga:8080", OpenFgaApiToken: "token", OpenFgaStoreIndexTTL: "5"},
+			expected:    "invalid OPENFGA_STORE_INDEX_TTL",
+		},
+		{
+			description: "negative rate limit",
+			env:         map[string]string{OpenFgaApiUrl: "http://openfga:8080", OpenFgaApiToken: "token", OpenFgaRateLimit: "-1"},
+			expected:    "invalid OPENFGA_RATE_LIMIT -1, may not be negative",
+		},
+		{
+			description: "retry base delay longer than max delay",
+			env:         map[string]string{OpenFgaApiUrl: "http://openfga:8080", OpenFgaApiToken: "token", OpenFgaRetryBaseDelay: "1m"},
+			expected:    "OPENFGA_RETRY_BASE_DELAY 1m0s may not be longer than OPENFGA_RETRY_MAX_DELAY 10s",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			clearConfigEnv(t)
+			for name, value := range testCase.env {
+				t.Setenv(name, value)
+			}
+
+			// Act
+			_, err := NewConfig(Flags{})
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), testCase.expected) {
+				t.Errorf("expected error containing %q, got %v", testCase.expected, err)
+			}
+		})
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	// Arrange
+	var tokenRequest http.Request
+	issuer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		tokenRequest = *r
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token": "access-token", "token_type": "Bearer", "expires_in": 3600}`))
+	}))
+	defer issuer.Close()
+
+	var authorization string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"stores": []any{}, "continuation_token": ""})
+	}))
+	defer api.Close()
+
+	service, err := newOpenFgaService(Config{
+		ApiUrl:         api.URL,
+		ClientId:       "operator",
+		ClientSecret:   "secret",
+		ApiTokenIssuer: issuer.URL + "/oauth2/token",
+		ApiAudience:    "https://openfga.example.com",
+		ApiScopes:      "read write",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	_, err = service.CheckExistingStoresByName(context.Background(), "store")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if authorization != "Bearer access-token" {
+		t.Errorf("expected access token of the issuer, got authorization %q", authorization)
+	}
+	if tokenRequest.URL.Path != "/oauth2/token" {
+		t.Errorf("expected token request to /oauth2/token, got %s", tokenRequest.URL.Path)
+	}
+	expectedForm := map[string]string{
+		"grant_type": "client_credentials",
+		"audience":   "https://openfga.example.com",
+		"scope":      "read write",
+	}
+	for key, value := range expectedForm {
+		if actual := tokenRequest.PostForm.Get(key); actual != value {
+			t.Errorf("expected %s %q in token request, got %q", key, value, actual)
+		}
+	}
+	if clientId, clientSecret, ok := tokenRequest.BasicAuth(); !(ok && clientId == "operator" && clientSecret == "secret") &&
+		tokenRequest.PostForm.Get("client_id") != "operator" {
+		t.Errorf("expected client credentials in token request")
+	}
+}
+
+func TestTLS(t *testing.T) {
+	clientCa, clientCertFile, clientKeyFile := writeClientCertificate(t)
+	newServer := func(requireClientCertificate bool) *httptest.Server {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"stores": []any{}, "continuation_token": ""})
+		}))
+		if requireClientCertificate {
+			server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCa}
+		}
+		server.StartTLS()
+		return server
+	}
+
+	testCases := []struct {
+		description              string
+		requireClientCertificate bool
+		config                   func(caFile string) Config
+		expectedError            bool
+	}{
+		{
+			description: "untrusted server certificate",
+			config: func(caFile string) Config {
+				return Config{AuthMode: AuthModeNone}
+			},
+			expectedError: true,
+		},
+		{
+			description: "ca file",
+			config: func(caFile string) Config {
+				return Config{AuthMode: AuthModeNone, CaFile: caFile}
+			},
+		},
+		{
+			description: "insecure skip verify",
+			config: func(caFile string) Config {
+				return Config{AuthMode: AuthModeNone, InsecureSkipVerify: true}
+			},
+		},
+		{
+			description:              "missing client certificate",
+			requireClientCertificate: true,
+			config: func(caFile string) Config {
+				return Config{AuthMode: AuthModeNone, CaFile: caFile}
+			},
+			expectedError: true,
+		},
+		{
+			description:              "client certificate",
+			requireClientCertificate: true,
+			config: func(caFile string) Config {
+				return Config{AuthMode: AuthModeNone, CaFile: caFile, ClientCertFile: clientCertFile, ClientKeyFile: clientKeyFile}
+			},
+		},
+		{
+			description:              "certificates read from secrets",
+			requireClientCertificate: true,
+			config: func(caFile string) Config {
+				return Config{AuthMode: AuthModeNone, Ca: readFile(t, caFile), ClientCert: readFile(t, clientCertFile), ClientKey: readFile(t, clientKeyFile)}
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			server := newServer(testCase.requireClientCertificate)
+			defer server.Close()
+			caFile := filepath.Join(t.TempDir(), "ca.pem")
+			writePem(t, caFile, "CERTIFICATE", server.Certificate().Raw)
+			config := testCase.config(caFile)
+			config.ApiUrl = server.URL
+			service, err := newOpenFgaService(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			_, err = service.CheckExistingStoresByName(context.Background(), "store")
+
+			// Assert
+			if (err != nil) != testCase.expectedError {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestNewConfigWithTLS(t *testing.T) {
+	_, clientCertFile, clientKeyFile := writeClientCertificate(t)
+	invalidCaFile := filepath.Join(t.TempDir(), "invalid.pem")
+	if err := os.WriteFile(invalidCaFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		description string
+		env         map[string]string
+		flags       Flags
+		expected    string
+	}{
+		{
+			description: "auth mode none with client certificate",
+			env:         map[string]string{OpenFgaAuthMode: "none", OpenFgaClientCertFile: clientCertFile, OpenFgaClientKeyFile: clientKeyFile},
+		},
+		{
+			description: "insecure skip verify flag",
+			flags:       Flags{AuthMode: "none", InsecureSkipVerify: true},
+		},
+		{
+			description: "auth mode none with api token",
+			env:         map[string]string{OpenFgaAuthMode: "none", OpenFgaApiToken: "token"},
+			expected:    "OPENFGA_API_TOKEN and client credentials may not be set with auth mode none",
+		},
+		{
+			description: "unknown auth mode",
+			env:         map[string]string{OpenFgaAuthMode: "oidc"},
+			expected:    "invalid OPENFGA_AUTH_MODE oidc",
+		},
+		{
+			description: "client certificate without key",
+			env:         map[string]string{OpenFgaAuthMode: "none", OpenFgaClientCertFile: clientCertFile},
+			expected:    "OPENFGA_CLIENT_CERT_FILE and OPENFGA_CLIENT_KEY_FILE must be set together",
+		},
+		{
+			description: "invalid ca file",
+			env:         map[string]string{OpenFgaAuthMode: "none", OpenFgaCaFile: invalidCaFile},
+			expected:    "no certificates found in OPENFGA_CA_FILE",
+		},
+		{
+			description: "invalid insecure skip verify",
+			env:         map[string]string{OpenFgaAuthMode: "none", OpenFgaInsecureSkipVerify: "maybe"},
+			expected:    "invalid OPENFGA_INSECURE_SKIP_VERIFY",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			clearConfigEnv(t)
+			t.Setenv(OpenFgaApiUrl, "https://openfga:8080")
+			for name, value := range testCase.env {
+				t.Setenv(name, value)
+			}
+
+			// Act
+			_, err := NewConfig(testCase.flags)
+
+			// Assert
+			if testCase.expected == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), testCase.expected) {
+				t.Errorf("expected error containing %q, got %v", testCase.expected, err)
+			}
+		})
+	}
+}
+
+// writeClientCertificate writes a self-signed client certificate and its key, and returns a pool
+// trusting the certificate.
+func writeClientCertificate(t *testing.T) (*x509.CertPool, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fga-operator"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writePem(t, certFile, "CERTIFICATE", der)
+	writePem(t, keyFile, "EC PRIVATE KEY", keyDer)
+	pool := x509.NewCertPool()
+	pool.AddCert(certificate)
+	return pool, certFile, keyFile
+}
+
+func readFile(t *testing.T, file string) string {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func writePem(t *testing.T, file, blockType string, der []byte) {
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
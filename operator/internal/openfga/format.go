@@ -0,0 +1,78 @@
+package openfga
+
+import (
+	"fmt"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/openfga/language/pkg/go/transformer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"sort"
+)
+
+// ValidateJSONModel validates an authorization model in the json syntax of the OpenFGA API, which is the
+// body of a WriteAuthorizationModel request. Unknown fields are rejected.
+func ValidateJSONModel(model string) error {
+	request := &openfgav1.WriteAuthorizationModelRequest{}
+	if err := protojson.Unmarshal([]byte(model), request); err != nil {
+		return fmt.Errorf("invalid json authorization model: %w", err)
+	}
+	if request.GetSchemaVersion() == "" {
+		return fmt.Errorf("invalid json authorization model: schema_version is required")
+	}
+	if len(request.GetTypeDefinitions()) == 0 {
+		return fmt.Errorf("invalid json authorization model: type_definitions must contain at least one type")
+	}
+	for _, typeDefinition := range request.GetTypeDefinitions() {
+		if err := typeDefinition.ValidateAll(); err != nil {
+			return fmt.Errorf("invalid json authorization model: type %s: %w", typeDefinition.GetType(), err)
+		}
+	}
+	for name, condition := range request.GetConditions() {
+		if err := condition.ValidateAll(); err != nil {
+			return fmt.Errorf("invalid json authorization model: condition %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// RenderDSL renders an authorization model in json to DSL.
+func RenderDSL(model string) (string, error) {
+	dsl, err := transformer.TransformJSONStringToDSL(model)
+	if err != nil {
+		return "", err
+	}
+	return *dsl, nil
+}
+
+// IsSameModel returns true when two authorization models in DSL compile to the same model, such that
+// formatting changes and comments are allowed.
+func IsSameModel(existingDsl, dsl string) bool {
+	existingCompiled, err := transformer.TransformDSLToJSON(existingDsl)
+	if err != nil {
+		return false
+	}
+	compiled, err := transformer.TransformDSLToJSON(dsl)
+	if err != nil {
+		return false
+	}
+	return existingCompiled == compiled
+}
+
+// CanonicalizeJSONModel returns a canonical form of an authorization model in json, such that two models
+// with the same schema version, type definitions and conditions have the same canonical form regardless
+// of formatting, field order, the order of the type definitions or the ID of the model.
+func CanonicalizeJSONModel(model string) (string, error) {
+	authorizationModel := &openfgav1.AuthorizationModel{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal([]byte(model), authorizationModel); err != nil {
+		return "", fmt.Errorf("invalid json authorization model: %w", err)
+	}
+	authorizationModel.Id = ""
+	sort.SliceStable(authorizationModel.TypeDefinitions, func(i, j int) bool {
+		return authorizationModel.TypeDefinitions[i].GetType() < authorizationModel.TypeDefinitions[j].GetType()
+	})
+	canonical, err := proto.MarshalOptions{Deterministic: true}.Marshal(authorizationModel)
+	if err != nil {
+		return "", err
+	}
+	return string(canonical), nil
+}
@@ -0,0 +1,202 @@
+package openfga
+
+import (
+	"encoding/json"
+	openfga "github.com/openfga/go-sdk"
+	"github.com/openfga/language/pkg/go/transformer"
+	"strings"
+	"testing"
+)
+
+const jsonModel = `{
+  "schema_version": "1.1",
+  "type_definitions": [
+    {"type": "user"},
+    {
+      "type": "document",
+      "relations": {"reader": {"this": {}}},
+      "metadata": {"relations": {"reader": {"directly_related_user_types": [{"type": "user"}]}}}
+    }
+  ]
+}`
+
+func TestValidateJSONModel(t *testing.T) {
+	testCases := []struct {
+		description string
+		model       string
+		expected    string
+	}{
+		{
+			description: "valid model",
+			model:       jsonModel,
+		},
+		{
+			description: "malformed json",
+			model:       `{"schema_version": "1.1",`,
+			expected:    "invalid json authorization model",
+		},
+		{
+			description: "unknown field",
+			model:       `{"schema_version": "1.1", "type_definitions": [{"type": "user"}], "types": []}`,
+			expected:    `unknown field "types"`,
+		},
+		{
+			description: "missing schema version",
+			model:       `{"type_definitions": [{"type": "user"}]}`,
+			expected:    "invalid json authorization model: schema_version is required",
+		},
+		{
+			description: "no type definitions",
+			model:       `{"schema_version": "1.1"}`,
+			expected:    "invalid json authorization model: type_definitions must contain at least one type",
+		},
+		{
+			description: "invalid type name",
+			model:       `{"schema_version": "1.1", "type_definitions": [{"type": "user name"}]}`,
+			expected:    "invalid json authorization model: type user name",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Act
+			err := ValidateJSONModel(testCase.model)
+
+			// Assert
+			if testCase.expected == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), testCase.expected) {
+				t.Errorf("expected error containing %q, got %v", testCase.expected, err)
+			}
+		})
+	}
+}
+
+func TestRenderDSL(t *testing.T) {
+	// Act
+	dsl, err := RenderDSL(jsonModel)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{"schema 1.1", "type user", "type document", "define reader: [user]"} {
+		if !strings.Contains(dsl, expected) {
+			t.Errorf("expected rendered model to contain %s, got %s", expected, dsl)
+		}
+	}
+}
+
+func TestIsSameModel(t *testing.T) {
+	const dsl = `model
+  schema 1.1
+
+type user
+
+type document
+  relations
+    define reader: [user]
+`
+	testCases := []struct {
+		description string
+		dsl         string
+		expected    bool
+	}{
+		{
+			description: "formatting and comments changed",
+			dsl:         "# documents\nmodel\n  schema 1.1\ntype user\ntype document\n  relations\n    define reader: [user]\n",
+			expected:    true,
+		},
+		{
+			description: "relation added",
+			dsl:         dsl + "    define writer: [user]\n",
+		},
+		{
+			description: "doesn't compile",
+			dsl:         "model",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Act
+			same := IsSameModel(dsl, testCase.dsl)
+
+			// Assert
+			if same != testCase.expected {
+				t.Errorf("expected same model %v, got %v", testCase.expected, same)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeJSONModel(t *testing.T) {
+	dsl := "model\n  schema 1.1\n\ntype user\n\ntype document\n  relations\n    define reader: [user with public]\n\ncondition public(visibility: string) {\n  visibility == \"public\"\n}\n"
+	compiled, err := transformer.TransformDSLToJSON(dsl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A model read from OpenFGA has an ID, and is serialized by the SDK.
+	var read openfga.AuthorizationModel
+	if err := json.Unmarshal([]byte(compiled), &read); err != nil {
+		t.Fatal(err)
+	}
+	read.Id = "01HVMMBCMGZNT3SED4Z17ECXCA"
+	readJson, err := json.Marshal(read)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		description string
+		model       string
+		expected    bool
+	}{
+		{description: "model read from OpenFGA", model: string(readJson), expected: true},
+		{description: "reordered type definitions", model: `{"schema_version": "1.1", "conditions": ` + conditionsOf(t, compiled) + `, "type_definitions": [` + typeOf(t, compiled, 1) + `,` + typeOf(t, compiled, 0) + `]}`, expected: true},
+		{description: "other model", model: jsonModel, expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Act
+			expected, err := CanonicalizeJSONModel(compiled)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := CanonicalizeJSONModel(testCase.model)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (expected == actual) != testCase.expected {
+				t.Errorf("expected same canonical model %v", testCase.expected)
+			}
+		})
+	}
+}
+
+func typeOf(t *testing.T, model string, i int) string {
+	var parsed struct {
+		TypeDefinitions []json.RawMessage `json:"type_definitions"`
+	}
+	if err := json.Unmarshal([]byte(model), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	return string(parsed.TypeDefinitions[i])
+}
+
+func conditionsOf(t *testing.T, model string) string {
+	var parsed struct {
+		Conditions json.RawMessage `json:"conditions"`
+	}
+	if err := json.Unmarshal([]byte(model), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	return string(parsed.Conditions)
+}
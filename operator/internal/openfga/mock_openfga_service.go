@@ -10,6 +10,7 @@ import (
 
 	logr "github.com/go-logr/logr"
 	gomock "github.com/golang/mock/gomock"
+	transformer "github.com/openfga/language/pkg/go/transformer"
 )
 
 // MockPermissionServiceFactory is a mock of PermissionServiceFactory interface.
@@ -73,21 +74,6 @@ func (m *MockPermissionService) EXPECT() *MockPermissionServiceMockRecorder {
 	return m.recorder
 }
 
-// CheckAuthorizationModelExists mocks base method.
-func (m *MockPermissionService) CheckAuthorizationModelExists(ctx context.Context, authorizationModelId string) (bool, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckAuthorizationModelExists", ctx, authorizationModelId)
-	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// CheckAuthorizationModelExists indicates an expected call of CheckAuthorizationModelExists.
-func (mr *MockPermissionServiceMockRecorder) CheckAuthorizationModelExists(ctx, authorizationModelId interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckAuthorizationModelExists", reflect.TypeOf((*MockPermissionService)(nil).CheckAuthorizationModelExists), ctx, authorizationModelId)
-}
-
 // CheckExistingStoresById mocks base method.
 func (m *MockPermissionService) CheckExistingStoresById(ctx context.Context, storeId string) (*Store, error) {
 	m.ctrl.T.Helper()
@@ -118,44 +104,216 @@ func (mr *MockPermissionServiceMockRecorder) CheckExistingStoresByName(ctx, stor
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckExistingStoresByName", reflect.TypeOf((*MockPermissionService)(nil).CheckExistingStoresByName), ctx, storeName)
 }
 
+// CreateStore mocks base method.
+func (m *MockPermissionService) CreateStore(ctx context.Context, storeName string, log *logr.Logger) (*Store, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStore", ctx, storeName, log)
+	ret0, _ := ret[0].(*Store)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStore indicates an expected call of CreateStore.
+func (mr *MockPermissionServiceMockRecorder) CreateStore(ctx, storeName, log interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStore", reflect.TypeOf((*MockPermissionService)(nil).CreateStore), ctx, storeName, log)
+}
+
+// DeleteStore mocks base method.
+func (m *MockPermissionService) DeleteStore(ctx context.Context, storeId string, log *logr.Logger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStore", ctx, storeId, log)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStore indicates an expected call of DeleteStore.
+func (mr *MockPermissionServiceMockRecorder) DeleteStore(ctx, storeId, log interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStore", reflect.TypeOf((*MockPermissionService)(nil).DeleteStore), ctx, storeId, log)
+}
+
+// ForStore mocks base method.
+func (m *MockPermissionService) ForStore(storeId string) (StoreService, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForStore", storeId)
+	ret0, _ := ret[0].(StoreService)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForStore indicates an expected call of ForStore.
+func (mr *MockPermissionServiceMockRecorder) ForStore(storeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForStore", reflect.TypeOf((*MockPermissionService)(nil).ForStore), storeId)
+}
+
+// MockStoreService is a mock of StoreService interface.
+type MockStoreService struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoreServiceMockRecorder
+}
+
+// MockStoreServiceMockRecorder is the mock recorder for MockStoreService.
+type MockStoreServiceMockRecorder struct {
+	mock *MockStoreService
+}
+
+// NewMockStoreService creates a new mock instance.
+func NewMockStoreService(ctrl *gomock.Controller) *MockStoreService {
+	mock := &MockStoreService{ctrl: ctrl}
+	mock.recorder = &MockStoreServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStoreService) EXPECT() *MockStoreServiceMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockStoreService) Check(ctx context.Context, authorizationModelId string, tuple Tuple, contextualTuples []Tuple) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, authorizationModelId, tuple, contextualTuples)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockStoreServiceMockRecorder) Check(ctx, authorizationModelId, tuple, contextualTuples interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockStoreService)(nil).Check), ctx, authorizationModelId, tuple, contextualTuples)
+}
+
+// CheckAuthorizationModelExists mocks base method.
+func (m *MockStoreService) CheckAuthorizationModelExists(ctx context.Context, authorizationModelId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckAuthorizationModelExists", ctx, authorizationModelId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckAuthorizationModelExists indicates an expected call of CheckAuthorizationModelExists.
+func (mr *MockStoreServiceMockRecorder) CheckAuthorizationModelExists(ctx, authorizationModelId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckAuthorizationModelExists", reflect.TypeOf((*MockStoreService)(nil).CheckAuthorizationModelExists), ctx, authorizationModelId)
+}
+
 // CreateAuthorizationModel mocks base method.
-func (m *MockPermissionService) CreateAuthorizationModel(ctx context.Context, authorizationModel string, log *logr.Logger) (string, error) {
+func (m *MockStoreService) CreateAuthorizationModel(ctx context.Context, authorizationModel string, log *logr.Logger) (*AuthorizationModel, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateAuthorizationModel", ctx, authorizationModel, log)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(*AuthorizationModel)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateAuthorizationModel indicates an expected call of CreateAuthorizationModel.
-func (mr *MockPermissionServiceMockRecorder) CreateAuthorizationModel(ctx, authorizationModel, log interface{}) *gomock.Call {
+func (mr *MockStoreServiceMockRecorder) CreateAuthorizationModel(ctx, authorizationModel, log interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuthorizationModel", reflect.TypeOf((*MockPermissionService)(nil).CreateAuthorizationModel), ctx, authorizationModel, log)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuthorizationModel", reflect.TypeOf((*MockStoreService)(nil).CreateAuthorizationModel), ctx, authorizationModel, log)
 }
 
-// CreateStore mocks base method.
-func (m *MockPermissionService) CreateStore(ctx context.Context, storeName string, log *logr.Logger) (*Store, error) {
+// CreateJSONAuthorizationModel mocks base method.
+func (m *MockStoreService) CreateJSONAuthorizationModel(ctx context.Context, authorizationModel string, log *logr.Logger) (*AuthorizationModel, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateStore", ctx, storeName, log)
-	ret0, _ := ret[0].(*Store)
+	ret := m.ctrl.Call(m, "CreateJSONAuthorizationModel", ctx, authorizationModel, log)
+	ret0, _ := ret[0].(*AuthorizationModel)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateStore indicates an expected call of CreateStore.
-func (mr *MockPermissionServiceMockRecorder) CreateStore(ctx, storeName, log interface{}) *gomock.Call {
+// CreateJSONAuthorizationModel indicates an expected call of CreateJSONAuthorizationModel.
+func (mr *MockStoreServiceMockRecorder) CreateJSONAuthorizationModel(ctx, authorizationModel, log interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStore", reflect.TypeOf((*MockPermissionService)(nil).CreateStore), ctx, storeName, log)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJSONAuthorizationModel", reflect.TypeOf((*MockStoreService)(nil).CreateJSONAuthorizationModel), ctx, authorizationModel, log)
+}
+
+// CreateModularAuthorizationModel mocks base method.
+func (m *MockStoreService) CreateModularAuthorizationModel(ctx context.Context, manifest string, modules []transformer.ModuleFile, log *logr.Logger) (*AuthorizationModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateModularAuthorizationModel", ctx, manifest, modules, log)
+	ret0, _ := ret[0].(*AuthorizationModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateModularAuthorizationModel indicates an expected call of CreateModularAuthorizationModel.
+func (mr *MockStoreServiceMockRecorder) CreateModularAuthorizationModel(ctx, manifest, modules, log interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateModularAuthorizationModel", reflect.TypeOf((*MockStoreService)(nil).CreateModularAuthorizationModel), ctx, manifest, modules, log)
+}
+
+// DeleteTuples mocks base method.
+func (m *MockStoreService) DeleteTuples(ctx context.Context, tuples []Tuple, log *logr.Logger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTuples", ctx, tuples, log)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTuples indicates an expected call of DeleteTuples.
+func (mr *MockStoreServiceMockRecorder) DeleteTuples(ctx, tuples, log interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTuples", reflect.TypeOf((*MockStoreService)(nil).DeleteTuples), ctx, tuples, log)
+}
+
+// ListObjects mocks base method.
+func (m *MockStoreService) ListObjects(ctx context.Context, authorizationModelId, user, relation, objectType string, contextualTuples []Tuple) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListObjects", ctx, authorizationModelId, user, relation, objectType, contextualTuples)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListObjects indicates an expected call of ListObjects.
+func (mr *MockStoreServiceMockRecorder) ListObjects(ctx, authorizationModelId, user, relation, objectType, contextualTuples interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListObjects", reflect.TypeOf((*MockStoreService)(nil).ListObjects), ctx, authorizationModelId, user, relation, objectType, contextualTuples)
+}
+
+// Read mocks base method.
+func (m *MockStoreService) Read(ctx context.Context, filter Tuple) ([]Tuple, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", ctx, filter)
+	ret0, _ := ret[0].([]Tuple)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockStoreServiceMockRecorder) Read(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockStoreService)(nil).Read), ctx, filter)
+}
+
+// WriteAssertions mocks base method.
+func (m *MockStoreService) WriteAssertions(ctx context.Context, authorizationModelId string, assertions []Assertion, log *logr.Logger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteAssertions", ctx, authorizationModelId, assertions, log)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteAssertions indicates an expected call of WriteAssertions.
+func (mr *MockStoreServiceMockRecorder) WriteAssertions(ctx, authorizationModelId, assertions, log interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAssertions", reflect.TypeOf((*MockStoreService)(nil).WriteAssertions), ctx, authorizationModelId, assertions, log)
 }
 
-// SetStoreId mocks base method.
-func (m *MockPermissionService) SetStoreId(storeId string) {
+// WriteTuples mocks base method.
+func (m *MockStoreService) WriteTuples(ctx context.Context, tuples []Tuple, log *logr.Logger) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SetStoreId", storeId)
+	ret := m.ctrl.Call(m, "WriteTuples", ctx, tuples, log)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// SetStoreId indicates an expected call of SetStoreId.
-func (mr *MockPermissionServiceMockRecorder) SetStoreId(storeId interface{}) *gomock.Call {
+// WriteTuples indicates an expected call of WriteTuples.
+func (mr *MockStoreServiceMockRecorder) WriteTuples(ctx, tuples, log interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStoreId", reflect.TypeOf((*MockPermissionService)(nil).SetStoreId), storeId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteTuples", reflect.TypeOf((*MockStoreService)(nil).WriteTuples), ctx, tuples, log)
 }
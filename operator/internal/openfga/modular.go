@@ -0,0 +1,85 @@
+package openfga
+
+import (
+	"errors"
+	"fmt"
+	"github.com/openfga/language/pkg/go/transformer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"strings"
+)
+
+// CompileModularModel combines the module files listed in the contents of the fga.mod manifest into a
+// single authorization model, and returns it in the json syntax accepted by the OpenFGA API. Errors in
+// module files are reported with the name of the file, line and column.
+func CompileModularModel(manifest string, modules []transformer.ModuleFile) (string, error) {
+	modFile, err := transformer.TransformModFile(manifest)
+	if err != nil {
+		return "", fmt.Errorf("fga.mod: %w", err)
+	}
+
+	provided := make(map[string]struct{}, len(modules))
+	for _, module := range modules {
+		provided[module.Name] = struct{}{}
+	}
+	listed := make(map[string]struct{}, len(modFile.Contents.Value))
+	var files []transformer.ModuleFile
+	for _, content := range modFile.Contents.Value {
+		listed[content.Value] = struct{}{}
+		if _, ok := provided[content.Value]; !ok {
+			return "", fmt.Errorf("fga.mod: module file %s is listed in contents but not provided", content.Value)
+		}
+	}
+	for _, module := range modules {
+		if _, ok := listed[module.Name]; !ok {
+			return "", fmt.Errorf("module file %s is not listed in the contents of fga.mod", module.Name)
+		}
+		files = append(files, module)
+	}
+
+	model, err := transformer.TransformModuleFilesToModel(files, modFile.Schema.Value)
+	if err != nil {
+		return "", moduleErrors(err)
+	}
+
+	bytes, err := protojson.Marshal(model)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal modular model: %w", err)
+	}
+	return string(bytes), nil
+}
+
+// IsSameModularModel returns true when two modular authorization models compile to the same model.
+func IsSameModularModel(
+	existingManifest string,
+	existingModules []transformer.ModuleFile,
+	manifest string,
+	modules []transformer.ModuleFile) bool {
+	existingCompiled, err := CompileModularModel(existingManifest, existingModules)
+	if err != nil {
+		return false
+	}
+	compiled, err := CompileModularModel(manifest, modules)
+	if err != nil {
+		return false
+	}
+	return existingCompiled == compiled
+}
+
+// moduleErrors lists the errors of all module files, prefixed by the name of the file.
+func moduleErrors(err error) error {
+	var multipleError *transformer.ModuleValidationMultipleError
+	if !errors.As(err, &multipleError) {
+		return err
+	}
+	messages := make([]string, 0, len(multipleError.Errors))
+	for _, moduleErr := range multipleError.Errors {
+		var singleError *transformer.ModuleTransformationSingleError
+		if errors.As(moduleErr, &singleError) && singleError.File != "" {
+			messages = append(messages, fmt.Sprintf("%s:%d:%d: %s",
+				singleError.File, singleError.Line.Start, singleError.Column.Start, singleError.Msg))
+			continue
+		}
+		messages = append(messages, moduleErr.Error())
+	}
+	return fmt.Errorf("invalid modular model: %s", strings.Join(messages, "; "))
+}
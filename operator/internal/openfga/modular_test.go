@@ -0,0 +1,132 @@
+package openfga
+
+import (
+	"github.com/openfga/language/pkg/go/transformer"
+	"strings"
+	"testing"
+)
+
+const manifest = `schema: '1.2'
+contents:
+  - core.fga
+  - issues.fga
+`
+
+const coreModule = `module core
+
+type user
+
+type organization
+  relations
+    define member: [user]
+`
+
+const issuesModule = `module issues
+
+extend type organization
+  relations
+    define admin: [user]
+
+type issue
+  relations
+    define owner: [organization#member]
+`
+
+func TestCompileModularModel(t *testing.T) {
+	// Act
+	compiled, err := CompileModularModel(manifest, []transformer.ModuleFile{
+		{Name: "core.fga", Contents: coreModule},
+		{Name: "issues.fga", Contents: issuesModule},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{`"schema_version":"1.2"`, `"type":"organization"`, `"admin"`, `"type":"issue"`} {
+		if !strings.Contains(compiled, expected) {
+			t.Errorf("expected compiled model to contain %s, got %s", expected, compiled)
+		}
+	}
+}
+
+func TestIsSameModularModel(t *testing.T) {
+	// Arrange
+	modules := []transformer.ModuleFile{
+		{Name: "core.fga", Contents: coreModule},
+		{Name: "issues.fga", Contents: issuesModule},
+	}
+	reformatted := []transformer.ModuleFile{
+		{Name: "core.fga", Contents: "# core types\n" + coreModule},
+		{Name: "issues.fga", Contents: issuesModule},
+	}
+	changed := []transformer.ModuleFile{
+		{Name: "core.fga", Contents: coreModule + "    define owner: [user]\n"},
+		{Name: "issues.fga", Contents: issuesModule},
+	}
+
+	// Act
+	sameReformatted := IsSameModularModel(manifest, modules, manifest, reformatted)
+	sameChanged := IsSameModularModel(manifest, modules, manifest, changed)
+
+	// Assert
+	if !sameReformatted {
+		t.Errorf("expected reformatted modules to be the same model")
+	}
+	if sameChanged {
+		t.Errorf("expected changed modules to be another model")
+	}
+}
+
+func TestCompileModularModelErrors(t *testing.T) {
+	testCases := []struct {
+		description string
+		manifest    string
+		modules     []transformer.ModuleFile
+		expected    string
+	}{
+		{
+			description: "missing module file",
+			manifest:    manifest,
+			modules:     []transformer.ModuleFile{{Name: "core.fga", Contents: coreModule}},
+			expected:    "fga.mod: module file issues.fga is listed in contents but not provided",
+		},
+		{
+			description: "module file not in manifest",
+			manifest:    manifest,
+			modules: []transformer.ModuleFile{
+				{Name: "core.fga", Contents: coreModule},
+				{Name: "issues.fga", Contents: issuesModule},
+				{Name: "other.fga", Contents: coreModule},
+			},
+			expected: "module file other.fga is not listed in the contents of fga.mod",
+		},
+		{
+			description: "invalid manifest",
+			manifest:    "contents: core.fga",
+			modules:     []transformer.ModuleFile{{Name: "core.fga", Contents: coreModule}},
+			expected:    "fga.mod:",
+		},
+		{
+			description: "error in module file",
+			manifest:    manifest,
+			modules: []transformer.ModuleFile{
+				{Name: "core.fga", Contents: coreModule},
+				{Name: "issues.fga", Contents: strings.Replace(issuesModule, "extend type organization", "extend type team", 1)},
+			},
+			expected: "issues.fga:",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Act
+			_, err := CompileModularModel(testCase.manifest, testCase.modules)
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), testCase.expected) {
+				t.Errorf("expected error containing %q, got %v", testCase.expected, err)
+			}
+		})
+	}
+}
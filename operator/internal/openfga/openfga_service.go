@@ -3,25 +3,43 @@ package openfga
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/go-logr/logr"
 	openfga "github.com/openfga/go-sdk"
 	ofgaClient "github.com/openfga/go-sdk/client"
-	"github.com/openfga/go-sdk/credentials"
 	"github.com/openfga/language/pkg/go/transformer"
+	"regexp"
 	"time"
 )
 
+// PermissionServiceFactory returns the permission service of an OpenFGA server.
 type PermissionServiceFactory interface {
 	GetService(config Config) (PermissionService, error)
 }
 
+// PermissionService manages the stores of an OpenFGA server. It's safe for concurrent use.
 type PermissionService interface {
-	SetStoreId(storeId string)
-	CreateAuthorizationModel(ctx context.Context, authorizationModel string, log *logr.Logger) (string, error)
+	// ForStore returns a service for the authorization models of the store.
+	ForStore(storeId string) (StoreService, error)
 	CheckExistingStoresByName(ctx context.Context, storeName string) (*Store, error)
 	CheckExistingStoresById(ctx context.Context, storeId string) (*Store, error)
 	CreateStore(ctx context.Context, storeName string, log *logr.Logger) (*Store, error)
+	DeleteStore(ctx context.Context, storeId string, log *logr.Logger) error
+}
+
+// StoreService manages the authorization models and tuples of a single store. It's safe for concurrent use.
+type StoreService interface {
+	CreateAuthorizationModel(ctx context.Context, authorizationModel string, log *logr.Logger) (*AuthorizationModel, error)
+	CreateJSONAuthorizationModel(ctx context.Context, authorizationModel string, log *logr.Logger) (*AuthorizationModel, error)
+	CreateModularAuthorizationModel(ctx context.Context, manifest string, modules []transformer.ModuleFile, log *logr.Logger) (*AuthorizationModel, error)
 	CheckAuthorizationModelExists(ctx context.Context, authorizationModelId string) (bool, error)
+	WriteTuples(ctx context.Context, tuples []Tuple, log *logr.Logger) error
+	DeleteTuples(ctx context.Context, tuples []Tuple, log *logr.Logger) error
+	Read(ctx context.Context, filter Tuple) ([]Tuple, error)
+	Check(ctx context.Context, authorizationModelId string, tuple Tuple, contextualTuples []Tuple) (bool, error)
+	ListObjects(ctx context.Context, authorizationModelId string, user, relation, objectType string, contextualTuples []Tuple) ([]string, error)
+	WriteAssertions(ctx context.Context, authorizationModelId string, assertions []Assertion, log *logr.Logger) error
 }
 
 type Store struct {
@@ -30,58 +48,107 @@ type Store struct {
 	CreatedAt time.Time
 }
 
-type OpenFgaServiceFactory struct{}
+// AuthorizationModel is an authorization model written to OpenFGA.
+type AuthorizationModel struct {
+	Id string
 
-func (_ OpenFgaServiceFactory) GetService(config Config) (PermissionService, error) {
-	return newOpenFgaService(config)
+	// Adopted is true when an identical authorization model already existed in the store, and its ID is
+	// reused instead of writing a new authorization model.
+	Adopted bool
 }
 
 type OpenFgaService struct {
-	client ofgaClient.OpenFgaClient
+	clients *connectionClients
 }
 
+// newOpenFgaService creates a service with its own clients, which aren't pooled.
 func newOpenFgaService(config Config) (PermissionService, error) {
-	client, err := ofgaClient.NewSdkClient(&ofgaClient.ClientConfiguration{
-		ApiUrl: config.ApiUrl,
-		Credentials: &credentials.Credentials{
-			Method: credentials.CredentialsMethodApiToken,
-			Config: &credentials.Config{
-				ApiToken: config.ApiToken,
-			},
-		},
-	})
+	clients, err := newConnectionClients(config)
 	if err != nil {
 		return &OpenFgaService{}, err
 	}
-	return &OpenFgaService{
-		*client,
-	}, nil
+	return &OpenFgaService{clients: clients}, nil
+}
+
+func (s *OpenFgaService) ForStore(storeId string) (StoreService, error) {
+	client, err := s.clients.forStore(storeId)
+	if err != nil {
+		return nil, err
+	}
+	return &OpenFgaStoreService{client: client, clients: s.clients}, nil
 }
 
-func (s *OpenFgaService) SetStoreId(storeId string) {
-	s.client.SetStoreId(storeId)
+// OpenFgaStoreService is a service for a single store, of which the SDK client is never changed.
+type OpenFgaStoreService struct {
+	client  *ofgaClient.OpenFgaClient
+	clients *connectionClients
 }
 
+// CheckExistingStoresByName returns the store with the name, or nil when there is none. A store found
+// before is read by its ID, and otherwise the stores are listed.
 func (s *OpenFgaService) CheckExistingStoresByName(ctx context.Context, storeName string) (*Store, error) {
-	return s.checkExistingStores(ctx, storeName, "")
+	if storeId, ok := s.clients.storeNames.get(storeName); ok {
+		store, err := s.CheckExistingStoresById(ctx, storeId)
+		if err != nil {
+			return nil, err
+		}
+		if store != nil && store.Name == storeName {
+			return store, nil
+		}
+		s.clients.storeNames.remove(storeId)
+	}
+	return s.listStores(ctx, storeName)
 }
 
+// CheckExistingStoresById returns the store with the ID, or nil when there is none.
 func (s *OpenFgaService) CheckExistingStoresById(ctx context.Context, storeId string) (*Store, error) {
-	return s.checkExistingStores(ctx, "", storeId)
+	if !isUlid(storeId) {
+		return nil, nil
+	}
+	client, err := s.clients.forStore(storeId)
+	if err != nil {
+		return nil, err
+	}
+	var store *ofgaClient.ClientGetStoreResponse
+	err = s.clients.retrier.call(ctx, true, func(ctx context.Context) (err error) {
+		store, err = client.GetStore(ctx).Execute()
+		return err
+	})
+	if isNotFound(err) {
+		s.clients.removeStore(storeId)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Store{
+		Id:        store.Id,
+		Name:      store.Name,
+		CreatedAt: store.CreatedAt,
+	}, nil
 }
 
-func (s *OpenFgaService) checkExistingStores(ctx context.Context, storeName, storeId string) (*Store, error) {
-	pageSize := openfga.PtrInt32(10)
+// listStores lists the stores until one with the name is found, and adds the listed stores to the index.
+func (s *OpenFgaService) listStores(ctx context.Context, storeName string) (*Store, error) {
+	client, err := s.clients.forStore("")
+	if err != nil {
+		return nil, err
+	}
 	options := ofgaClient.ClientListStoresOptions{
-		PageSize: pageSize,
+		PageSize: s.clients.config.Requests.pageSize(),
 	}
 	for {
-		stores, err := s.client.ListStores(ctx).Options(options).Execute()
+		var stores *ofgaClient.ClientListStoresResponse
+		err := s.clients.retrier.call(ctx, true, func(ctx context.Context) (err error) {
+			stores, err = client.ListStores(ctx).Options(options).Execute()
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
 		for _, oldStore := range stores.Stores {
-			if storeName != "" && oldStore.Name == storeName || storeId != "" && oldStore.Id == storeId {
+			s.clients.storeNames.add(oldStore.Name, oldStore.Id)
+			if oldStore.Name == storeName {
 				return &Store{
 					Id:        oldStore.Id,
 					Name:      oldStore.Name,
@@ -93,45 +160,49 @@ func (s *OpenFgaService) checkExistingStores(ctx context.Context, storeName, sto
 			break
 		}
 		options = ofgaClient.ClientListStoresOptions{
-			PageSize:          pageSize,
+			PageSize:          s.clients.config.Requests.pageSize(),
 			ContinuationToken: openfga.PtrString(stores.ContinuationToken),
 		}
 	}
 	return nil, nil
 }
 
-func (s *OpenFgaService) CheckAuthorizationModelExists(ctx context.Context, authorizationModelId string) (bool, error) {
-	pageSize := openfga.PtrInt32(10)
-	options := ofgaClient.ClientReadAuthorizationModelsOptions{
-		PageSize: pageSize,
+// CheckAuthorizationModelExists reads the authorization model by its ID.
+func (s *OpenFgaStoreService) CheckAuthorizationModelExists(ctx context.Context, authorizationModelId string) (bool, error) {
+	if !isUlid(authorizationModelId) {
+		return false, nil
 	}
-	for {
-		authModels, err := s.client.ReadAuthorizationModels(ctx).Options(options).Execute()
-		if err != nil {
-			return false, err
-		}
-		for _, authModel := range authModels.AuthorizationModels {
-			if authModel.Id == authorizationModelId {
-				return true, nil
-			}
-		}
-		if authModels.ContinuationToken == nil || *authModels.ContinuationToken == "" {
-			break
-		}
-		options = ofgaClient.ClientReadAuthorizationModelsOptions{
-			PageSize:          pageSize,
-			ContinuationToken: authModels.ContinuationToken,
-		}
+	options := ofgaClient.ClientReadAuthorizationModelOptions{
+		AuthorizationModelId: openfga.PtrString(authorizationModelId),
+	}
+	err := s.clients.retrier.call(ctx, true, func(ctx context.Context) error {
+		_, err := s.client.ReadAuthorizationModel(ctx).Options(options).Execute()
+		return err
+	})
+	if isNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
 	}
-	return false, nil
+	return true, nil
 }
 
 func (s *OpenFgaService) CreateStore(ctx context.Context, storeName string, log *logr.Logger) (*Store, error) {
+	client, err := s.clients.forStore("")
+	if err != nil {
+		return nil, err
+	}
 	body := ofgaClient.ClientCreateStoreRequest{Name: storeName}
-	store, err := s.client.CreateStore(ctx).Body(body).Execute()
+	var store *ofgaClient.ClientCreateStoreResponse
+	err = s.clients.retrier.call(ctx, false, func(ctx context.Context) (err error) {
+		store, err = client.CreateStore(ctx).Body(body).Execute()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	s.clients.storeNames.add(store.Name, store.Id)
 	log.V(0).Info("Created store in OpenFGA", "storeOpenFGA", store)
 	return &Store{
 		Id:        store.Id,
@@ -140,23 +211,156 @@ func (s *OpenFgaService) CreateStore(ctx context.Context, storeName string, log
 	}, nil
 }
 
-func (s *OpenFgaService) CreateAuthorizationModel(
+// DeleteStore deletes the store from OpenFGA. A store which doesn't exist is considered deleted.
+func (s *OpenFgaService) DeleteStore(ctx context.Context, storeId string, log *logr.Logger) error {
+	client, err := s.clients.forStore(storeId)
+	if err != nil {
+		return err
+	}
+	err = s.clients.retrier.call(ctx, true, func(ctx context.Context) error {
+		_, err := client.DeleteStore(ctx).Execute()
+		return err
+	})
+	s.clients.removeStore(storeId)
+	s.clients.storeNames.remove(storeId)
+	if isNotFound(err) {
+		log.V(0).Info("Store to delete does not exist in OpenFGA", "storeId", storeId)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.V(0).Info("Deleted store in OpenFGA", "storeId", storeId)
+	return nil
+}
+
+func (s *OpenFgaStoreService) CreateAuthorizationModel(
 	ctx context.Context,
 	authorizationModel string,
-	log *logr.Logger) (string, error) {
+	log *logr.Logger) (*AuthorizationModel, error) {
 
 	generatedJsonString, err := transformer.TransformDSLToJSON(authorizationModel)
 	if err != nil {
-		return "", err
+		return nil, Permanent(err)
+	}
+	return s.writeAuthorizationModel(ctx, generatedJsonString, log)
+}
+
+// CreateJSONAuthorizationModel creates an authorization model in the json syntax of the OpenFGA API.
+func (s *OpenFgaStoreService) CreateJSONAuthorizationModel(
+	ctx context.Context,
+	authorizationModel string,
+	log *logr.Logger) (*AuthorizationModel, error) {
+
+	if err := ValidateJSONModel(authorizationModel); err != nil {
+		return nil, Permanent(err)
 	}
+	return s.writeAuthorizationModel(ctx, authorizationModel, log)
+}
+
+// CreateModularAuthorizationModel combines the module files into a single authorization model, and
+// creates it in OpenFGA.
+func (s *OpenFgaStoreService) CreateModularAuthorizationModel(
+	ctx context.Context,
+	manifest string,
+	modules []transformer.ModuleFile,
+	log *logr.Logger) (*AuthorizationModel, error) {
+
+	generatedJsonString, err := CompileModularModel(manifest, modules)
+	if err != nil {
+		return nil, Permanent(err)
+	}
+	return s.writeAuthorizationModel(ctx, generatedJsonString, log)
+}
+
+// writeAuthorizationModel writes the authorization model to OpenFGA, unless an identical authorization
+// model already exists in the store, in which case that one is adopted.
+func (s *OpenFgaStoreService) writeAuthorizationModel(ctx context.Context, generatedJsonString string, log *logr.Logger) (*AuthorizationModel, error) {
 	var body ofgaClient.ClientWriteAuthorizationModelRequest
 	if err := json.Unmarshal([]byte(generatedJsonString), &body); err != nil {
+		return nil, Permanent(fmt.Errorf("invalid json authorization model: %w", err))
 	}
-	data, err := s.client.WriteAuthorizationModel(ctx).Body(body).Execute()
+
+	existingId, err := s.findAuthorizationModel(ctx, generatedJsonString)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to read existing authorization models: %w", err)
+	}
+	if existingId != "" {
+		log.V(0).Info("Adopted identical authorization model in OpenFGA", "authorizationModelId", existingId)
+		return &AuthorizationModel{Id: existingId, Adopted: true}, nil
+	}
+
+	// A retried write may write the model twice, which is harmless as the models are identical.
+	var data *ofgaClient.ClientWriteAuthorizationModelResponse
+	err = s.clients.retrier.call(ctx, true, func(ctx context.Context) (err error) {
+		data, err = s.client.WriteAuthorizationModel(ctx).Body(body).Execute()
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 	log.V(0).Info("Created authorization model in OpenFGA", "authorizationModelBody", body, "authorizationModelData", data)
 
-	return data.AuthorizationModelId, nil
+	return &AuthorizationModel{Id: data.AuthorizationModelId}, nil
+}
+
+// findAuthorizationModel returns the ID of the latest authorization model in the store which is identical
+// to the given one, or an empty string when there is none.
+func (s *OpenFgaStoreService) findAuthorizationModel(ctx context.Context, generatedJsonString string) (string, error) {
+	canonical, err := CanonicalizeJSONModel(generatedJsonString)
+	if err != nil {
+		return "", Permanent(err)
+	}
+	options := ofgaClient.ClientReadAuthorizationModelsOptions{
+		PageSize: s.clients.config.Requests.pageSize(),
+	}
+	for {
+		var authModels *ofgaClient.ClientReadAuthorizationModelsResponse
+		err := s.clients.retrier.call(ctx, true, func(ctx context.Context) (err error) {
+			authModels, err = s.client.ReadAuthorizationModels(ctx).Options(options).Execute()
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+		for _, authModel := range authModels.AuthorizationModels {
+			existing, err := json.Marshal(authModel)
+			if err != nil {
+				return "", err
+			}
+			existingCanonical, err := CanonicalizeJSONModel(string(existing))
+			if err != nil {
+				continue
+			}
+			if existingCanonical == canonical {
+				return authModel.Id, nil
+			}
+		}
+		if authModels.ContinuationToken == nil || *authModels.ContinuationToken == "" {
+			break
+		}
+		options = ofgaClient.ClientReadAuthorizationModelsOptions{
+			PageSize:          s.clients.config.Requests.pageSize(),
+			ContinuationToken: authModels.ContinuationToken,
+		}
+	}
+	return "", nil
+}
+
+var ulidPattern = regexp.MustCompile("^[0-7][0-9A-HJKMNP-TV-Z]{25}$")
+
+// isUlid returns true for a well-formed ULID. Stores and authorization models with other IDs can't exist,
+// and the SDK rejects them.
+func isUlid(id string) bool {
+	return ulidPattern.MatchString(id)
+}
+
+// isNotFound returns true when OpenFGA responded that the store or authorization model doesn't exist.
+func isNotFound(err error) bool {
+	var notFoundError openfga.FgaApiNotFoundError
+	if errors.As(err, &notFoundError) {
+		return true
+	}
+	var validationError openfga.FgaApiValidationError
+	return errors.As(err, &validationError) && validationError.ResponseCode() == openfga.AUTHORIZATION_MODEL_NOT_FOUND
 }
@@ -127,6 +127,31 @@ func TestNegativeCheckExistingStoresByNameIntegration(t *testing.T) {
 	}
 }
 
+func TestDeleteStoreIntegration(t *testing.T) {
+	// Arrange
+	setupIntegrationTest(t)
+	testStoreName := uuid.NewString()
+	createdStore, err := service.CreateStore(ctx, testStoreName, &logger)
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+
+	// Act
+	err = service.DeleteStore(ctx, createdStore.Id, &logger)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("failed to delete store: %v", err)
+	}
+	existingStore, err := service.CheckExistingStoresById(ctx, createdStore.Id)
+	if err != nil {
+		t.Fatalf("failed to check existing stores: %v", err)
+	}
+	if existingStore != nil {
+		t.Fatalf("expected store %q to be deleted, but it exists", testStoreName)
+	}
+}
+
 func TestPositiveCheckAuthorizationModelExistsIntegration(t *testing.T) {
 	// Arrange
 	setupIntegrationTest(t)
@@ -135,14 +160,17 @@ func TestPositiveCheckAuthorizationModelExistsIntegration(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to seed store: %v", err)
 	}
-	service.SetStoreId(store.Id)
-	modelId, err := service.CreateAuthorizationModel(ctx, model, &logger)
+	storeService, err := service.ForStore(store.Id)
+	if err != nil {
+		t.Fatalf("failed to get store service: %v", err)
+	}
+	authModel, err := storeService.CreateAuthorizationModel(ctx, model, &logger)
 	if err != nil {
 		t.Fatalf("failed to create authorization model: %v", err)
 	}
 
 	// Act
-	modelExists, err := service.CheckAuthorizationModelExists(ctx, modelId)
+	modelExists, err := storeService.CheckAuthorizationModelExists(ctx, authModel.Id)
 
 	// Assert
 	if err != nil {
@@ -161,10 +189,13 @@ func TestNegativeCheckAuthorizationModelExistsIntegration(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to seed store: %v", err)
 	}
-	service.SetStoreId(store.Id)
+	storeService, err := service.ForStore(store.Id)
+	if err != nil {
+		t.Fatalf("failed to get store service: %v", err)
+	}
 
 	// Act
-	modelExists, err := service.CheckAuthorizationModelExists(ctx, uuid.NewString())
+	modelExists, err := storeService.CheckAuthorizationModelExists(ctx, uuid.NewString())
 
 	// Assert
 	if err != nil {
@@ -184,18 +215,54 @@ func TestCreateAuthorizationModelIntegration(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to seed store: %v", err)
 	}
-	service.SetStoreId(store.Id)
+	storeService, err := service.ForStore(store.Id)
+	if err != nil {
+		t.Fatalf("failed to get store service: %v", err)
+	}
 
 	// Act
-	modelID, err := service.CreateAuthorizationModel(ctx, model, &logger)
+	authModel, err := storeService.CreateAuthorizationModel(ctx, model, &logger)
 	if err != nil {
 		t.Fatalf("failed to create authorization model: %v", err)
 	}
 
 	// Assert
-	if modelID == "" {
+	if authModel.Id == "" {
 		t.Fatal("authorization model ID is empty")
 	}
+	if authModel.Adopted {
+		t.Fatal("expected authorization model to be created, but it was adopted")
+	}
+}
+
+func TestCreateAuthorizationModelIntegration_IdenticalModel(t *testing.T) {
+	setupIntegrationTest(t)
+
+	// Arrange
+	storeName := uuid.NewString()
+	store, err := service.CreateStore(ctx, storeName, &logger)
+	if err != nil {
+		t.Fatalf("failed to seed store: %v", err)
+	}
+	storeService, err := service.ForStore(store.Id)
+	if err != nil {
+		t.Fatalf("failed to get store service: %v", err)
+	}
+	created, err := storeService.CreateAuthorizationModel(ctx, model, &logger)
+	if err != nil {
+		t.Fatalf("failed to create authorization model: %v", err)
+	}
+
+	// Act
+	adopted, err := storeService.CreateAuthorizationModel(ctx, "# same model, other formatting\n"+model, &logger)
+	if err != nil {
+		t.Fatalf("failed to create authorization model: %v", err)
+	}
+
+	// Assert
+	if !adopted.Adopted || adopted.Id != created.Id {
+		t.Fatalf("expected authorization model %s to be adopted, got %+v", created.Id, adopted)
+	}
 }
 
 func TestCreateAuthorizationModelIntegration_BadModel(t *testing.T) {
@@ -207,11 +274,14 @@ func TestCreateAuthorizationModelIntegration_BadModel(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to seed store: %v", err)
 	}
-	service.SetStoreId(store.Id)
+	storeService, err := service.ForStore(store.Id)
+	if err != nil {
+		t.Fatalf("failed to get store service: %v", err)
+	}
 	authorizationModel := `{"bad": "authorization model"}`
 
 	// Act
-	_, err = service.CreateAuthorizationModel(ctx, authorizationModel, &logger)
+	_, err = storeService.CreateAuthorizationModel(ctx, authorizationModel, &logger)
 
 	// Assert
 	if err == nil {
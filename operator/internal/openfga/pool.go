@@ -0,0 +1,134 @@
+package openfga
+
+import (
+	"fmt"
+	"github.com/go-logr/logr"
+	ofgaClient "github.com/openfga/go-sdk/client"
+	"net/http"
+	"sync"
+)
+
+// OpenFgaServiceFactory is a thread-safe pool of clients keyed by the settings of a connection, such that
+// TCP connections and access tokens are reused across reconciliations. When the settings of a connection
+// change, e.g. because credentials were rotated, its clients are replaced.
+type OpenFgaServiceFactory struct {
+	log logr.Logger
+
+	mutex   sync.Mutex
+	clients map[Config]*connectionClients
+	// connections are the current settings of each connection, to detect changes.
+	connections map[string]Config
+}
+
+func NewOpenFgaServiceFactory(log logr.Logger) *OpenFgaServiceFactory {
+	return &OpenFgaServiceFactory{
+		log:         log,
+		clients:     make(map[Config]*connectionClients),
+		connections: make(map[string]Config),
+	}
+}
+
+func (f *OpenFgaServiceFactory) GetService(config Config) (PermissionService, error) {
+	clients, err := f.connectionClients(config)
+	if err != nil {
+		return nil, err
+	}
+	return &OpenFgaService{clients: clients}, nil
+}
+
+func (f *OpenFgaServiceFactory) connectionClients(config Config) (*connectionClients, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if previous, ok := f.connections[config.Connection]; ok && previous != config {
+		f.log.Info("Settings of OpenFGA connection changed, replacing its clients", "connection", connectionName(config.Connection))
+		if replaced, ok := f.clients[previous]; ok {
+			replaced.httpClient.CloseIdleConnections()
+			delete(f.clients, previous)
+		}
+	}
+	f.connections[config.Connection] = config
+
+	if clients, ok := f.clients[config]; ok {
+		return clients, nil
+	}
+	clients, err := newConnectionClients(config)
+	if err != nil {
+		return nil, err
+	}
+	f.clients[config] = clients
+	return clients, nil
+}
+
+func connectionName(connection string) string {
+	if connection == "" {
+		return "default"
+	}
+	return connection
+}
+
+// connectionClients are the SDK clients of a connection, which share an HTTP client. The SDK client holds
+// the store ID in its configuration, such that there is a client per store, which is never changed and
+// can be used concurrently. The index of store names and the rate limit are shared by the reconciliations
+// using the connection.
+type connectionClients struct {
+	config     Config
+	httpClient *http.Client
+	storeNames *storeIndex
+	retrier    *retrier
+
+	mutex  sync.Mutex
+	stores map[string]*ofgaClient.OpenFgaClient
+}
+
+func newConnectionClients(config Config) (*connectionClients, error) {
+	// The credentials are validated first, which normalizes the token issuer of the client credentials flow.
+	sdkCredentials := config.credentials()
+	if err := sdkCredentials.ValidateCredentialsConfig(); err != nil {
+		return nil, fmt.Errorf("invalid credentials: %w", err)
+	}
+	httpClient, err := config.httpClient(sdkCredentials)
+	if err != nil {
+		return nil, err
+	}
+	clients := &connectionClients{
+		config:     config,
+		httpClient: httpClient,
+		storeNames: newStoreIndex(config.Requests.StoreIndexTTL),
+		retrier:    newRetrier(config.Requests),
+		stores:     make(map[string]*ofgaClient.OpenFgaClient),
+	}
+	// The client without store is created right away, such that invalid settings fail early.
+	if _, err := clients.forStore(""); err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+// forStore returns the SDK client of the store, or of no store for an empty store ID.
+func (c *connectionClients) forStore(storeId string) (*ofgaClient.OpenFgaClient, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if client, ok := c.stores[storeId]; ok {
+		return client, nil
+	}
+	client, err := ofgaClient.NewSdkClient(&ofgaClient.ClientConfiguration{
+		ApiUrl:      c.config.ApiUrl,
+		StoreId:     storeId,
+		Credentials: c.config.credentials(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The SDK ignores a custom HTTP client in its configuration, so it's replaced after creation.
+	client.GetConfig().HTTPClient = c.httpClient
+	c.stores[storeId] = client
+	return client, nil
+}
+
+// removeStore removes the SDK client of a deleted store.
+func (c *connectionClients) removeStore(storeId string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.stores, storeId)
+}
@@ -0,0 +1,140 @@
+package openfga
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-logr/logr"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	storeIdA = "01HVMMBCMGZNT3SED4Z17ECXCA"
+	storeIdB = "01J5JKZ4B9Q4NEZ7E1KZ1Y0V9S"
+)
+
+func TestOpenFgaServiceFactory(t *testing.T) {
+	// Arrange
+	factory := NewOpenFgaServiceFactory(logr.Discard())
+	config := Config{Connection: "FGAConnection/default/eu-west-1", ApiUrl: "http://localhost:8089", ApiToken: "foobar"}
+	rotated := config
+	rotated.ApiToken = "rotated"
+	other := config
+	other.Connection = "ClusterFGAConnection/compliance"
+	clients := func(config Config) *connectionClients {
+		service, err := factory.GetService(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return service.(*OpenFgaService).clients
+	}
+
+	// Act
+	first := clients(config)
+	second := clients(config)
+	otherConnection := clients(other)
+	afterRotation := clients(rotated)
+
+	// Assert
+	if first != second {
+		t.Errorf("expected the clients of a connection to be reused")
+	}
+	if first == otherConnection {
+		t.Errorf("expected connections to have their own clients")
+	}
+	if first == afterRotation || first.httpClient == afterRotation.httpClient {
+		t.Errorf("expected new clients when the settings of a connection change")
+	}
+	if _, ok := factory.clients[config]; ok {
+		t.Errorf("expected the clients of the previous settings to be removed")
+	}
+	if len(factory.clients) != 2 {
+		t.Errorf("expected clients of 2 connections, got %d", len(factory.clients))
+	}
+}
+
+func TestForStore(t *testing.T) {
+	// Arrange
+	service, err := NewOpenFgaServiceFactory(logr.Discard()).GetService(Config{ApiUrl: "http://localhost:8089", AuthMode: AuthModeNone})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	storeA, errA := service.ForStore(storeIdA)
+	storeAAgain, errAAgain := service.ForStore(storeIdA)
+	storeB, errB := service.ForStore(storeIdB)
+	_, errInvalid := service.ForStore("invalid")
+
+	// Assert
+	for _, err := range []error{errA, errAAgain, errB} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	clientA := storeA.(*OpenFgaStoreService).client
+	if clientA != storeAAgain.(*OpenFgaStoreService).client {
+		t.Errorf("expected the client of a store to be reused")
+	}
+	if clientA.GetConfig().StoreId != storeIdA || storeB.(*OpenFgaStoreService).client.GetConfig().StoreId != storeIdB {
+		t.Errorf("expected a client per store")
+	}
+	if errInvalid == nil {
+		t.Errorf("expected an error for an invalid store ID")
+	}
+}
+
+func TestForStoreConcurrently(t *testing.T) {
+	// Arrange
+	var mutex sync.Mutex
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests[strings.Split(strings.TrimPrefix(r.URL.Path, "/stores/"), "/")[0]]++
+		mutex.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"authorization_models": []any{}})
+	}))
+	defer server.Close()
+	factory := NewOpenFgaServiceFactory(logr.Discard())
+	const calls = 20
+
+	// Act
+	var wait sync.WaitGroup
+	errs := make(chan error, 2*calls)
+	for i := 0; i < calls; i++ {
+		for _, storeId := range []string{storeIdA, storeIdB} {
+			wait.Add(1)
+			go func(storeId string) {
+				defer wait.Done()
+				service, err := factory.GetService(Config{ApiUrl: server.URL, AuthMode: AuthModeNone})
+				if err != nil {
+					errs <- err
+					return
+				}
+				storeService, err := service.ForStore(storeId)
+				if err != nil {
+					errs <- err
+					return
+				}
+				_, err = storeService.CheckAuthorizationModelExists(context.Background(), "01HVMMBCMGZNT3SED4Z17ECXCB")
+				errs <- err
+			}(storeId)
+		}
+	}
+	wait.Wait()
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if requests[storeIdA] != calls || requests[storeIdB] != calls {
+		t.Errorf("expected %d requests per store, got %v", calls, requests)
+	}
+}
@@ -0,0 +1,94 @@
+package openfga
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	openfga "github.com/openfga/go-sdk"
+	ofgaClient "github.com/openfga/go-sdk/client"
+)
+
+// Assertion is the expected result of checking a tuple, which is stored with an authorization model.
+type Assertion struct {
+	Tuple
+	Expectation bool
+}
+
+// Check returns whether the user of the tuple has the relation to its object in the authorization model,
+// as if the contextual tuples were written to the store.
+func (s *OpenFgaStoreService) Check(ctx context.Context, authorizationModelId string, tuple Tuple, contextualTuples []Tuple) (bool, error) {
+	body := ofgaClient.ClientCheckRequest{
+		User:             tuple.User,
+		Relation:         tuple.Relation,
+		Object:           tuple.Object,
+		ContextualTuples: toContextualTuples(contextualTuples),
+	}
+	options := ofgaClient.ClientCheckOptions{AuthorizationModelId: openfga.PtrString(authorizationModelId)}
+	var response *ofgaClient.ClientCheckResponse
+	err := s.clients.retrier.call(ctx, true, func(ctx context.Context) (err error) {
+		response, err = s.client.Check(ctx).Body(body).Options(options).Execute()
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return response.GetAllowed(), nil
+}
+
+// ListObjects returns the objects of the type the user has the relation to in the authorization model, as
+// if the contextual tuples were written to the store.
+func (s *OpenFgaStoreService) ListObjects(
+	ctx context.Context,
+	authorizationModelId string,
+	user, relation, objectType string,
+	contextualTuples []Tuple) ([]string, error) {
+	body := ofgaClient.ClientListObjectsRequest{
+		User:             user,
+		Relation:         relation,
+		Type:             objectType,
+		ContextualTuples: toContextualTuples(contextualTuples),
+	}
+	options := ofgaClient.ClientListObjectsOptions{AuthorizationModelId: openfga.PtrString(authorizationModelId)}
+	var response *ofgaClient.ClientListObjectsResponse
+	err := s.clients.retrier.call(ctx, true, func(ctx context.Context) (err error) {
+		response, err = s.client.ListObjects(ctx).Body(body).Options(options).Execute()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response.GetObjects(), nil
+}
+
+// WriteAssertions replaces the assertions stored with the authorization model.
+func (s *OpenFgaStoreService) WriteAssertions(ctx context.Context, authorizationModelId string, assertions []Assertion, log *logr.Logger) error {
+	body := make(ofgaClient.ClientWriteAssertionsRequest, 0, len(assertions))
+	for _, assertion := range assertions {
+		body = append(body, ofgaClient.ClientAssertion{
+			User:        assertion.User,
+			Relation:    assertion.Relation,
+			Object:      assertion.Object,
+			Expectation: assertion.Expectation,
+		})
+	}
+	options := ofgaClient.ClientWriteAssertionsOptions{AuthorizationModelId: openfga.PtrString(authorizationModelId)}
+	err := s.clients.retrier.call(ctx, true, func(ctx context.Context) error {
+		_, err := s.client.WriteAssertions(ctx).Body(body).Options(options).Execute()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	log.V(1).Info("Wrote assertions to OpenFGA", "authModelId", authorizationModelId, "assertions", len(assertions))
+	return nil
+}
+
+func toContextualTuples(tuples []Tuple) []ofgaClient.ClientContextualTupleKey {
+	if len(tuples) == 0 {
+		return nil
+	}
+	result := make([]ofgaClient.ClientContextualTupleKey, 0, len(tuples))
+	for _, tuple := range tuples {
+		result = append(result, ofgaClient.ClientContextualTupleKey{User: tuple.User, Relation: tuple.Relation, Object: tuple.Object})
+	}
+	return result
+}
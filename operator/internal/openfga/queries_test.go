@@ -0,0 +1,56 @@
+package openfga
+
+import (
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"testing"
+)
+
+const queryModelId = "01GXSA8YR785C4FYS3C0RTG7B1"
+
+func TestCheckRetriesTransientFailures(t *testing.T) {
+	// Arrange
+	allowed := response{status: http.StatusOK, body: `{"allowed": true}`}
+	service, calls, _ := newRetryTestService(t, testRequestSettings, unavailable, allowed)
+	storeService, err := service.ForStore(storeIdA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contextualTuples := []Tuple{{User: "user:anne", Relation: "viewer", Object: "document:roadmap"}}
+
+	// Act
+	result, err := storeService.Check(context.Background(), queryModelId, contextualTuples[0], contextualTuples)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result {
+		t.Error("expected the check to be allowed")
+	}
+	if *calls != 2 {
+		t.Errorf("expected a retry, got %d calls", *calls)
+	}
+}
+
+func TestListObjects(t *testing.T) {
+	// Arrange
+	objects := response{status: http.StatusOK, body: `{"objects": ["document:a", "document:b"]}`}
+	service, _, _ := newRetryTestService(t, RequestConfig{}, objects)
+	storeService, err := service.ForStore(storeIdA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	result, err := storeService.ListObjects(context.Background(), queryModelId, "user:anne", "viewer", "document", nil)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"document:a", "document:b"}, result); diff != "" {
+		t.Errorf("unexpected objects (-want +got):\n%s", diff)
+	}
+}
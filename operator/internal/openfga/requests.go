@@ -0,0 +1,207 @@
+package openfga
+
+import (
+	"flag"
+	"fmt"
+	openfga "github.com/openfga/go-sdk"
+	"strconv"
+	"time"
+)
+
+// Environment variables tuning the requests to OpenFGA.
+const (
+	OpenFgaPageSize       = "OPENFGA_PAGE_SIZE"
+	OpenFgaStoreIndexTTL  = "OPENFGA_STORE_INDEX_TTL"
+	OpenFgaMaxRetries     = "OPENFGA_MAX_RETRIES"
+	OpenFgaRetryBaseDelay = "OPENFGA_RETRY_BASE_DELAY"
+	OpenFgaRetryMaxDelay  = "OPENFGA_RETRY_MAX_DELAY"
+	OpenFgaCallTimeout    = "OPENFGA_CALL_TIMEOUT"
+	OpenFgaRateLimit      = "OPENFGA_RATE_LIMIT"
+	OpenFgaRateBurst      = "OPENFGA_RATE_BURST"
+)
+
+const (
+	// DefaultPageSize is the page size when listing stores and authorization models.
+	DefaultPageSize = 50
+
+	// MaxPageSize is the largest page size accepted by OpenFGA.
+	MaxPageSize = 100
+
+	// DefaultStoreIndexTTL is how long the ID of a store found by name is reused.
+	DefaultStoreIndexTTL = 5 * time.Minute
+
+	DefaultMaxRetries     = 3
+	DefaultRetryBaseDelay = 200 * time.Millisecond
+	DefaultRetryMaxDelay  = 10 * time.Second
+	DefaultCallTimeout    = 30 * time.Second
+	DefaultRateLimit      = 50
+)
+
+// RequestConfig tunes the requests to OpenFGA. It applies to the OpenFGA server of the operator and all
+// connections. The zero value lists with the default page size, and disables the store index, retries,
+// timeouts and rate limiting.
+type RequestConfig struct {
+	// PageSize of listed stores and authorization models, where zero is DefaultPageSize.
+	PageSize int
+	// StoreIndexTTL is how long the ID of a store found by name is reused, where zero disables the index.
+	StoreIndexTTL time.Duration
+
+	// MaxRetries of a call failing with a transient error, e.g. a rate limit or an internal error.
+	MaxRetries int
+	// RetryBaseDelay is the delay before the first retry, which doubles on every retry up to RetryMaxDelay.
+	// A delay requested by OpenFGA with Retry-After takes precedence.
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// CallTimeout limits every attempt of a call, where zero is no limit.
+	CallTimeout time.Duration
+
+	// RateLimit is the number of requests per second to the OpenFGA server of a connection, where zero is
+	// no limit. RateBurst is the number of requests above the rate limit allowed at once, and defaults to
+	// the rate limit.
+	RateLimit int
+	RateBurst int
+}
+
+// RequestFlags are the command line flags tuning the requests to OpenFGA.
+type RequestFlags struct {
+	PageSize       string
+	StoreIndexTTL  string
+	MaxRetries     string
+	RetryBaseDelay string
+	RetryMaxDelay  string
+	CallTimeout    string
+	RateLimit      string
+	RateBurst      string
+}
+
+func (f *RequestFlags) bindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.PageSize, "openfga-page-size", "",
+		fmt.Sprintf("Page size when listing stores and authorization models, at most %d. Defaults to %d. Overrides %s.", MaxPageSize, DefaultPageSize, OpenFgaPageSize))
+	fs.StringVar(&f.StoreIndexTTL, "openfga-store-index-ttl", "",
+		fmt.Sprintf("How long the ID of a store found by name is reused, e.g. 1m, where 0 disables it. Defaults to %s. Overrides %s.", DefaultStoreIndexTTL, OpenFgaStoreIndexTTL))
+	fs.StringVar(&f.MaxRetries, "openfga-max-retries", "",
+		fmt.Sprintf("Retries of calls to OpenFGA failing with a transient error, where 0 disables them. Defaults to %d. Overrides %s.", DefaultMaxRetries, OpenFgaMaxRetries))
+	fs.StringVar(&f.RetryBaseDelay, "openfga-retry-base-delay", "",
+		fmt.Sprintf("Delay before the first retry, which doubles on every retry. Defaults to %s. Overrides %s.", DefaultRetryBaseDelay, OpenFgaRetryBaseDelay))
+	fs.StringVar(&f.RetryMaxDelay, "openfga-retry-max-delay", "",
+		fmt.Sprintf("Longest delay between retries. Defaults to %s. Overrides %s.", DefaultRetryMaxDelay, OpenFgaRetryMaxDelay))
+	fs.StringVar(&f.CallTimeout, "openfga-call-timeout", "",
+		fmt.Sprintf("Timeout of every attempt of a call to OpenFGA, where 0 disables it. Defaults to %s. Overrides %s.", DefaultCallTimeout, OpenFgaCallTimeout))
+	fs.StringVar(&f.RateLimit, "openfga-rate-limit", "",
+		fmt.Sprintf("Requests per second to an OpenFGA server, where 0 disables the limit. Defaults to %d. Overrides %s.", DefaultRateLimit, OpenFgaRateLimit))
+	fs.StringVar(&f.RateBurst, "openfga-rate-burst", "",
+		fmt.Sprintf("Requests above the rate limit allowed at once. Defaults to the rate limit. Overrides %s.", OpenFgaRateBurst))
+}
+
+// newRequestConfig reads the request configuration like NewConfig, with defaults for unset settings.
+func newRequestConfig(flags RequestFlags, credentialsDir string) (RequestConfig, error) {
+	config := RequestConfig{
+		StoreIndexTTL:  DefaultStoreIndexTTL,
+		MaxRetries:     DefaultMaxRetries,
+		RetryBaseDelay: DefaultRetryBaseDelay,
+		RetryMaxDelay:  DefaultRetryMaxDelay,
+		CallTimeout:    DefaultCallTimeout,
+		RateLimit:      DefaultRateLimit,
+	}
+	numbers := []struct {
+		flag  string
+		name  string
+		value *int
+	}{
+		{flags.PageSize, OpenFgaPageSize, &config.PageSize},
+		{flags.MaxRetries, OpenFgaMaxRetries, &config.MaxRetries},
+		{flags.RateLimit, OpenFgaRateLimit, &config.RateLimit},
+		{flags.RateBurst, OpenFgaRateBurst, &config.RateBurst},
+	}
+	for _, setting := range numbers {
+		value, err := getSetting(setting.flag, setting.name, credentialsDir)
+		if err != nil {
+			return RequestConfig{}, err
+		}
+		if value == "" {
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return RequestConfig{}, fmt.Errorf("invalid %s: %w", setting.name, err)
+		}
+		*setting.value = number
+	}
+	durations := []struct {
+		flag  string
+		name  string
+		value *time.Duration
+	}{
+		{flags.StoreIndexTTL, OpenFgaStoreIndexTTL, &config.StoreIndexTTL},
+		{flags.RetryBaseDelay, OpenFgaRetryBaseDelay, &config.RetryBaseDelay},
+		{flags.RetryMaxDelay, OpenFgaRetryMaxDelay, &config.RetryMaxDelay},
+		{flags.CallTimeout, OpenFgaCallTimeout, &config.CallTimeout},
+	}
+	for _, setting := range durations {
+		value, err := getSetting(setting.flag, setting.name, credentialsDir)
+		if err != nil {
+			return RequestConfig{}, err
+		}
+		if value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return RequestConfig{}, fmt.Errorf("invalid %s: %w", setting.name, err)
+		}
+		*setting.value = duration
+	}
+	return config, nil
+}
+
+func (c RequestConfig) validate() error {
+	if c.PageSize < 0 || c.PageSize > MaxPageSize {
+		return fmt.Errorf("invalid %s %d, must be between 1 and %d", OpenFgaPageSize, c.PageSize, MaxPageSize)
+	}
+	for _, setting := range []struct {
+		name  string
+		value int
+	}{
+		{OpenFgaMaxRetries, c.MaxRetries},
+		{OpenFgaRateLimit, c.RateLimit},
+		{OpenFgaRateBurst, c.RateBurst},
+	} {
+		if setting.value < 0 {
+			return fmt.Errorf("invalid %s %d, may not be negative", setting.name, setting.value)
+		}
+	}
+	for _, setting := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{OpenFgaStoreIndexTTL, c.StoreIndexTTL},
+		{OpenFgaRetryBaseDelay, c.RetryBaseDelay},
+		{OpenFgaRetryMaxDelay, c.RetryMaxDelay},
+		{OpenFgaCallTimeout, c.CallTimeout},
+	} {
+		if setting.value < 0 {
+			return fmt.Errorf("invalid %s %s, may not be negative", setting.name, setting.value)
+		}
+	}
+	if c.RetryBaseDelay > c.RetryMaxDelay {
+		return fmt.Errorf("%s %s may not be longer than %s %s", OpenFgaRetryBaseDelay, c.RetryBaseDelay, OpenFgaRetryMaxDelay, c.RetryMaxDelay)
+	}
+	return nil
+}
+
+// pageSize returns the page size of listed stores and authorization models.
+func (c RequestConfig) pageSize() *int32 {
+	if c.PageSize == 0 {
+		return openfga.PtrInt32(DefaultPageSize)
+	}
+	return openfga.PtrInt32(int32(c.PageSize))
+}
+
+// rateBurst returns the number of requests allowed at once by the rate limiter.
+func (c RequestConfig) rateBurst() int {
+	if c.RateBurst == 0 {
+		return c.RateLimit
+	}
+	return c.RateBurst
+}
@@ -0,0 +1,213 @@
+package openfga
+
+import (
+	"context"
+	"errors"
+	openfga "github.com/openfga/go-sdk"
+	ofgaClient "github.com/openfga/go-sdk/client"
+	"golang.org/x/time/rate"
+	"math/rand"
+	"net"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"strconv"
+	"time"
+)
+
+// TransientError is a failure which may succeed when retried later, e.g. because OpenFGA was rate limited
+// or unavailable, after the retries of the call were exhausted.
+type TransientError struct {
+	err error
+
+	// RetryAfter is the delay OpenFGA asked for before retrying, or zero when it didn't.
+	RetryAfter time.Duration
+}
+
+func (e *TransientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.err
+}
+
+// PermanentError is a failure which fails again when retried, e.g. an invalid authorization model.
+type PermanentError struct {
+	err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks the error as permanent, such that it isn't retried.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{err: err}
+}
+
+// IsPermanent returns true when the error fails again when retried.
+func IsPermanent(err error) bool {
+	var permanentError *PermanentError
+	return errors.As(err, &permanentError)
+}
+
+// ReconcileResult returns the result of a reconciliation which failed with the error. Permanent failures
+// aren't retried by controller-runtime until the resource changes, and transient failures are retried
+// after the delay OpenFGA asked for, or else with the backoff of controller-runtime.
+func ReconcileResult(err error) (reconcile.Result, error) {
+	if IsPermanent(err) {
+		return reconcile.Result{}, reconcile.TerminalError(err)
+	}
+	var transientError *TransientError
+	if errors.As(err, &transientError) && transientError.RetryAfter > 0 {
+		return reconcile.Result{RequeueAfter: transientError.RetryAfter}, nil
+	}
+	return reconcile.Result{}, err
+}
+
+// retrier calls OpenFGA with a rate limit and a timeout per attempt, and retries transient failures with
+// jittered exponential backoff. It's shared by the clients of a connection.
+type retrier struct {
+	config  RequestConfig
+	limiter *rate.Limiter
+	sleep   func(ctx context.Context, delay time.Duration) error
+}
+
+func newRetrier(config RequestConfig) *retrier {
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	if config.RateLimit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(config.RateLimit), config.rateBurst())
+	}
+	return &retrier{config: config, limiter: limiter, sleep: sleep}
+}
+
+// call calls the operation until it succeeds, fails permanently or the retries are exhausted, and
+// returns the failure as TransientError or PermanentError. An operation which isn't idempotent, e.g.
+// creating a store, is only retried when OpenFGA didn't process the request.
+func (r *retrier) call(ctx context.Context, idempotent bool, operation func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		if err := r.limiter.Wait(ctx); err != nil {
+			return &TransientError{err: err}
+		}
+		err := r.attempt(ctx, operation)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return &TransientError{err: err}
+		}
+		// Credentials fail until they are changed, which doesn't change the resource being reconciled.
+		var authenticationError openfga.FgaApiAuthenticationError
+		if errors.As(err, &authenticationError) {
+			return &TransientError{err: err}
+		}
+		transient, unprocessed, retryAfter := classify(err)
+		if !transient {
+			return Permanent(err)
+		}
+		if attempt >= r.config.MaxRetries || !idempotent && !unprocessed {
+			return &TransientError{err: err, RetryAfter: retryAfter}
+		}
+		delay := r.backoff(attempt)
+		if retryAfter > 0 {
+			if retryAfter > r.config.RetryMaxDelay {
+				return &TransientError{err: err, RetryAfter: retryAfter}
+			}
+			delay = retryAfter
+		}
+		if err := r.sleep(ctx, delay); err != nil {
+			return &TransientError{err: err}
+		}
+	}
+}
+
+func (r *retrier) attempt(ctx context.Context, operation func(ctx context.Context) error) error {
+	if r.config.CallTimeout == 0 {
+		return operation(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.config.CallTimeout)
+	defer cancel()
+	return operation(ctx)
+}
+
+// backoff returns the delay before the retry after the attempt, which is a random delay between half
+// and all of the exponential delay, such that concurrent reconciliations don't retry at once.
+func (r *retrier) backoff(attempt int) time.Duration {
+	delay := r.config.RetryBaseDelay
+	for i := 0; i < attempt && delay < r.config.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, r.config.RetryMaxDelay)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// classify returns whether the failure is transient, whether OpenFGA didn't process the request, such that
+// it can be retried even when it isn't idempotent, and the delay OpenFGA asked for.
+func classify(err error) (transient bool, unprocessed bool, retryAfter time.Duration) {
+	var rateLimitError openfga.FgaApiRateLimitExceededError
+	if errors.As(err, &rateLimitError) {
+		return true, true, parseRetryAfter(rateLimitError.ResponseHeader())
+	}
+	var internalError openfga.FgaApiInternalError
+	if errors.As(err, &internalError) {
+		return internalError.ResponseStatusCode() != http.StatusNotImplemented, false, parseRetryAfter(internalError.ResponseHeader())
+	}
+	var apiError openfga.FgaApiError
+	if errors.As(err, &apiError) {
+		status := apiError.ResponseStatusCode()
+		return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError, status == http.StatusTooManyRequests,
+			parseRetryAfter(apiError.ResponseHeader())
+	}
+	var validationError openfga.FgaApiValidationError
+	var notFoundError openfga.FgaApiNotFoundError
+	var invalidError ofgaClient.FgaInvalidError
+	var requiredParamError ofgaClient.FgaRequiredParamError
+	if errors.As(err, &validationError) || errors.As(err, &notFoundError) ||
+		errors.As(err, &invalidError) || errors.As(err, &requiredParamError) {
+		return false, false, 0
+	}
+	var opError *net.OpError
+	if errors.As(err, &opError) {
+		return true, opError.Op == "dial", 0
+	}
+	// Other failures, e.g. timeouts and broken connections, may succeed when retried.
+	return true, false, 0
+}
+
+// parseRetryAfter returns the delay of the Retry-After header, which is either seconds or a date.
+func parseRetryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
@@ -0,0 +1,307 @@
+package openfga
+
+import (
+	"context"
+	"errors"
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/time/rate"
+	"net/http"
+	"net/http/httptest"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sync"
+	"testing"
+	"time"
+)
+
+// response is a response of the fake OpenFGA server.
+type response struct {
+	status     int
+	retryAfter string
+	body       string
+}
+
+// newRetryTestService returns a service of a server responding with the responses in order, and then with
+// the last one, and the number of requests and the delays of the retries.
+func newRetryTestService(t *testing.T, requests RequestConfig, responses ...response) (PermissionService, *int, *[]time.Duration) {
+	var mutex sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		current := responses[min(calls, len(responses)-1)]
+		calls++
+		mutex.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if current.retryAfter != "" {
+			w.Header().Set("Retry-After", current.retryAfter)
+		}
+		w.WriteHeader(current.status)
+		_, _ = w.Write([]byte(current.body))
+	}))
+	t.Cleanup(server.Close)
+	service, err := NewOpenFgaServiceFactory(logr.Discard()).GetService(Config{ApiUrl: server.URL, AuthMode: AuthModeNone, Requests: requests})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var delays []time.Duration
+	service.(*OpenFgaService).clients.retrier.sleep = func(ctx context.Context, delay time.Duration) error {
+		delays = append(delays, delay)
+		return nil
+	}
+	return service, &calls, &delays
+}
+
+var (
+	storeResponse       = response{status: http.StatusOK, body: `{"id": "` + storeIdA + `", "name": "store"}`}
+	unavailable         = response{status: http.StatusServiceUnavailable, body: `{"code": "internal_error", "message": "unavailable"}`}
+	rateLimited         = response{status: http.StatusTooManyRequests, retryAfter: "2", body: `{"code": "rate_limit_exceeded", "message": "slow down"}`}
+	invalidModel        = response{status: http.StatusBadRequest, body: `{"code": "invalid_authorization_model", "message": "invalid"}`}
+	testRequestSettings = RequestConfig{MaxRetries: 2, RetryBaseDelay: time.Second, RetryMaxDelay: 10 * time.Second}
+)
+
+func TestRetries(t *testing.T) {
+	testCases := []struct {
+		description       string
+		responses         []response
+		call              func(service PermissionService) error
+		expectedCalls     int
+		expectedDelays    int
+		expectedRetries   []time.Duration
+		expectedPermanent bool
+		expectedTransient bool
+	}{
+		{
+			description: "retries transient failures",
+			responses:   []response{unavailable, storeResponse},
+			call: func(service PermissionService) error {
+				_, err := service.CheckExistingStoresById(context.Background(), storeIdA)
+				return err
+			},
+			expectedCalls:  2,
+			expectedDelays: 1,
+		},
+		{
+			description: "honors retry after",
+			responses:   []response{rateLimited, storeResponse},
+			call: func(service PermissionService) error {
+				_, err := service.CheckExistingStoresById(context.Background(), storeIdA)
+				return err
+			},
+			expectedCalls:   2,
+			expectedRetries: []time.Duration{2 * time.Second},
+		},
+		{
+			description: "gives up after the retries",
+			responses:   []response{unavailable},
+			call: func(service PermissionService) error {
+				_, err := service.CheckExistingStoresById(context.Background(), storeIdA)
+				return err
+			},
+			expectedCalls:     3,
+			expectedDelays:    2,
+			expectedTransient: true,
+		},
+		{
+			description: "doesn't retry writes which may have been processed",
+			responses:   []response{unavailable},
+			call: func(service PermissionService) error {
+				_, err := service.CreateStore(context.Background(), "store", &logr.Logger{})
+				return err
+			},
+			expectedCalls:     1,
+			expectedTransient: true,
+		},
+		{
+			description: "retries writes which were rate limited",
+			responses:   []response{rateLimited, storeResponse},
+			call: func(service PermissionService) error {
+				_, err := service.CreateStore(context.Background(), "store", &logr.Logger{})
+				return err
+			},
+			expectedCalls:   2,
+			expectedRetries: []time.Duration{2 * time.Second},
+		},
+		{
+			description: "doesn't retry authentication failures",
+			responses:   []response{{status: http.StatusUnauthorized, body: `{"code": "unauthenticated"}`}},
+			call: func(service PermissionService) error {
+				_, err := service.CheckExistingStoresById(context.Background(), storeIdA)
+				return err
+			},
+			expectedCalls:     1,
+			expectedTransient: true,
+		},
+		{
+			description: "doesn't retry permanent failures",
+			responses:   []response{{status: http.StatusOK, body: `{"authorization_models": []}`}, invalidModel},
+			call: func(service PermissionService) error {
+				storeService, err := service.ForStore(storeIdA)
+				if err != nil {
+					return err
+				}
+				_, err = storeService.CreateAuthorizationModel(context.Background(), model, &logr.Logger{})
+				return err
+			},
+			expectedCalls:     2,
+			expectedPermanent: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			service, calls, delays := newRetryTestService(t, testRequestSettings, testCase.responses...)
+
+			// Act
+			err := testCase.call(service)
+
+			// Assert
+			if *calls != testCase.expectedCalls {
+				t.Errorf("expected %d calls, got %d", testCase.expectedCalls, *calls)
+			}
+			if testCase.expectedRetries != nil {
+				if diff := cmp.Diff(testCase.expectedRetries, *delays); diff != "" {
+					t.Errorf("unexpected delays (-want +got):\n%s", diff)
+				}
+			} else if len(*delays) != testCase.expectedDelays {
+				t.Errorf("expected %d delays, got %v", testCase.expectedDelays, *delays)
+			}
+			var transientError *TransientError
+			if IsPermanent(err) != testCase.expectedPermanent || errors.As(err, &transientError) != testCase.expectedTransient {
+				t.Errorf("expected permanent %v and transient %v, got %v", testCase.expectedPermanent, testCase.expectedTransient, err)
+			}
+			if err == nil && (testCase.expectedPermanent || testCase.expectedTransient) {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestRetryAfterLongerThanMaxDelay(t *testing.T) {
+	// Arrange
+	slowDown := response{status: http.StatusTooManyRequests, retryAfter: "60", body: `{"code": "rate_limit_exceeded"}`}
+	service, calls, _ := newRetryTestService(t, testRequestSettings, slowDown)
+
+	// Act
+	_, err := service.CheckExistingStoresById(context.Background(), storeIdA)
+
+	// Assert
+	var transientError *TransientError
+	if !errors.As(err, &transientError) || transientError.RetryAfter != time.Minute {
+		t.Fatalf("expected a transient error to retry after a minute, got %v", err)
+	}
+	if *calls != 1 {
+		t.Errorf("expected no retries, got %d calls", *calls)
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	service, err := NewOpenFgaServiceFactory(logr.Discard()).GetService(Config{
+		ApiUrl:   server.URL,
+		AuthMode: AuthModeNone,
+		Requests: RequestConfig{CallTimeout: 10 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	_, err = service.CheckExistingStoresById(context.Background(), storeIdA)
+
+	// Assert
+	var transientError *TransientError
+	if !errors.As(err, &transientError) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a transient timeout, got %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	// Arrange
+	retrier := newRetrier(RequestConfig{RetryBaseDelay: time.Second, RetryMaxDelay: 5 * time.Second})
+
+	for attempt, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		// Act
+		delay := retrier.backoff(attempt)
+
+		// Assert
+		if delay < expected/2 || delay > expected {
+			t.Errorf("expected delay of attempt %d between %s and %s, got %s", attempt, expected/2, expected, delay)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	// Act
+	unlimited := newRetrier(RequestConfig{})
+	limited := newRetrier(RequestConfig{RateLimit: 10})
+	burst := newRetrier(RequestConfig{RateLimit: 10, RateBurst: 30})
+
+	// Assert
+	if unlimited.limiter.Limit() != rate.Inf {
+		t.Errorf("expected no rate limit, got %v", unlimited.limiter.Limit())
+	}
+	if limited.limiter.Limit() != 10 || limited.limiter.Burst() != 10 {
+		t.Errorf("expected 10 requests per second, got %v with burst %d", limited.limiter.Limit(), limited.limiter.Burst())
+	}
+	if burst.limiter.Burst() != 30 {
+		t.Errorf("expected burst 30, got %d", burst.limiter.Burst())
+	}
+}
+
+func TestReconcileResult(t *testing.T) {
+	failure := errors.New("failure")
+	testCases := []struct {
+		description      string
+		err              error
+		expectedResult   reconcile.Result
+		expectedTerminal bool
+		expectedError    bool
+	}{
+		{
+			description:    "unclassified",
+			err:            failure,
+			expectedError:  true,
+			expectedResult: reconcile.Result{},
+		},
+		{
+			description:      "permanent",
+			err:              Permanent(failure),
+			expectedError:    true,
+			expectedTerminal: true,
+		},
+		{
+			description:    "transient",
+			err:            &TransientError{err: failure},
+			expectedError:  true,
+			expectedResult: reconcile.Result{},
+		},
+		{
+			description:    "transient with retry after",
+			err:            &TransientError{err: failure, RetryAfter: time.Minute},
+			expectedResult: reconcile.Result{RequeueAfter: time.Minute},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Act
+			result, err := ReconcileResult(testCase.err)
+
+			// Assert
+			if result != testCase.expectedResult {
+				t.Errorf("expected result %+v, got %+v", testCase.expectedResult, result)
+			}
+			if (err != nil) != testCase.expectedError {
+				t.Errorf("expected error %v, got %v", testCase.expectedError, err)
+			}
+			if errors.Is(err, reconcile.TerminalError(nil)) != testCase.expectedTerminal {
+				t.Errorf("expected terminal error %v, got %v", testCase.expectedTerminal, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package openfga
+
+import (
+	"sync"
+	"time"
+)
+
+// storeIndex maps store names to IDs, such that a store can be looked up by name without listing all
+// stores. Entries expire after the TTL, and an index with a TTL of zero is empty. Store names aren't
+// unique in OpenFGA, so the first store with a name which was listed is kept.
+type storeIndex struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mutex   sync.Mutex
+	entries map[string]storeIndexEntry
+}
+
+type storeIndexEntry struct {
+	id      string
+	expires time.Time
+}
+
+func newStoreIndex(ttl time.Duration) *storeIndex {
+	return &storeIndex{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]storeIndexEntry),
+	}
+}
+
+// get returns the ID of the store with the name, unless it's unknown or expired.
+func (i *storeIndex) get(name string) (string, bool) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	entry, ok := i.entries[name]
+	if !ok {
+		return "", false
+	}
+	if !i.now().Before(entry.expires) {
+		delete(i.entries, name)
+		return "", false
+	}
+	return entry.id, true
+}
+
+// add adds the store, unless another store with the same name is known.
+func (i *storeIndex) add(name, id string) {
+	if i.ttl <= 0 {
+		return
+	}
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	now := i.now()
+	if entry, ok := i.entries[name]; ok && entry.id != id && now.Before(entry.expires) {
+		return
+	}
+	i.entries[name] = storeIndexEntry{id: id, expires: now.Add(i.ttl)}
+}
+
+// remove removes the store with the ID.
+func (i *storeIndex) remove(id string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	for name, entry := range i.entries {
+		if entry.id == id {
+			delete(i.entries, name)
+		}
+	}
+}
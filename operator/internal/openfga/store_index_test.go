@@ -0,0 +1,220 @@
+package openfga
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-logr/logr"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStoreIndex(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	index := newStoreIndex(time.Minute)
+	index.now = func() time.Time { return now }
+
+	// Act
+	index.add("store", storeIdA)
+	index.add("store", storeIdB)
+	index.add("other", storeIdB)
+	first, firstOk := index.get("store")
+	index.remove(storeIdB)
+	_, removedOk := index.get("other")
+	now = now.Add(time.Minute)
+	_, expiredOk := index.get("store")
+
+	// Assert
+	if !firstOk || first != storeIdA {
+		t.Errorf("expected the first store with the name, got %q", first)
+	}
+	if removedOk {
+		t.Errorf("expected the removed store not to be found")
+	}
+	if expiredOk {
+		t.Errorf("expected the expired store not to be found")
+	}
+}
+
+func TestStoreIndexDisabled(t *testing.T) {
+	// Arrange
+	index := newStoreIndex(0)
+
+	// Act
+	index.add("store", storeIdA)
+	_, ok := index.get("store")
+
+	// Assert
+	if ok {
+		t.Errorf("expected an index without TTL to be empty")
+	}
+}
+
+// fakeOpenFga serves the stores and authorization models of OpenFGA, and counts the requests per operation.
+type fakeOpenFga struct {
+	mutex    sync.Mutex
+	stores   []map[string]string
+	models   map[string]bool
+	requests map[string]int
+}
+
+func (f *fakeOpenFga) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(path) == 1:
+		f.requests["ListStores"]++
+		pageSize := 2
+		start := 0
+		if token := r.URL.Query().Get("continuation_token"); token != "" {
+			start = len(token)
+		}
+		end := min(start+pageSize, len(f.stores))
+		token := ""
+		if end < len(f.stores) {
+			token = strings.Repeat("x", end)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"stores": f.stores[start:end], "continuation_token": token})
+	case len(path) == 2:
+		f.requests["GetStore"]++
+		for _, store := range f.stores {
+			if store["id"] == path[1] {
+				_ = json.NewEncoder(w).Encode(store)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"code": "store_id_not_found", "message": "store not found"})
+	case len(path) == 4:
+		f.requests["ReadAuthorizationModel"]++
+		if f.models[path[3]] {
+			_ = json.NewEncoder(w).Encode(map[string]any{"authorization_model": map[string]any{"id": path[3], "schema_version": "1.1"}})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"code": "authorization_model_not_found", "message": "model not found"})
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func newFakeOpenFgaService(t *testing.T, fake *fakeOpenFga) PermissionService {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	service, err := NewOpenFgaServiceFactory(logr.Discard()).GetService(Config{
+		ApiUrl:   server.URL,
+		AuthMode: AuthModeNone,
+		Requests: RequestConfig{StoreIndexTTL: time.Minute},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service
+}
+
+func TestCheckExistingStoresByName(t *testing.T) {
+	// Arrange
+	fake := &fakeOpenFga{
+		stores: []map[string]string{
+			{"id": "01HVMMBCMGZNT3SED4Z17ECXC0", "name": "first"},
+			{"id": "01HVMMBCMGZNT3SED4Z17ECXC1", "name": "second"},
+			{"id": storeIdA, "name": "store"},
+		},
+		requests: map[string]int{},
+	}
+	service := newFakeOpenFgaService(t, fake)
+
+	// Act
+	listed, errListed := service.CheckExistingStoresByName(context.Background(), "store")
+	indexed, errIndexed := service.CheckExistingStoresByName(context.Background(), "store")
+	alsoListed, errAlsoListed := service.CheckExistingStoresByName(context.Background(), "first")
+	fake.stores = fake.stores[:2]
+	deleted, errDeleted := service.CheckExistingStoresByName(context.Background(), "store")
+
+	// Assert
+	for _, err := range []error{errListed, errIndexed, errAlsoListed, errDeleted} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if listed == nil || listed.Id != storeIdA || indexed == nil || indexed.Id != storeIdA {
+		t.Errorf("expected store %s, got %+v and %+v", storeIdA, listed, indexed)
+	}
+	if alsoListed == nil || alsoListed.Name != "first" {
+		t.Errorf("expected store first, got %+v", alsoListed)
+	}
+	if deleted != nil {
+		t.Errorf("expected the deleted store not to be found, got %+v", deleted)
+	}
+	expected := map[string]int{"ListStores": 3, "GetStore": 3}
+	if fake.requests["ListStores"] != expected["ListStores"] || fake.requests["GetStore"] != expected["GetStore"] {
+		t.Errorf("expected requests %v, got %v", expected, fake.requests)
+	}
+}
+
+func TestCheckExistingStoresById(t *testing.T) {
+	// Arrange
+	fake := &fakeOpenFga{
+		stores:   []map[string]string{{"id": storeIdA, "name": "store"}},
+		requests: map[string]int{},
+	}
+	service := newFakeOpenFgaService(t, fake)
+
+	// Act
+	existing, errExisting := service.CheckExistingStoresById(context.Background(), storeIdA)
+	missing, errMissing := service.CheckExistingStoresById(context.Background(), storeIdB)
+	invalid, errInvalid := service.CheckExistingStoresById(context.Background(), "non-existing-store")
+
+	// Assert
+	for _, err := range []error{errExisting, errMissing, errInvalid} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if existing == nil || existing.Name != "store" {
+		t.Errorf("expected store %s, got %+v", storeIdA, existing)
+	}
+	if missing != nil || invalid != nil {
+		t.Errorf("expected no stores, got %+v and %+v", missing, invalid)
+	}
+	if fake.requests["GetStore"] != 2 || fake.requests["ListStores"] != 0 {
+		t.Errorf("expected 2 direct lookups, got %v", fake.requests)
+	}
+}
+
+func TestCheckAuthorizationModelExists(t *testing.T) {
+	// Arrange
+	const modelId = "01HVMMBCMGZNT3SED4Z17ECXCB"
+	fake := &fakeOpenFga{
+		models:   map[string]bool{modelId: true},
+		requests: map[string]int{},
+	}
+	service, err := newFakeOpenFgaService(t, fake).ForStore(storeIdA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	existing, errExisting := service.CheckAuthorizationModelExists(context.Background(), modelId)
+	missing, errMissing := service.CheckAuthorizationModelExists(context.Background(), "01HVMMBCMGZNT3SED4Z17ECXCC")
+	invalid, errInvalid := service.CheckAuthorizationModelExists(context.Background(), "invalid")
+
+	// Assert
+	for _, err := range []error{errExisting, errMissing, errInvalid} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !existing || missing || invalid {
+		t.Errorf("expected only %s to exist, got %v, %v and %v", modelId, existing, missing, invalid)
+	}
+	if fake.requests["ReadAuthorizationModel"] != 2 {
+		t.Errorf("expected 2 direct lookups, got %v", fake.requests)
+	}
+}
@@ -0,0 +1,108 @@
+package openfga
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	openfga "github.com/openfga/go-sdk"
+	ofgaClient "github.com/openfga/go-sdk/client"
+)
+
+// MaxTuplesPerWrite is the number of tuples OpenFGA accepts in a single write by default. Larger writes
+// are split into several transactions.
+const MaxTuplesPerWrite = 100
+
+// Tuple is a relationship tuple of a store.
+type Tuple struct {
+	User     string
+	Relation string
+	Object   string
+}
+
+// WriteTuples writes the tuples to the store in transactions of at most MaxTuplesPerWrite tuples. When a
+// transaction fails, the tuples of the previous transactions remain written. A tuple which already exists
+// fails the transaction.
+func (s *OpenFgaStoreService) WriteTuples(ctx context.Context, tuples []Tuple, log *logr.Logger) error {
+	for _, chunk := range chunkTuples(tuples) {
+		body := ofgaClient.ClientWriteRequest{Writes: make([]ofgaClient.ClientTupleKey, 0, len(chunk))}
+		for _, tuple := range chunk {
+			body.Writes = append(body.Writes, ofgaClient.ClientTupleKey{User: tuple.User, Relation: tuple.Relation, Object: tuple.Object})
+		}
+		// A retried write which was processed fails as the tuples already exist.
+		err := s.clients.retrier.call(ctx, false, func(ctx context.Context) error {
+			_, err := s.client.Write(ctx).Body(body).Execute()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		log.V(1).Info("Wrote tuples to OpenFGA", "tuples", len(chunk))
+	}
+	return nil
+}
+
+// DeleteTuples deletes the tuples from the store in transactions of at most MaxTuplesPerWrite tuples. A
+// tuple which doesn't exist fails the transaction.
+func (s *OpenFgaStoreService) DeleteTuples(ctx context.Context, tuples []Tuple, log *logr.Logger) error {
+	for _, chunk := range chunkTuples(tuples) {
+		body := ofgaClient.ClientWriteRequest{Deletes: make([]ofgaClient.ClientTupleKeyWithoutCondition, 0, len(chunk))}
+		for _, tuple := range chunk {
+			body.Deletes = append(body.Deletes, ofgaClient.ClientTupleKeyWithoutCondition{User: tuple.User, Relation: tuple.Relation, Object: tuple.Object})
+		}
+		err := s.clients.retrier.call(ctx, false, func(ctx context.Context) error {
+			_, err := s.client.Write(ctx).Body(body).Execute()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		log.V(1).Info("Deleted tuples from OpenFGA", "tuples", len(chunk))
+	}
+	return nil
+}
+
+// Read returns the tuples of the store matching the filter, of which empty fields match anything. OpenFGA
+// requires at least the type of the object, e.g. "document:", when the user is set.
+func (s *OpenFgaStoreService) Read(ctx context.Context, filter Tuple) ([]Tuple, error) {
+	body := ofgaClient.ClientReadRequest{}
+	if filter.User != "" {
+		body.User = openfga.PtrString(filter.User)
+	}
+	if filter.Relation != "" {
+		body.Relation = openfga.PtrString(filter.Relation)
+	}
+	if filter.Object != "" {
+		body.Object = openfga.PtrString(filter.Object)
+	}
+	options := ofgaClient.ClientReadOptions{
+		PageSize: s.clients.config.Requests.pageSize(),
+	}
+	var tuples []Tuple
+	for {
+		var response *ofgaClient.ClientReadResponse
+		err := s.clients.retrier.call(ctx, true, func(ctx context.Context) (err error) {
+			response, err = s.client.Read(ctx).Body(body).Options(options).Execute()
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, tuple := range response.Tuples {
+			tuples = append(tuples, Tuple{User: tuple.Key.User, Relation: tuple.Key.Relation, Object: tuple.Key.Object})
+		}
+		if response.ContinuationToken == "" {
+			return tuples, nil
+		}
+		options = ofgaClient.ClientReadOptions{
+			PageSize:          s.clients.config.Requests.pageSize(),
+			ContinuationToken: openfga.PtrString(response.ContinuationToken),
+		}
+	}
+}
+
+func chunkTuples(tuples []Tuple) [][]Tuple {
+	var chunks [][]Tuple
+	for start := 0; start < len(tuples); start += MaxTuplesPerWrite {
+		chunks = append(chunks, tuples[start:min(start+MaxTuplesPerWrite, len(tuples))])
+	}
+	return chunks
+}
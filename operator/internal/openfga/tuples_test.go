@@ -0,0 +1,84 @@
+package openfga
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"testing"
+)
+
+func TestWriteTuplesInChunks(t *testing.T) {
+	// Arrange
+	service, calls, _ := newRetryTestService(t, RequestConfig{}, response{status: http.StatusOK, body: `{}`})
+	storeService, err := service.ForStore(storeIdA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tuples := make([]Tuple, 2*MaxTuplesPerWrite+1)
+	for i := range tuples {
+		tuples[i] = Tuple{User: fmt.Sprintf("user:%d", i), Relation: "viewer", Object: "document:roadmap"}
+	}
+
+	// Act
+	err = storeService.WriteTuples(context.Background(), tuples, &logr.Logger{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *calls != 3 {
+		t.Errorf("expected 3 writes, got %d", *calls)
+	}
+}
+
+func TestWriteTuplesPermanentFailure(t *testing.T) {
+	// Arrange
+	invalidTuple := response{status: http.StatusBadRequest, body: `{"code": "validation_error", "message": "type 'team' not found"}`}
+	service, calls, _ := newRetryTestService(t, testRequestSettings, invalidTuple)
+	storeService, err := service.ForStore(storeIdA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	err = storeService.WriteTuples(context.Background(), []Tuple{{User: "user:anne", Relation: "member", Object: "team:a"}}, &logr.Logger{})
+
+	// Assert
+	if !IsPermanent(err) {
+		t.Errorf("expected a permanent failure, got %v", err)
+	}
+	if *calls != 1 {
+		t.Errorf("expected no retries, got %d calls", *calls)
+	}
+}
+
+func TestReadTuples(t *testing.T) {
+	// Arrange
+	firstPage := response{status: http.StatusOK, body: `{"tuples": [{"key": {"user": "user:anne", "relation": "viewer", "object": "document:a"}}], "continuation_token": "next"}`}
+	lastPage := response{status: http.StatusOK, body: `{"tuples": [{"key": {"user": "user:bob", "relation": "viewer", "object": "document:b"}}], "continuation_token": ""}`}
+	service, calls, _ := newRetryTestService(t, RequestConfig{}, firstPage, lastPage)
+	storeService, err := service.ForStore(storeIdA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	tuples, err := storeService.Read(context.Background(), Tuple{Relation: "viewer", Object: "document:"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Tuple{
+		{User: "user:anne", Relation: "viewer", Object: "document:a"},
+		{User: "user:bob", Relation: "viewer", Object: "document:b"},
+	}
+	if diff := cmp.Diff(expected, tuples); diff != "" {
+		t.Errorf("unexpected tuples (-want +got):\n%s", diff)
+	}
+	if *calls != 2 {
+		t.Errorf("expected 2 pages, got %d", *calls)
+	}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authorizationmodelrequest
+
+import (
+	"context"
+	"fga-operator/internal/openfga"
+	"fmt"
+	"github.com/openfga/language/pkg/go/transformer"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	extensionsv1 "fga-operator/api/v1"
+)
+
+// Validator rejects authorization model requests which would otherwise only fail during
+// reconciliation, like requests with authorization models which don't compile.
+type Validator struct {
+	client.Client
+}
+
+//+kubebuilder:webhook:path=/validate-extensions-fga-operator-v1-authorizationmodelrequest,mutating=false,failurePolicy=fail,sideEffects=None,groups=extensions.fga-operator,resources=authorizationmodelrequests,verbs=create;update,versions=v1,name=vauthorizationmodelrequest.fga-operator,admissionReviewVersions=v1
+
+var _ admission.CustomValidator = &Validator{}
+
+func (v *Validator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&extensionsv1.AuthorizationModelRequest{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate implements admission.CustomValidator.
+func (v *Validator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, obj)
+}
+
+// ValidateUpdate implements admission.CustomValidator.
+func (v *Validator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return v.validate(ctx, newObj)
+}
+
+// ValidateDelete implements admission.CustomValidator.
+func (v *Validator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *Validator) validate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	request, ok := obj.(*extensionsv1.AuthorizationModelRequest)
+	if !ok {
+		return nil, fmt.Errorf("expected an AuthorizationModelRequest but got a %T", obj)
+	}
+
+	authorizationModel := &extensionsv1.AuthorizationModel{}
+	err := v.Get(ctx, types.NamespacedName{Name: request.Name, Namespace: request.Namespace}, authorizationModel)
+	switch {
+	case apierrors.IsNotFound(err):
+		authorizationModel = nil
+	case err != nil:
+		return nil, apierrors.NewInternalError(err)
+	}
+
+	if errs := ValidateAuthorizationModelRequest(request, authorizationModel); len(errs) > 0 {
+		return nil, apierrors.NewInvalid(
+			extensionsv1.GroupVersion.WithKind("AuthorizationModelRequest").GroupKind(),
+			request.Name,
+			errs)
+	}
+	return nil, nil
+}
+
+// ValidateAuthorizationModelRequest validates the instances of the request. The authorization model
+// is the one already created from the request, and is nil when the request is new.
+func ValidateAuthorizationModelRequest(
+	request *extensionsv1.AuthorizationModelRequest,
+	authorizationModel *extensionsv1.AuthorizationModel) field.ErrorList {
+	var errs field.ErrorList
+	instancesPath := field.NewPath("spec", "instances")
+
+	existingInstances := make(map[extensionsv1.ModelVersion]extensionsv1.AuthorizationModelInstance)
+	if authorizationModel != nil {
+		for _, instance := range authorizationModel.Spec.Instances {
+			existingInstances[instance.Version] = instance
+		}
+	}
+
+	seenVersions := make(map[extensionsv1.ModelVersion]struct{})
+	for i, instance := range request.Spec.Instances {
+		instancePath := instancesPath.Index(i)
+
+		if _, seen := seenVersions[instance.Version]; seen {
+			errs = append(errs, field.Duplicate(instancePath.Child("version"), instance.Version.String()))
+		}
+		seenVersions[instance.Version] = struct{}{}
+
+		if instance.Assertions != nil && instance.AssertionsFrom != nil {
+			errs = append(errs, field.Forbidden(instancePath.Child("assertionsFrom"), "may not be set together with assertions"))
+		}
+
+		if instance.ModularAuthorizationModel != nil {
+			errs = append(errs, validateModularAuthorizationModel(instance, existingInstances, instancePath.Child("modularAuthorizationModel"))...)
+			continue
+		}
+		if instance.AuthorizationModelFrom != nil {
+			errs = append(errs, validateAuthorizationModelFrom(instance, instancePath.Child("authorizationModelFrom"))...)
+			continue
+		}
+		if instance.ExistingAuthorizationModelId != "" && instance.AuthorizationModel != "" {
+			errs = append(errs, field.Forbidden(
+				instancePath.Child("existingAuthorizationModelId"),
+				"may not be set together with authorizationModel"))
+			continue
+		}
+		if instance.AuthorizationModel == "" {
+			continue
+		}
+
+		dsl := instance.AuthorizationModel
+		if instance.Format == extensionsv1.FormatJSON {
+			if err := openfga.ValidateJSONModel(instance.AuthorizationModel); err != nil {
+				errs = append(errs, field.Invalid(instancePath.Child("authorizationModel"), field.OmitValueType{}, err.Error()))
+				continue
+			}
+			// Versions are compared on the DSL stored in the authorization model, which is skipped for
+			// json models using features the DSL can't express.
+			rendered, err := openfga.RenderDSL(instance.AuthorizationModel)
+			if err != nil {
+				continue
+			}
+			dsl = rendered
+		}
+
+		if _, err := transformer.TransformDSLToJSON(dsl); err != nil {
+			errs = append(errs, field.Invalid(instancePath.Child("authorizationModel"), field.OmitValueType{}, err.Error()))
+			continue
+		}
+
+		existing, exists := existingInstances[instance.Version]
+		if !exists || existing.AuthorizationModel == "" || openfga.IsSameModel(existing.AuthorizationModel, dsl) {
+			continue
+		}
+		errs = append(errs, field.Forbidden(
+			instancePath.Child("authorizationModel"),
+			fmt.Sprintf("authorization model of version %s already exists with id %s and can't be changed, add a new version instead",
+				instance.Version.String(), existing.Id)))
+	}
+
+	if strategy := request.Spec.RolloutStrategy; strategy != nil && strategy.BatchSize > 0 && strategy.BatchPercentage > 0 {
+		errs = append(errs, field.Forbidden(field.NewPath("spec", "rolloutStrategy", "batchPercentage"), "may not be set together with batchSize"))
+	}
+
+	channelsPath := field.NewPath("spec", "channels")
+	for i, channel := range request.Spec.Channels {
+		if _, err := extensionsv1.ParseVersionConstraint(channel.Version); err != nil {
+			errs = append(errs, field.Invalid(channelsPath.Index(i).Child("version"), channel.Version, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+// validateAuthorizationModelFrom validates the source of the authorization model. The content of the
+// source is validated by the reconciler, since it may change after the request is admitted.
+func validateAuthorizationModelFrom(instance extensionsv1.AuthorizationModelRequestInstance, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	source := instance.AuthorizationModelFrom
+	if instance.AuthorizationModel != "" || instance.ExistingAuthorizationModelId != "" {
+		errs = append(errs, field.Forbidden(path, "may not be set together with authorizationModel or existingAuthorizationModelId"))
+	}
+	switch {
+	case source.ConfigMapKeyRef != nil && source.SecretKeyRef != nil:
+		errs = append(errs, field.Forbidden(path, "may only set one of configMapKeyRef and secretKeyRef"))
+	case source.ConfigMapKeyRef == nil && source.SecretKeyRef == nil:
+		errs = append(errs, field.Required(path, "one of configMapKeyRef and secretKeyRef must be set"))
+	}
+	return errs
+}
+
+// validateModularAuthorizationModel validates the modular authorization model. It is only compiled when
+// the manifest and all module files are inlined, otherwise the reconciler validates it.
+func validateModularAuthorizationModel(
+	instance extensionsv1.AuthorizationModelRequestInstance,
+	existingInstances map[extensionsv1.ModelVersion]extensionsv1.AuthorizationModelInstance,
+	path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	modular := instance.ModularAuthorizationModel
+	if instance.AuthorizationModel != "" || instance.AuthorizationModelFrom != nil || instance.ExistingAuthorizationModelId != "" {
+		errs = append(errs, field.Forbidden(path,
+			"may not be set together with authorizationModel, authorizationModelFrom or existingAuthorizationModelId"))
+	}
+	if (modular.Manifest == "") == (modular.ManifestFrom == nil) {
+		errs = append(errs, field.Invalid(path.Child("manifest"), field.OmitValueType{}, "exactly one of manifest and manifestFrom must be set"))
+	}
+	if len(modular.Modules) == 0 {
+		errs = append(errs, field.Required(path.Child("modules"), "at least one module file must be set"))
+	}
+	inlined := modular.ManifestFrom == nil
+	files := make([]transformer.ModuleFile, 0, len(modular.Modules))
+	for i, module := range modular.Modules {
+		modulePath := path.Child("modules").Index(i)
+		if module.Name == "" {
+			errs = append(errs, field.Required(modulePath.Child("name"), ""))
+		}
+		if (module.Contents == "") == (module.ContentsFrom == nil) {
+			errs = append(errs, field.Invalid(modulePath.Child("contents"), field.OmitValueType{}, "exactly one of contents and contentsFrom must be set"))
+		}
+		inlined = inlined && module.ContentsFrom == nil
+		files = append(files, transformer.ModuleFile{Name: module.Name, Contents: module.Contents})
+	}
+	if len(errs) > 0 || !inlined {
+		return errs
+	}
+
+	if _, err := openfga.CompileModularModel(modular.Manifest, files); err != nil {
+		return append(errs, field.Invalid(path, field.OmitValueType{}, err.Error()))
+	}
+
+	existing, exists := existingInstances[instance.Version]
+	if !exists || existing.ModularAuthorizationModel == nil {
+		return errs
+	}
+	existingFiles := make([]transformer.ModuleFile, len(existing.ModularAuthorizationModel.Modules))
+	for i, module := range existing.ModularAuthorizationModel.Modules {
+		existingFiles[i] = transformer.ModuleFile{Name: module.Name, Contents: module.Contents}
+	}
+	if openfga.IsSameModularModel(existing.ModularAuthorizationModel.Manifest, existingFiles, modular.Manifest, files) {
+		return errs
+	}
+	return append(errs, field.Forbidden(path,
+		fmt.Sprintf("authorization model of version %s already exists with id %s and can't be changed, add a new version instead",
+			instance.Version.String(), existing.Id)))
+}
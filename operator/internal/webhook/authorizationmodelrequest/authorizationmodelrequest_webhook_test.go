@@ -0,0 +1,336 @@
+package authorizationmodelrequest
+
+import (
+	extensionsv1 "fga-operator/api/v1"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"testing"
+	"time"
+)
+
+const model = `
+model
+  schema 1.1
+
+type user
+
+type document
+  relations
+    define reader: [user]
+`
+
+const reformattedModel = `
+model
+  schema 1.1
+
+# comments and formatting don't change the model
+type user
+type document
+  relations
+    define reader: [user]
+`
+
+const changedModel = `
+model
+  schema 1.1
+
+type user
+
+type document
+  relations
+    define reader: [user]
+    define writer: [user]
+`
+
+const invalidModel = `
+model
+  schema 1.1
+
+type user
+
+type document
+  relations
+    define reader [user
+`
+
+// jsonModel is model in the json syntax of the OpenFGA API.
+const jsonModel = `{
+  "schema_version": "1.1",
+  "type_definitions": [
+    {"type": "user"},
+    {
+      "type": "document",
+      "relations": {"reader": {"this": {}}},
+      "metadata": {"relations": {"reader": {"directly_related_user_types": [{"type": "user"}]}}}
+    }
+  ]
+}`
+
+type fieldError struct {
+	Type  field.ErrorType
+	Field string
+}
+
+func newRequest(instances ...extensionsv1.AuthorizationModelRequestInstance) *extensionsv1.AuthorizationModelRequest {
+	return &extensionsv1.AuthorizationModelRequest{
+		Spec: extensionsv1.AuthorizationModelRequestSpec{Instances: instances},
+	}
+}
+
+func withChannels(request *extensionsv1.AuthorizationModelRequest, channels ...extensionsv1.ModelChannel) *extensionsv1.AuthorizationModelRequest {
+	request.Spec.Channels = channels
+	return request
+}
+
+func withRolloutStrategy(request *extensionsv1.AuthorizationModelRequest, strategy *extensionsv1.RolloutStrategy) *extensionsv1.AuthorizationModelRequest {
+	request.Spec.RolloutStrategy = strategy
+	return request
+}
+
+const manifest = `schema: '1.2'
+contents:
+  - core.fga
+`
+
+const coreModule = `module core
+
+type user
+
+type document
+  relations
+    define reader: [user]
+`
+
+func modularModel(contents string) *extensionsv1.ModularAuthorizationModel {
+	return &extensionsv1.ModularAuthorizationModel{
+		Manifest: manifest,
+		Modules:  []extensionsv1.ModuleFile{{Name: "core.fga", Contents: contents}},
+	}
+}
+
+func configMapSource() *extensionsv1.AuthorizationModelSource {
+	return &extensionsv1.AuthorizationModelSource{
+		ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "models"},
+			Key:                  "model.fga",
+		},
+	}
+}
+
+func TestValidateAuthorizationModelRequest(t *testing.T) {
+	existing := extensionsv1.NewAuthorizationModel("store", "default", []extensionsv1.AuthorizationModelDefinition{
+		extensionsv1.NewAuthorizationModelDefinition("id", model, extensionsv1.ModelVersion{Major: 1}),
+	}, time.Now())
+
+	testCases := []struct {
+		description        string
+		request            *extensionsv1.AuthorizationModelRequest
+		authorizationModel *extensionsv1.AuthorizationModel
+		expected           []fieldError
+	}{
+		{
+			description: "valid request",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{AuthorizationModel: model, Version: extensionsv1.ModelVersion{Major: 1}},
+				extensionsv1.AuthorizationModelRequestInstance{ExistingAuthorizationModelId: "id", Version: extensionsv1.ModelVersion{Major: 2}},
+			),
+		},
+		{
+			description: "invalid model",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{AuthorizationModel: model, Version: extensionsv1.ModelVersion{Major: 1}},
+				extensionsv1.AuthorizationModelRequestInstance{AuthorizationModel: invalidModel, Version: extensionsv1.ModelVersion{Major: 2}},
+			),
+			expected: []fieldError{{field.ErrorTypeInvalid, "spec.instances[1].authorizationModel"}},
+		},
+		{
+			description: "duplicate version",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{AuthorizationModel: model, Version: extensionsv1.ModelVersion{Major: 1}},
+				extensionsv1.AuthorizationModelRequestInstance{AuthorizationModel: changedModel, Version: extensionsv1.ModelVersion{Major: 1}},
+			),
+			expected: []fieldError{{field.ErrorTypeDuplicate, "spec.instances[1].version"}},
+		},
+		{
+			description: "existing id and model both set",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{ExistingAuthorizationModelId: "id", AuthorizationModel: model, Version: extensionsv1.ModelVersion{Major: 1}},
+			),
+			expected: []fieldError{{field.ErrorTypeForbidden, "spec.instances[0].existingAuthorizationModelId"}},
+		},
+		{
+			description: "model from config map",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{AuthorizationModelFrom: configMapSource(), Version: extensionsv1.ModelVersion{Major: 1}},
+			),
+			authorizationModel: &existing,
+		},
+		{
+			description: "model from config map and inline model both set",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{AuthorizationModelFrom: configMapSource(), AuthorizationModel: model, Version: extensionsv1.ModelVersion{Major: 1}},
+			),
+			expected: []fieldError{{field.ErrorTypeForbidden, "spec.instances[0].authorizationModelFrom"}},
+		},
+		{
+			description: "model from config map and secret both set",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{
+					AuthorizationModelFrom: &extensionsv1.AuthorizationModelSource{
+						ConfigMapKeyRef: configMapSource().ConfigMapKeyRef,
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "models"},
+							Key:                  "model.fga",
+						},
+					},
+					Version: extensionsv1.ModelVersion{Major: 1},
+				},
+			),
+			expected: []fieldError{{field.ErrorTypeForbidden, "spec.instances[0].authorizationModelFrom"}},
+		},
+		{
+			description: "model from without reference",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{AuthorizationModelFrom: &extensionsv1.AuthorizationModelSource{}, Version: extensionsv1.ModelVersion{Major: 1}},
+			),
+			expected: []fieldError{{field.ErrorTypeRequired, "spec.instances[0].authorizationModelFrom"}},
+		},
+		{
+			description: "assertions and assertions from config map both set",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{
+					AuthorizationModel: model,
+					Assertions:         &extensionsv1.AuthorizationModelAssertions{},
+					AssertionsFrom:     configMapSource().ConfigMapKeyRef,
+					Version:            extensionsv1.ModelVersion{Major: 1},
+				},
+			),
+			expected: []fieldError{{field.ErrorTypeForbidden, "spec.instances[0].assertionsFrom"}},
+		},
+		{
+			description: "invalid channel version",
+			request: withChannels(
+				newRequest(extensionsv1.AuthorizationModelRequestInstance{AuthorizationModel: model, Version: extensionsv1.ModelVersion{Major: 1}}),
+				extensionsv1.ModelChannel{Name: "stable", Version: "1.x"},
+				extensionsv1.ModelChannel{Name: "canary", Version: "~1.a"},
+			),
+			expected: []fieldError{{field.ErrorTypeInvalid, "spec.channels[1].version"}},
+		},
+		{
+			description: "rollout batch size and batch percentage both set",
+			request: withRolloutStrategy(
+				newRequest(extensionsv1.AuthorizationModelRequestInstance{AuthorizationModel: model, Version: extensionsv1.ModelVersion{Major: 1}}),
+				&extensionsv1.RolloutStrategy{BatchSize: 2, BatchPercentage: 25},
+			),
+			expected: []fieldError{{field.ErrorTypeForbidden, "spec.rolloutStrategy.batchPercentage"}},
+		},
+		{
+			description: "modular model",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{ModularAuthorizationModel: modularModel(coreModule), Version: extensionsv1.ModelVersion{Major: 1}},
+			),
+		},
+		{
+			description: "modular model with error in module file",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{ModularAuthorizationModel: modularModel("module core\n\ntype user\n  relations\n    define reader [user"), Version: extensionsv1.ModelVersion{Major: 1}},
+			),
+			expected: []fieldError{{field.ErrorTypeInvalid, "spec.instances[0].modularAuthorizationModel"}},
+		},
+		{
+			description: "modular model with contents and contents from",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{
+					ModularAuthorizationModel: &extensionsv1.ModularAuthorizationModel{
+						Manifest: manifest,
+						Modules: []extensionsv1.ModuleFile{{
+							Name:         "core.fga",
+							Contents:     coreModule,
+							ContentsFrom: configMapSource().ConfigMapKeyRef,
+						}},
+					},
+					Version: extensionsv1.ModelVersion{Major: 1},
+				},
+			),
+			expected: []fieldError{{field.ErrorTypeInvalid, "spec.instances[0].modularAuthorizationModel.modules[0].contents"}},
+		},
+		{
+			description: "modular model and inline model both set",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{ModularAuthorizationModel: modularModel(coreModule), AuthorizationModel: model, Version: extensionsv1.ModelVersion{Major: 1}},
+			),
+			expected: []fieldError{{field.ErrorTypeForbidden, "spec.instances[0].modularAuthorizationModel"}},
+		},
+		{
+			description: "changed model of existing version",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{AuthorizationModel: changedModel, Version: extensionsv1.ModelVersion{Major: 1}},
+			),
+			authorizationModel: &existing,
+			expected:           []fieldError{{field.ErrorTypeForbidden, "spec.instances[0].authorizationModel"}},
+		},
+		{
+			description: "reformatted model of existing version",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{AuthorizationModel: reformattedModel, Version: extensionsv1.ModelVersion{Major: 1}},
+			),
+			authorizationModel: &existing,
+		},
+		{
+			description: "json model",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{AuthorizationModel: jsonModel, Format: extensionsv1.FormatJSON, Version: extensionsv1.ModelVersion{Major: 2}},
+			),
+		},
+		{
+			description: "json model with unknown field",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{
+					AuthorizationModel: `{"schema_version": "1.1", "type_definitions": [{"type": "user"}], "types": []}`,
+					Format:             extensionsv1.FormatJSON,
+					Version:            extensionsv1.ModelVersion{Major: 1},
+				},
+			),
+			expected: []fieldError{{field.ErrorTypeInvalid, "spec.instances[0].authorizationModel"}},
+		},
+		{
+			description: "dsl model in json format",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{AuthorizationModel: model, Format: extensionsv1.FormatJSON, Version: extensionsv1.ModelVersion{Major: 1}},
+			),
+			expected: []fieldError{{field.ErrorTypeInvalid, "spec.instances[0].authorizationModel"}},
+		},
+		{
+			description: "json model of existing version with same model in dsl",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{AuthorizationModel: jsonModel, Format: extensionsv1.FormatJSON, Version: extensionsv1.ModelVersion{Major: 1}},
+			),
+			authorizationModel: &existing,
+		},
+		{
+			description: "changed model with new version",
+			request: newRequest(
+				extensionsv1.AuthorizationModelRequestInstance{AuthorizationModel: model, Version: extensionsv1.ModelVersion{Major: 1}},
+				extensionsv1.AuthorizationModelRequestInstance{AuthorizationModel: changedModel, Version: extensionsv1.ModelVersion{Major: 1, Minor: 1}},
+			),
+			authorizationModel: &existing,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Act
+			errs := ValidateAuthorizationModelRequest(testCase.request, testCase.authorizationModel)
+
+			// Assert
+			var actual []fieldError
+			for _, err := range errs {
+				actual = append(actual, fieldError{Type: err.Type, Field: err.Field})
+			}
+			if diff := cmp.Diff(testCase.expected, actual); diff != "" {
+				t.Errorf("errors mismatch (-expected +actual):\n%s\n%v", diff, errs)
+			}
+		})
+	}
+}
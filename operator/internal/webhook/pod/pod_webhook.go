@@ -0,0 +1,154 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"fga-operator/internal/injection"
+	"fga-operator/internal/observability"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	extensionsv1 "fga-operator/api/v1"
+)
+
+const (
+	injectionResultInjected = "injected"
+	injectionResultSkipped  = "skipped"
+)
+
+// EnvInjector injects the store ID and authorization model ID into pods which carry the
+// `openfga-store` label, at the time the pod is created. Pods with the ConfigMap delivery get
+// the IDs from their ConfigMap instead. Pods which already have the authorization model ID or
+// version from the pod template of their workload are left untouched, such that the operator
+// rolls out new authorization models to them.
+//
+// The injector never rejects a pod. If the store or authorization model can't be resolved
+// the pod is admitted unchanged and the failure is logged.
+type EnvInjector struct {
+	client.Client
+}
+
+// The webhook only receives pods with the `openfga-store` label, using the object selector of
+// config/webhook/pod_webhook_selector_patch.yaml and of the Helm chart.
+//+kubebuilder:webhook:path=/mutate--v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod.fga-operator,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups=extensions.fga-operator,resources=stores,verbs=get;list;watch
+
+var _ admission.CustomDefaulter = &EnvInjector{}
+
+func (i *EnvInjector) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&corev1.Pod{}).
+		WithDefaulter(i).
+		Complete()
+}
+
+// Default implements admission.CustomDefaulter.
+func (i *EnvInjector) Default(ctx context.Context, obj runtime.Object) error {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("expected a Pod but got a %T", obj)
+	}
+	storeName, ok := pod.GetLabels()[extensionsv1.OpenFgaStoreLabel]
+	if !ok {
+		return nil
+	}
+	switch extensionsv1.DeliveryMode(pod.GetAnnotations()[extensionsv1.OpenFgaDeliveryAnnotation]) {
+	case extensionsv1.DeliveryModeConfigMap, extensionsv1.DeliveryModeConfigMapKeyRef:
+		return nil
+	}
+	if hasInjectedModel(pod) {
+		return nil
+	}
+
+	namespace := getNamespace(ctx, pod)
+	logger := log.FromContext(ctx).WithValues("store", storeName, "namespace", namespace)
+
+	store := &extensionsv1.Store{}
+	if err := i.Get(ctx, types.NamespacedName{Name: storeName, Namespace: namespace}, store); err != nil {
+		logger.Error(err, "unable to fetch store, admitting pod without injection")
+		observability.RecordPodInjection(storeName, injectionResultSkipped)
+		return nil
+	}
+
+	model := &extensionsv1.AuthorizationModel{}
+	if err := i.Get(ctx, types.NamespacedName{Name: storeName, Namespace: namespace}, model); err != nil {
+		logger.Error(err, "unable to fetch authorization model, admitting pod without injection")
+		observability.RecordPodInjection(storeName, injectionResultSkipped)
+		return nil
+	}
+
+	instance, err := model.WithoutFailedVersions().GetVersionFromObject(pod)
+	if err != nil {
+		logger.Error(err, "unable to get auth instance from pod, admitting pod without injection")
+		observability.RecordPodInjection(storeName, injectionResultSkipped)
+		return nil
+	}
+
+	injectIntoPod(pod, store, instance)
+	observability.RecordPodInjection(storeName, injectionResultInjected)
+	logger.V(1).Info("injected OpenFGA environment variables into pod", "authInstance", instance.Id)
+
+	return nil
+}
+
+// hasInjectedModel returns true when the pod got the authorization model from the pod template of its workload, which
+// the operator keeps up to date following the rollout strategy of the authorization model.
+func hasInjectedModel(pod *corev1.Pod) bool {
+	if _, ok := pod.GetAnnotations()[extensionsv1.OpenFgaAuthModelVersionLabel]; ok {
+		return true
+	}
+	return injection.FromObject(pod).HasEnvVar(&pod.Spec, extensionsv1.OpenFgaAuthModelIdEnv)
+}
+
+// injectIntoPod sets the environment variables as configured by the annotations of the pod, which it gets from the
+// pod template of its workload.
+func injectIntoPod(pod *corev1.Pod, store *extensionsv1.Store, instance extensionsv1.AuthorizationModelInstance) {
+	podInjection := injection.FromObject(pod)
+	podInjection.SetEnvVar(&pod.Spec, extensionsv1.OpenFgaStoreIdEnv, store.Spec.Id)
+	podInjection.SetEnvVar(&pod.Spec, extensionsv1.OpenFgaAuthModelIdEnv, instance.Id)
+	podInjection.SetEnvVar(&pod.Spec, extensionsv1.OpenFgaAuthModelVersionEnv, instance.Version.String())
+	if store.Status.ApiUrl != "" {
+		podInjection.SetEnvVar(&pod.Spec, extensionsv1.OpenFgaApiUrlEnv, store.Status.ApiUrl)
+	}
+
+	annotations := pod.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[extensionsv1.OpenFgaAuthModelVersionLabel] = instance.Version.String()
+	pod.SetAnnotations(annotations)
+}
+
+// getNamespace returns the namespace of the pod. Pods created by controllers, like ReplicaSets,
+// don't have the namespace set on the object, hence it is taken from the admission request.
+func getNamespace(ctx context.Context, pod *corev1.Pod) string {
+	if pod.Namespace != "" {
+		return pod.Namespace
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return ""
+	}
+	return req.Namespace
+}
@@ -0,0 +1,184 @@
+package pod
+
+import (
+	"context"
+	extensionsv1 "fga-operator/api/v1"
+	"github.com/google/go-cmp/cmp"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"testing"
+	"time"
+)
+
+const (
+	storeName = "store"
+	namespace = "default"
+	storeId   = "store-id"
+	apiUrl    = "http://openfga:8080"
+)
+
+func newInjector(t *testing.T) *EnvInjector {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := extensionsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	store := extensionsv1.NewStore(storeName, namespace, storeId, now)
+	store.Status.ApiUrl = apiUrl
+	model := extensionsv1.NewAuthorizationModel(storeName, namespace, []extensionsv1.AuthorizationModelDefinition{
+		extensionsv1.NewAuthorizationModelDefinition("model-1", "", extensionsv1.ModelVersion{Major: 1}),
+		extensionsv1.NewAuthorizationModelDefinition("model-2", "", extensionsv1.ModelVersion{Major: 2}),
+	}, now)
+
+	return &EnvInjector{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(store, &model).Build(),
+	}
+}
+
+func newPod(labels map[string]string, env ...corev1.EnvVar) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "pod",
+			Labels: labels,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Env: env}},
+		},
+	}
+}
+
+func TestDefault(t *testing.T) {
+	testCases := []struct {
+		description         string
+		labels              map[string]string
+		annotations         map[string]string
+		env                 []corev1.EnvVar
+		expectedEnv         []corev1.EnvVar
+		expectedAnnotations map[string]string
+	}{
+		{
+			description: "pod without store label is left untouched",
+			labels:      map[string]string{"app": "foo"},
+		},
+		{
+			description: "pod with unknown store is left untouched",
+			labels:      map[string]string{extensionsv1.OpenFgaStoreLabel: "unknown"},
+		},
+		{
+			description: "pod with unknown version is left untouched",
+			labels: map[string]string{
+				extensionsv1.OpenFgaStoreLabel:            storeName,
+				extensionsv1.OpenFgaAuthModelVersionLabel: "3.0.0",
+			},
+		},
+		{
+			description: "pod without version gets latest model",
+			labels:      map[string]string{extensionsv1.OpenFgaStoreLabel: storeName},
+			expectedEnv: []corev1.EnvVar{
+				{Name: extensionsv1.OpenFgaStoreIdEnv, Value: storeId},
+				{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "model-2"},
+			},
+			expectedAnnotations: map[string]string{extensionsv1.OpenFgaAuthModelVersionLabel: "2.0.0"},
+		},
+		{
+			description: "pod with version gets matching model",
+			labels: map[string]string{
+				extensionsv1.OpenFgaStoreLabel:            storeName,
+				extensionsv1.OpenFgaAuthModelVersionLabel: "1.0.0",
+			},
+			env: []corev1.EnvVar{{Name: "FOO", Value: "bar"}},
+			expectedEnv: []corev1.EnvVar{
+				{Name: "FOO", Value: "bar"},
+				{Name: extensionsv1.OpenFgaStoreIdEnv, Value: storeId},
+				{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "model-1"},
+			},
+			expectedAnnotations: map[string]string{extensionsv1.OpenFgaAuthModelVersionLabel: "1.0.0"},
+		},
+		{
+			description: "pod with model id of its pod template is left untouched",
+			labels:      map[string]string{extensionsv1.OpenFgaStoreLabel: storeName},
+			env: []corev1.EnvVar{
+				{Name: extensionsv1.OpenFgaStoreIdEnv, Value: storeId},
+				{Name: extensionsv1.OpenFgaAuthModelIdEnv, Value: "model-1"},
+			},
+		},
+		{
+			description:         "pod with version annotation of its pod template is left untouched",
+			labels:              map[string]string{extensionsv1.OpenFgaStoreLabel: storeName},
+			annotations:         map[string]string{extensionsv1.OpenFgaAuthModelVersionLabel: "1.0.0"},
+			expectedAnnotations: map[string]string{extensionsv1.OpenFgaAuthModelVersionLabel: "1.0.0"},
+		},
+		{
+			description: "pod gets the environment variables named by its annotations",
+			labels:      map[string]string{extensionsv1.OpenFgaStoreLabel: storeName},
+			annotations: map[string]string{
+				extensionsv1.OpenFgaAuthModelIdEnvAnnotation: "FGA_MODEL_ID",
+				extensionsv1.OpenFgaApiUrlEnvAnnotation:      "FGA_API_URL",
+			},
+			expectedEnv: []corev1.EnvVar{
+				{Name: extensionsv1.OpenFgaStoreIdEnv, Value: storeId},
+				{Name: "FGA_MODEL_ID", Value: "model-2"},
+				{Name: "FGA_API_URL", Value: apiUrl},
+			},
+			expectedAnnotations: map[string]string{
+				extensionsv1.OpenFgaAuthModelIdEnvAnnotation: "FGA_MODEL_ID",
+				extensionsv1.OpenFgaApiUrlEnvAnnotation:      "FGA_API_URL",
+				extensionsv1.OpenFgaAuthModelVersionLabel:    "2.0.0",
+			},
+		},
+		{
+			description: "pod without the container of its annotation gets no environment variables",
+			labels:      map[string]string{extensionsv1.OpenFgaStoreLabel: storeName},
+			annotations: map[string]string{extensionsv1.OpenFgaContainersAnnotation: "other"},
+			expectedAnnotations: map[string]string{
+				extensionsv1.OpenFgaContainersAnnotation:  "other",
+				extensionsv1.OpenFgaAuthModelVersionLabel: "2.0.0",
+			},
+		},
+		{
+			description:         "pod with config map delivery is left untouched",
+			labels:              map[string]string{extensionsv1.OpenFgaStoreLabel: storeName},
+			annotations:         map[string]string{extensionsv1.OpenFgaDeliveryAnnotation: string(extensionsv1.DeliveryModeConfigMap)},
+			expectedAnnotations: map[string]string{extensionsv1.OpenFgaDeliveryAnnotation: string(extensionsv1.DeliveryModeConfigMap)},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			// Arrange
+			injector := newInjector(t)
+			pod := newPod(testCase.labels, testCase.env...)
+			pod.SetAnnotations(testCase.annotations)
+			ctx := admission.NewContextWithRequest(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{Namespace: namespace},
+			})
+			expectedEnv := testCase.expectedEnv
+			if expectedEnv == nil {
+				expectedEnv = testCase.env
+			}
+
+			// Act
+			err := injector.Default(ctx, pod)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if diff := cmp.Diff(expectedEnv, pod.Spec.Containers[0].Env); diff != "" {
+				t.Errorf("env mismatch (-expected +actual):\n%s", diff)
+			}
+			if diff := cmp.Diff(testCase.expectedAnnotations, pod.GetAnnotations()); diff != "" {
+				t.Errorf("annotations mismatch (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}